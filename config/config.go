@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strings" // Package untuk manipulasi string
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,6 +13,40 @@ import (
 type Config struct {
 	DBConn string `mapstructure:"DB_CONN"`
 	Port   string `mapstructure:"PORT"`
+
+	// Held cart: lama reservasi stok (menit) untuk keranjang yang di-park
+	HeldCartReservationMinutes int `mapstructure:"HELD_CART_RESERVATION_MINUTES"`
+
+	// Override harga / diskon: masa berlaku token persetujuan supervisor (menit)
+	OverrideTokenMinutes int `mapstructure:"OVERRIDE_TOKEN_MINUTES"`
+
+	// Penyesuaian stok: write-off dari non-admin di atas nilai ini (harga beli) menunggu persetujuan admin (0 = tanpa persetujuan)
+	StockAdjustmentApprovalLimit float64 `mapstructure:"STOCK_ADJUSTMENT_APPROVAL_LIMIT"`
+
+	// Harga pokok persediaan: average (rata-rata tertimbang), fifo (lapisan biaya terlama) atau last (harga beli terakhir)
+	CostingMethod string `mapstructure:"COSTING_METHOD"`
+
+	// Struk: identitas toko & lebar kertas printer thermal (58 atau 80 mm)
+	StoreName         string `mapstructure:"STORE_NAME"`
+	StoreAddress      string `mapstructure:"STORE_ADDRESS"`
+	StoreFooter       string `mapstructure:"STORE_FOOTER"`
+	ReceiptPaperWidth int    `mapstructure:"RECEIPT_PAPER_WIDTH"`
+
+	// Service charge (persen dari nilai jual bersih, 0 = nonaktif)
+	ServiceChargePercent float64 `mapstructure:"SERVICE_CHARGE_PERCENT"`
+	ServiceChargeTaxable bool    `mapstructure:"SERVICE_CHARGE_TAXABLE"`
+
+	// Pembulatan porsi tunai (kelipatan rupiah, 0 = nonaktif) dan mode: half, up, down
+	CashRoundingUnit float64 `mapstructure:"CASH_ROUNDING_UNIT"`
+	CashRoundingMode string  `mapstructure:"CASH_ROUNDING_MODE"`
+
+	// Poin loyalitas: belanja per 1 poin (0 = nonaktif), nilai 1 poin saat dipakai, masa berlaku (hari, 0 = selamanya)
+	LoyaltyEarnAmount      float64 `mapstructure:"LOYALTY_EARN_AMOUNT"`
+	LoyaltyPointValue      float64 `mapstructure:"LOYALTY_POINT_VALUE"`
+	LoyaltyPointExpiryDays int     `mapstructure:"LOYALTY_POINT_EXPIRY_DAYS"`
+
+	// Timezone toko untuk jadwal diskon berulang (hari & jam, misal happy hour)
+	StoreTimezone string `mapstructure:"STORE_TIMEZONE"`
 }
 
 // LoadConfig loads configuration from .env file and environment variables
@@ -29,6 +64,22 @@ func LoadConfig() (*Config, error) {
 	// Set default values
 	viper.SetDefault("PORT", "8080")
 	viper.SetDefault("DB_CONN", "")
+	viper.SetDefault("HELD_CART_RESERVATION_MINUTES", 30)
+	viper.SetDefault("OVERRIDE_TOKEN_MINUTES", 5)
+	viper.SetDefault("STOCK_ADJUSTMENT_APPROVAL_LIMIT", 0)
+	viper.SetDefault("COSTING_METHOD", "average")
+	viper.SetDefault("STORE_NAME", "Kasir API")
+	viper.SetDefault("STORE_ADDRESS", "")
+	viper.SetDefault("STORE_FOOTER", "Terima kasih atas kunjungan Anda")
+	viper.SetDefault("RECEIPT_PAPER_WIDTH", 58)
+	viper.SetDefault("SERVICE_CHARGE_PERCENT", 0)
+	viper.SetDefault("SERVICE_CHARGE_TAXABLE", true)
+	viper.SetDefault("CASH_ROUNDING_UNIT", 0)
+	viper.SetDefault("CASH_ROUNDING_MODE", "half")
+	viper.SetDefault("LOYALTY_EARN_AMOUNT", 0)
+	viper.SetDefault("LOYALTY_POINT_VALUE", 1)
+	viper.SetDefault("LOYALTY_POINT_EXPIRY_DAYS", 365)
+	viper.SetDefault("STORE_TIMEZONE", "Asia/Jakarta")
 
 	// Read config file (if exists)
 	if err := viper.ReadInConfig(); err != nil {
@@ -47,6 +98,88 @@ func LoadConfig() (*Config, error) {
 	config := &Config{
 		DBConn: viper.GetString("DB_CONN"),
 		Port:   viper.GetString("PORT"),
+
+		HeldCartReservationMinutes: viper.GetInt("HELD_CART_RESERVATION_MINUTES"),
+
+		OverrideTokenMinutes: viper.GetInt("OVERRIDE_TOKEN_MINUTES"),
+
+		StockAdjustmentApprovalLimit: viper.GetFloat64("STOCK_ADJUSTMENT_APPROVAL_LIMIT"),
+
+		CostingMethod: strings.ToLower(viper.GetString("COSTING_METHOD")),
+
+		StoreName:         viper.GetString("STORE_NAME"),
+		StoreAddress:      viper.GetString("STORE_ADDRESS"),
+		StoreFooter:       viper.GetString("STORE_FOOTER"),
+		ReceiptPaperWidth: viper.GetInt("RECEIPT_PAPER_WIDTH"),
+
+		ServiceChargePercent: viper.GetFloat64("SERVICE_CHARGE_PERCENT"),
+		ServiceChargeTaxable: viper.GetBool("SERVICE_CHARGE_TAXABLE"),
+
+		CashRoundingUnit: viper.GetFloat64("CASH_ROUNDING_UNIT"),
+		CashRoundingMode: strings.ToLower(viper.GetString("CASH_ROUNDING_MODE")),
+
+		LoyaltyEarnAmount:      viper.GetFloat64("LOYALTY_EARN_AMOUNT"),
+		LoyaltyPointValue:      viper.GetFloat64("LOYALTY_POINT_VALUE"),
+		LoyaltyPointExpiryDays: viper.GetInt("LOYALTY_POINT_EXPIRY_DAYS"),
+
+		StoreTimezone: viper.GetString("STORE_TIMEZONE"),
+	}
+
+	if config.OverrideTokenMinutes <= 0 {
+		log.Printf("⚠️  OVERRIDE_TOKEN_MINUTES=%d tidak valid, menggunakan 5\n", config.OverrideTokenMinutes)
+		config.OverrideTokenMinutes = 5
+	}
+
+	if config.StockAdjustmentApprovalLimit < 0 {
+		log.Printf("⚠️  STOCK_ADJUSTMENT_APPROVAL_LIMIT=%.0f tidak valid, persetujuan penyesuaian stok dinonaktifkan\n", config.StockAdjustmentApprovalLimit)
+		config.StockAdjustmentApprovalLimit = 0
+	}
+
+	switch config.CostingMethod {
+	case "average", "fifo", "last":
+	default:
+		log.Printf("⚠️  COSTING_METHOD=%s tidak didukung, menggunakan average\n", config.CostingMethod)
+		config.CostingMethod = "average"
+	}
+
+	if config.ServiceChargePercent < 0 || config.ServiceChargePercent > 100 {
+		log.Printf("⚠️  SERVICE_CHARGE_PERCENT=%.2f tidak valid, service charge dinonaktifkan\n", config.ServiceChargePercent)
+		config.ServiceChargePercent = 0
+	}
+
+	if config.CashRoundingUnit < 0 {
+		log.Printf("⚠️  CASH_ROUNDING_UNIT=%.0f tidak valid, pembulatan tunai dinonaktifkan\n", config.CashRoundingUnit)
+		config.CashRoundingUnit = 0
+	}
+	switch config.CashRoundingMode {
+	case "half", "up", "down":
+	default:
+		log.Printf("⚠️  CASH_ROUNDING_MODE=%s tidak didukung, menggunakan half\n", config.CashRoundingMode)
+		config.CashRoundingMode = "half"
+	}
+
+	if config.LoyaltyEarnAmount < 0 {
+		log.Printf("⚠️  LOYALTY_EARN_AMOUNT=%.0f tidak valid, poin loyalitas dinonaktifkan\n", config.LoyaltyEarnAmount)
+		config.LoyaltyEarnAmount = 0
+	}
+	if config.LoyaltyPointValue <= 0 {
+		log.Printf("⚠️  LOYALTY_POINT_VALUE=%.2f tidak valid, menggunakan 1\n", config.LoyaltyPointValue)
+		config.LoyaltyPointValue = 1
+	}
+	if config.LoyaltyPointExpiryDays < 0 {
+		log.Printf("⚠️  LOYALTY_POINT_EXPIRY_DAYS=%d tidak valid, menggunakan 365\n", config.LoyaltyPointExpiryDays)
+		config.LoyaltyPointExpiryDays = 365
+	}
+
+	if _, err := time.LoadLocation(config.StoreTimezone); err != nil || config.StoreTimezone == "" {
+		log.Printf("⚠️  STORE_TIMEZONE=%s tidak valid, menggunakan Asia/Jakarta\n", config.StoreTimezone)
+		config.StoreTimezone = "Asia/Jakarta"
+	}
+
+	// Lebar kertas struk hanya 58 atau 80 mm
+	if config.ReceiptPaperWidth != 58 && config.ReceiptPaperWidth != 80 {
+		log.Printf("⚠️  RECEIPT_PAPER_WIDTH=%d tidak didukung, menggunakan 58\n", config.ReceiptPaperWidth)
+		config.ReceiptPaperWidth = 58
 	}
 
 	// Validate required fields
@@ -57,6 +190,15 @@ func LoadConfig() (*Config, error) {
 	return config, nil
 }
 
+// StoreLocation returns the store timezone (fallback WIB jika tzdata tidak tersedia)
+func (c *Config) StoreLocation() *time.Location {
+	loc, err := time.LoadLocation(c.StoreTimezone)
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}
+
 // GetDatabaseURL returns the database URL with fallback to default
 func (c *Config) GetDatabaseURL() string {
 	if c.DBConn != "" {
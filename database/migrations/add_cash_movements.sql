@@ -0,0 +1,24 @@
+-- ==========================================
+-- MIGRATION: Tambah Tabel cash_movements (Kas Kecil Laci)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Mencatat uang masuk/keluar laci di luar penjualan, terhubung ke shift kasir:
+--            pay_in  = tambah uang ke laci (misal tambahan uang kembalian)
+--            pay_out = ambil uang dari laci (parkir, es batu, alat kebersihan)
+--            drop    = setor uang dari laci ke brankas (bukan pengeluaran)
+-- ==========================================
+
+CREATE TABLE IF NOT EXISTS cash_movements (
+    id SERIAL PRIMARY KEY,
+    shift_id INT NOT NULL,                               -- Shift kasir yang sedang terbuka
+    user_id INT NOT NULL,                                -- Kasir yang mencatat
+    type VARCHAR(20) NOT NULL,                           -- pay_in / pay_out / drop
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    note TEXT,                                           -- Keterangan (misal: "Beli es batu")
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_cash_movements_shift FOREIGN KEY (shift_id) REFERENCES cashier_shifts(id),
+    CONSTRAINT fk_cash_movements_user FOREIGN KEY (user_id) REFERENCES users(id),
+    CONSTRAINT chk_cash_movements_type CHECK (type IN ('pay_in', 'pay_out', 'drop'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_cash_movements_shift_id ON cash_movements(shift_id);
+CREATE INDEX IF NOT EXISTS idx_cash_movements_created_at ON cash_movements(created_at);
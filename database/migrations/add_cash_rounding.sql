@@ -0,0 +1,11 @@
+-- ==========================================
+-- MIGRATION: Tambah Kolom rounding_amount di transactions
+-- Tanggal: 2026-10-17
+-- Deskripsi: Selisih pembulatan porsi tunai (CASH_ROUNDING_UNIT / CASH_ROUNDING_MODE).
+--            Positif = customer membayar lebih dari total, negatif = dibulatkan ke bawah.
+--            total_amount tetap nilai penjualan sebelum pembulatan;
+--            baris cash di transaction_payments sudah termasuk pembulatan.
+-- ==========================================
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS rounding_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
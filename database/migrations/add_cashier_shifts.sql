@@ -0,0 +1,39 @@
+-- ==========================================
+-- MIGRATION: Tambah Shift Kasir (Sesi Laci Kas)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Kasir membuka shift dengan modal awal (opening float) dan menutupnya
+--            dengan hitungan uang fisik per pecahan. Setiap checkout, retur & void
+--            otomatis terhubung ke shift yang sedang terbuka.
+-- ==========================================
+
+-- 1. TABLE: CASHIER_SHIFTS
+CREATE TABLE IF NOT EXISTS cashier_shifts (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,                                -- Kasir pemilik shift
+    opening_float DECIMAL(15, 2) NOT NULL DEFAULT 0,     -- Modal awal di laci
+    opened_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP,                                 -- NULL = shift masih terbuka
+    expected_cash DECIMAL(15, 2),                        -- Uang yang seharusnya ada (dihitung saat tutup)
+    counted_cash DECIMAL(15, 2),                         -- Uang fisik yang dihitung kasir
+    cash_variance DECIMAL(15, 2),                        -- counted - expected (minus = kurang)
+    denominations JSONB,                                 -- Rincian hitungan per pecahan
+    closing_note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_cashier_shifts_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+-- 1 kasir hanya boleh punya 1 shift terbuka
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cashier_shifts_open_per_user ON cashier_shifts(user_id) WHERE closed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_cashier_shifts_opened_at ON cashier_shifts(opened_at);
+
+-- 2. Hubungkan transaksi & retur ke shift
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS shift_id INT REFERENCES cashier_shifts(id);
+ALTER TABLE sales_returns ADD COLUMN IF NOT EXISTS shift_id INT REFERENCES cashier_shifts(id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_shift_id ON transactions(shift_id);
+CREATE INDEX IF NOT EXISTS idx_sales_returns_shift_id ON sales_returns(shift_id);
+
+-- 3. Shift yang memproses void (uang tunai void keluar dari laci shift ini, bukan shift penjualan)
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS voided_shift_id INT REFERENCES cashier_shifts(id);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_voided_shift_id ON transactions(voided_shift_id);
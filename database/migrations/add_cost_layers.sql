@@ -0,0 +1,28 @@
+-- ==========================================
+-- MIGRATION: Harga Pokok Persediaan (Cost Layers)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Lapisan biaya per barang masuk (pembelian, retur, void, penyesuaian, stock opname)
+--            yang dikurangi saat barang keluar (urut terlama). Dipakai untuk metode FIFO dan
+--            selalu dicatat agar COSTING_METHOD bisa diganti (average / fifo / last).
+-- ==========================================
+
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    source_type VARCHAR(20) NOT NULL,   -- Tipe stock_movements penyebab barang masuk
+    reference_id INT,                   -- ID pembelian / transaksi / dokumen sesuai source_type
+    unit_cost DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    remaining INT NOT NULL CHECK (remaining >= 0 AND remaining <= quantity),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Lapisan yang masih bersisa, diambil urut id (terlama dulu)
+CREATE INDEX IF NOT EXISTS idx_cost_layers_open ON cost_layers(product_id, id) WHERE remaining > 0;
+
+-- Saldo awal: stok saat ini dengan harga_beli saat ini sebagai 1 lapisan
+INSERT INTO cost_layers (product_id, source_type, unit_cost, quantity, remaining)
+SELECT p.id, 'adjustment', COALESCE(p.harga_beli, 0), p.stok, p.stok
+FROM products p
+WHERE p.stok > 0
+  AND NOT EXISTS (SELECT 1 FROM cost_layers l WHERE l.product_id = p.id);
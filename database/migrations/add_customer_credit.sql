@@ -0,0 +1,44 @@
+-- ==========================================
+-- MIGRATION: Kasbon Pelanggan (Piutang / Store Credit)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Menambah limit kasbon per pelanggan dan buku besar piutang
+--            (customer_credit_ledger). Saldo kasbon = SUM(charge) - SUM(repayment, return, void).
+--            sales_returns.credit_refund = bagian refund retur yang memotong kasbon (bukan uang tunai).
+-- ==========================================
+
+-- 1. Limit kasbon (0 = pelanggan tidak boleh kasbon)
+ALTER TABLE customers
+    ADD COLUMN IF NOT EXISTS credit_limit DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+-- 2. TABLE: CUSTOMER_CREDIT_LEDGER
+-- charge    = checkout dengan tender "credit" (piutang bertambah)
+-- repayment = pembayaran / cicilan kasbon (piutang berkurang, uang masuk)
+-- return    = retur barang dari transaksi kasbon (piutang berkurang, tanpa uang keluar)
+-- void      = pembatalan transaksi kasbon (piutang berkurang)
+CREATE TABLE IF NOT EXISTS customer_credit_ledger (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL REFERENCES customers(id),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('charge', 'repayment', 'return', 'void')),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    transaction_id INT REFERENCES transactions(id) ON DELETE SET NULL,
+    sales_return_id INT REFERENCES sales_returns(id) ON DELETE SET NULL,
+    method VARCHAR(20),                                  -- Metode pembayaran cicilan (cash / qris / debit / transfer)
+    reference VARCHAR(100),
+    note TEXT,
+    created_by INT REFERENCES users(id),
+    shift_id INT REFERENCES cashier_shifts(id),          -- Cicilan tunai masuk ke laci shift ini
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_credit_ledger_customer ON customer_credit_ledger(customer_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_customer_credit_ledger_transaction ON customer_credit_ledger(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_customer_credit_ledger_created_at ON customer_credit_ledger(created_at);
+
+-- 3. Bagian refund retur yang dipotong dari kasbon (sisanya tunai)
+ALTER TABLE sales_returns
+    ADD COLUMN IF NOT EXISTS credit_refund DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+-- 4. Tender "credit" di transaction_payments (constraint lama hanya cash / qris / debit / transfer)
+ALTER TABLE transaction_payments DROP CONSTRAINT IF EXISTS chk_transaction_payments_method;
+ALTER TABLE transaction_payments ADD CONSTRAINT chk_transaction_payments_method
+    CHECK (method IN ('cash', 'qris', 'debit', 'transfer', 'credit'));
@@ -0,0 +1,29 @@
+-- ==========================================
+-- MIGRATION: Tambah Master Data Pelanggan (Customers)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Menambah tabel customers (no. HP sebagai natural key) dan
+--            kolom transactions.customer_id (opsional) untuk riwayat belanja pelanggan.
+--            Total belanja / jumlah kunjungan dihitung dari transaksi, tidak disimpan.
+-- ==========================================
+
+-- 1. TABLE: CUSTOMERS
+CREATE TABLE IF NOT EXISTS customers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    phone VARCHAR(20) NOT NULL,                          -- Dinormalisasi: hanya digit, awalan 0 (0812...)
+    email VARCHAR(150),
+    address TEXT,
+    notes TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_customers_phone UNIQUE (phone)
+);
+
+CREATE INDEX IF NOT EXISTS idx_customers_name ON customers(name);
+
+-- 2. Relasi transaksi → pelanggan (NULL = pelanggan umum / walk-in)
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS customer_id INT REFERENCES customers(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_customer_id ON transactions(customer_id);
@@ -0,0 +1,22 @@
+-- ==========================================
+-- MIGRATION: Jadwal Diskon (Hari & Jam / Happy Hour)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Diskon bisa dibatasi ke hari tertentu dan jendela jam tertentu
+--            (mis. Jumat 15:00 - 17:00) di timezone toko (STORE_TIMEZONE),
+--            di dalam periode start_date - end_date.
+-- ==========================================
+
+-- days_of_week = hari ISO dipisah koma: 1 = Senin ... 7 = Minggu (NULL = setiap hari)
+-- time_start   = jam mulai "HH:MM" (inklusif), time_end = jam selesai "HH:MM" (eksklusif)
+--                time_end < time_start → melewati tengah malam, jam setelah tengah malam ikut hari mulainya
+ALTER TABLE discounts
+  ADD COLUMN IF NOT EXISTS days_of_week VARCHAR(20) DEFAULT NULL
+    CHECK (days_of_week ~ '^[1-7](,[1-7])*$'),
+  ADD COLUMN IF NOT EXISTS time_start VARCHAR(5) DEFAULT NULL
+    CHECK (time_start ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'),
+  ADD COLUMN IF NOT EXISTS time_end VARCHAR(5) DEFAULT NULL
+    CHECK (time_end ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$');
+
+ALTER TABLE discounts DROP CONSTRAINT IF EXISTS discounts_time_window_check;
+ALTER TABLE discounts ADD CONSTRAINT discounts_time_window_check
+  CHECK ((time_start IS NULL AND time_end IS NULL) OR (time_start IS NOT NULL AND time_end IS NOT NULL AND time_start <> time_end));
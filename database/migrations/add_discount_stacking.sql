@@ -0,0 +1,43 @@
+-- ==========================================
+-- MIGRATION: Aturan Stacking & Prioritas Diskon
+-- Tanggal: 2026-10-17
+-- Deskripsi: Setiap diskon punya stacking_mode (STACKABLE / EXCLUSIVE), priority dan
+--            max_total_percent. Kombinasi diskon yang dipakai per baris dicatat di
+--            transaction_discounts untuk audit.
+-- ==========================================
+
+-- 1. DISCOUNTS: aturan stacking
+-- stacking_mode     = EXCLUSIVE → hanya dipakai di baris yang belum dapat diskon & menutup baris untuk diskon lain
+-- priority          = urutan evaluasi (besar dulu); sama → diskon produk/kategori, promosi, lalu diskon transaksi
+-- max_total_percent = batas total diskon baris (% dari harga × qty) jika diskon ini ikut dipakai (NULL = tanpa batas)
+ALTER TABLE discounts
+  ADD COLUMN IF NOT EXISTS stacking_mode VARCHAR(20) NOT NULL DEFAULT 'STACKABLE'
+    CHECK (stacking_mode IN ('STACKABLE', 'EXCLUSIVE')),
+  ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS max_total_percent DECIMAL(5, 2) DEFAULT NULL
+    CHECK (max_total_percent > 0 AND max_total_percent <= 100);
+
+-- 2. TRANSACTION_DETAILS: porsi diskon transaksi (discount_id / voucher) per baris
+-- Dipakai retur agar refund baris yang dikecualikan dari diskon transaksi tetap tepat
+ALTER TABLE transaction_details
+  ADD COLUMN IF NOT EXISTS order_discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+-- 3. TABLE: TRANSACTION_DISCOUNTS (audit kombinasi diskon per baris)
+CREATE TABLE IF NOT EXISTS transaction_discounts (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    transaction_detail_id INT REFERENCES transaction_details(id) ON DELETE CASCADE,
+    discount_id INT REFERENCES discounts(id) ON DELETE SET NULL,
+    voucher_id INT REFERENCES vouchers(id) ON DELETE SET NULL,
+    level VARCHAR(20) NOT NULL CHECK (level IN ('ITEM', 'PROMOTION', 'ORDER', 'MANUAL')),
+    name VARCHAR(100) NOT NULL,
+    stacking_mode VARCHAR(20) NOT NULL DEFAULT 'STACKABLE',
+    priority INT NOT NULL DEFAULT 0,
+    amount DECIMAL(15, 2) NOT NULL,
+    capped BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 4. INDEXES
+CREATE INDEX IF NOT EXISTS idx_transaction_discounts_transaction ON transaction_discounts(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_discounts_discount ON transaction_discounts(discount_id) WHERE discount_id IS NOT NULL;
@@ -0,0 +1,37 @@
+-- ==========================================
+-- MIGRATION: Tambah Held Cart (Keranjang Parkir) & Reservasi Stok
+-- Tanggal: 2026-10-17
+-- Deskripsi: Kasir bisa menyimpan keranjang customer sementara (draft CheckoutRequest)
+--            lalu melanjutkannya nanti. Opsional: stok di-reservasi sampai expires_at.
+-- ==========================================
+
+-- 1. TABLE: HELD_CARTS
+CREATE TABLE IF NOT EXISTS held_carts (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,                                -- Kasir pemilik keranjang
+    label VARCHAR(100),                                  -- Penanda keranjang (misal: "Bapak baju biru")
+    payload JSONB NOT NULL,                              -- Draft CheckoutRequest (items, discount_id, dll)
+    reserve_stock BOOLEAN NOT NULL DEFAULT FALSE,        -- TRUE = stok item di-reservasi
+    expires_at TIMESTAMP,                                -- Batas reservasi stok (NULL jika tidak reservasi)
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_held_carts_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_held_carts_user_id ON held_carts(user_id);
+
+-- 2. TABLE: STOCK_RESERVATIONS
+-- Stok yang di-reservasi held cart; dihitung mengurangi stok tersedia saat checkout lain
+-- selama expires_at > NOW(). Terhapus otomatis saat cart dihapus / di-checkout.
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id SERIAL PRIMARY KEY,
+    cart_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_stock_reservations_cart FOREIGN KEY (cart_id) REFERENCES held_carts(id) ON DELETE CASCADE,
+    CONSTRAINT fk_stock_reservations_product FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_product_expires ON stock_reservations(product_id, expires_at);
@@ -0,0 +1,32 @@
+-- ==========================================
+-- MIGRATION: Tambah Tabel idempotency_keys
+-- Tanggal: 2026-10-17
+-- Deskripsi: Menyimpan Idempotency-Key dari client (checkout & pembelian)
+--            agar request yang di-retry (misal Wi-Fi putus) tidak membuat
+--            transaksi ganda. Response pertama disimpan dan dikirim ulang saat replay.
+--            Key yang macet di status processing (server crash / panic) bisa diambil alih
+--            oleh retry setelah lewat batas waktu (lihat locked_at).
+-- ==========================================
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id SERIAL PRIMARY KEY,
+    scope VARCHAR(50) NOT NULL,                          -- checkout / purchase
+    idempotency_key VARCHAR(255) NOT NULL,               -- Nilai header Idempotency-Key
+    user_id INT NOT NULL,                                -- Key berlaku per user
+    request_hash CHAR(64) NOT NULL,                      -- SHA-256 method + path + body (deteksi request berbeda)
+    status VARCHAR(20) NOT NULL DEFAULT 'processing',    -- processing / completed
+    response_status INT,                                 -- HTTP status response pertama
+    response_body TEXT,                                  -- Body response pertama (untuk replay)
+    resource_id INT,                                     -- ID transaksi / pembelian yang dihasilkan
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    locked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,       -- Waktu mulai diproses (key processing yang macet bisa diambil alih)
+    completed_at TIMESTAMP,
+    CONSTRAINT uq_idempotency_keys UNIQUE (scope, user_id, idempotency_key),
+    CONSTRAINT fk_idempotency_keys_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Instalasi lama: tambah kolom locked_at
+ALTER TABLE idempotency_keys
+    ADD COLUMN IF NOT EXISTS locked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
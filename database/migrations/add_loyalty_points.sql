@@ -0,0 +1,38 @@
+-- ==========================================
+-- MIGRATION: Program Poin Loyalitas Pelanggan
+-- Tanggal: 2026-10-17
+-- Deskripsi: Buku besar poin per pelanggan (loyalty_points_ledger). Saldo = SUM(points).
+--            Poin earn punya expires_at; poin kedaluwarsa dicatat sebagai baris expire (FIFO).
+--            sales_returns.points_refund = bagian refund retur yang dikembalikan sebagai poin (rupiah).
+-- ==========================================
+
+-- 1. TABLE: LOYALTY_POINTS_LEDGER
+-- earn    = poin dari transaksi (+)
+-- redeem  = poin dipakai sebagai pembayaran / tender "points" (-)
+-- reverse = poin transaksi ditarik karena void / retur (-)
+-- refund  = poin yang dipakai dikembalikan karena void / retur (+)
+-- expire  = poin kedaluwarsa (-)
+CREATE TABLE IF NOT EXISTS loyalty_points_ledger (
+    id SERIAL PRIMARY KEY,
+    customer_id INT NOT NULL REFERENCES customers(id),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('earn', 'redeem', 'reverse', 'refund', 'expire')),
+    points INT NOT NULL CHECK (points <> 0),
+    transaction_id INT REFERENCES transactions(id) ON DELETE SET NULL,
+    sales_return_id INT REFERENCES sales_returns(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP,
+    note TEXT,
+    created_by INT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_points_ledger_customer ON loyalty_points_ledger(customer_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_loyalty_points_ledger_transaction ON loyalty_points_ledger(transaction_id);
+
+-- 2. Bagian refund retur yang dikembalikan sebagai poin (sisanya tunai)
+ALTER TABLE sales_returns
+    ADD COLUMN IF NOT EXISTS points_refund DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+-- 3. Tender "points" di transaction_payments (tetap mempertahankan "credit" dari migration kasbon)
+ALTER TABLE transaction_payments DROP CONSTRAINT IF EXISTS chk_transaction_payments_method;
+ALTER TABLE transaction_payments ADD CONSTRAINT chk_transaction_payments_method
+    CHECK (method IN ('cash', 'qris', 'debit', 'transfer', 'credit', 'points'));
@@ -0,0 +1,27 @@
+-- ==========================================
+-- MIGRATION: Override Harga / Diskon dengan Persetujuan Supervisor
+-- Tanggal: 2026-10-17
+-- Deskripsi: Menyimpan siapa yang menyetujui harga khusus / diskon manual per baris transaksi,
+--            alasan, dan harga asli (harga database saat transaksi) untuk laporan override.
+--            Baris tanpa override: semua kolom NULL (original_price NOT NULL = baris override).
+--            Approval token sekali pakai: jti dicatat di override_token_uses.
+-- ==========================================
+
+ALTER TABLE transaction_details
+    ADD COLUMN IF NOT EXISTS override_approved_by INT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS override_reason TEXT,
+    ADD COLUMN IF NOT EXISTS original_price DECIMAL(15, 2);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_details_override
+    ON transaction_details(transaction_id) WHERE original_price IS NOT NULL;
+
+-- Approval token sekali pakai: jti token dicatat saat checkout berhasil.
+-- PRIMARY KEY pada jti → token yang sama tidak bisa dipakai di checkout lain.
+CREATE TABLE IF NOT EXISTS override_token_uses (
+    jti VARCHAR(64) PRIMARY KEY,
+    approved_by INT REFERENCES users(id) ON DELETE SET NULL,
+    cashier_id INT REFERENCES users(id) ON DELETE SET NULL,
+    product_id INT REFERENCES products(id) ON DELETE SET NULL,
+    transaction_id INT REFERENCES transactions(id) ON DELETE SET NULL,
+    used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,45 @@
+-- ==========================================
+-- MIGRATION: Promosi (Beli X Gratis Y, Paket Bundling, Harga Grosir per Qty)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Tipe diskon baru BUY_X_GET_Y, BUNDLE dan QTY_TIER di tabel discounts.
+--            Dihitung otomatis oleh pricing engine saat checkout; potongan promosi
+--            dicatat per baris di transaction_details (promotion_id, promotion_amount).
+-- ==========================================
+
+-- 1. DISCOUNTS: parameter Beli X Gratis Y
+-- BUY_X_GET_Y: beli buy_qty, dapat get_qty unit termurah dengan potongan value% (100 = gratis)
+-- BUNDLE     : value = harga paket, isi paket di discount_bundle_items
+-- QTY_TIER   : harga satuan per jumlah minimal di discount_qty_tiers
+ALTER TABLE discounts
+  ADD COLUMN IF NOT EXISTS buy_qty INT DEFAULT NULL CHECK (buy_qty > 0),
+  ADD COLUMN IF NOT EXISTS get_qty INT DEFAULT NULL CHECK (get_qty > 0);
+
+-- 2. TABLE: DISCOUNT_BUNDLE_ITEMS (isi paket bundling)
+CREATE TABLE IF NOT EXISTS discount_bundle_items (
+    id SERIAL PRIMARY KEY,
+    discount_id INT NOT NULL REFERENCES discounts(id) ON DELETE CASCADE,
+    product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    CONSTRAINT uq_discount_bundle_items UNIQUE (discount_id, product_id)
+);
+
+-- 3. TABLE: DISCOUNT_QTY_TIERS (harga grosir: beli >= min_qty → harga satuan unit_price)
+CREATE TABLE IF NOT EXISTS discount_qty_tiers (
+    id SERIAL PRIMARY KEY,
+    discount_id INT NOT NULL REFERENCES discounts(id) ON DELETE CASCADE,
+    min_qty INT NOT NULL CHECK (min_qty > 1),
+    unit_price DECIMAL(15, 2) NOT NULL CHECK (unit_price >= 0),
+    CONSTRAINT uq_discount_qty_tiers UNIQUE (discount_id, min_qty)
+);
+
+-- 4. TRANSACTION_DETAILS: atribusi promosi per baris
+-- promotion_amount sudah termasuk di discount_amount (subtotal = harga × qty - discount_amount)
+ALTER TABLE transaction_details
+  ADD COLUMN IF NOT EXISTS promotion_id INT DEFAULT NULL REFERENCES discounts(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS promotion_type VARCHAR(20) DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS promotion_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+-- 5. INDEXES
+CREATE INDEX IF NOT EXISTS idx_discount_bundle_items_discount ON discount_bundle_items(discount_id);
+CREATE INDEX IF NOT EXISTS idx_discount_qty_tiers_discount ON discount_qty_tiers(discount_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_details_promotion ON transaction_details(promotion_id) WHERE promotion_id IS NOT NULL;
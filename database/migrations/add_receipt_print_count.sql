@@ -0,0 +1,9 @@
+-- ==========================================
+-- MIGRATION: Tambah Kolom print_count di transactions
+-- Tanggal: 2026-10-17
+-- Deskripsi: Menghitung berapa kali struk transaksi dicetak.
+--            Cetakan pertama = 1, selanjutnya ditandai sebagai cetak ulang di struk.
+-- ==========================================
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS print_count INT NOT NULL DEFAULT 0;
@@ -0,0 +1,39 @@
+-- ==========================================
+-- MIGRATION: Tambah Modul Retur Penjualan (Sales Return)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Menambah tabel sales_returns dan sales_return_items
+--            untuk mencatat pengembalian sebagian item dari transaksi
+-- ==========================================
+
+-- 1. TABLE: SALES_RETURNS (Header Retur)
+CREATE TABLE IF NOT EXISTS sales_returns (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL,                         -- FK ke transaksi asal
+    total_refund DECIMAL(15, 2) NOT NULL DEFAULT 0,      -- Total uang yang dikembalikan ke customer
+    reason TEXT,                                         -- Alasan retur (optional)
+    created_by INT,                                      -- User yang memproses retur
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_sales_returns_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_sales_returns_created_by FOREIGN KEY (created_by) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_returns_transaction_id ON sales_returns(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_sales_returns_created_at ON sales_returns(created_at);
+
+-- 2. TABLE: SALES_RETURN_ITEMS (Detail Item Retur)
+CREATE TABLE IF NOT EXISTS sales_return_items (
+    id SERIAL PRIMARY KEY,
+    return_id INT NOT NULL,                              -- FK ke header retur
+    transaction_detail_id INT NOT NULL,                  -- FK ke baris transaction_details asal
+    product_id INT NOT NULL,                             -- Produk yang diretur
+    quantity INT NOT NULL CHECK (quantity > 0),          -- Jumlah yang diretur
+    refund_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,     -- Uang kembali untuk baris ini (proporsional diskon)
+    harga_beli DECIMAL(15, 2) NOT NULL DEFAULT 0,        -- Snapshot HPP per unit dari transaksi asal
+    restock BOOLEAN NOT NULL DEFAULT TRUE,               -- TRUE = masuk stok lagi, FALSE = write-off (rusak)
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_sales_return_items_return FOREIGN KEY (return_id) REFERENCES sales_returns(id) ON DELETE CASCADE,
+    CONSTRAINT fk_sales_return_items_detail FOREIGN KEY (transaction_detail_id) REFERENCES transaction_details(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sales_return_items_return_id ON sales_return_items(return_id);
+CREATE INDEX IF NOT EXISTS idx_sales_return_items_detail_id ON sales_return_items(transaction_detail_id);
@@ -0,0 +1,42 @@
+-- ==========================================
+-- MIGRATION: Penyesuaian Stok Manual (Stock Adjustment)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Dokumen penyesuaian stok multi-baris dengan reason code (rusak, kedaluwarsa,
+--            hilang, ditemukan, pemakaian internal, sampel). Write-off besar dari non-admin
+--            menunggu persetujuan admin. Nilai dihitung dengan harga beli dan mengurangi laba.
+-- ==========================================
+
+-- 1. STOCK_ADJUSTMENTS: header dokumen
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+    id SERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'approved' CHECK (status IN ('pending', 'approved', 'rejected')),
+    notes TEXT,
+    write_off_value DECIMAL(15, 2) NOT NULL DEFAULT 0,  -- Nilai barang keluar (harga beli)
+    found_value DECIMAL(15, 2) NOT NULL DEFAULT 0,      -- Nilai barang ditemukan (harga beli)
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    approved_by INT REFERENCES users(id) ON DELETE SET NULL,
+    approved_at TIMESTAMP WITH TIME ZONE,               -- Tanggal yang dipakai laporan write-off & laba
+    rejected_by INT REFERENCES users(id) ON DELETE SET NULL,
+    rejected_at TIMESTAMP WITH TIME ZONE,
+    reject_reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_status ON stock_adjustments(status);
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_approved_at ON stock_adjustments(approved_at);
+
+-- 2. STOCK_ADJUSTMENT_ITEMS: baris penyesuaian
+CREATE TABLE IF NOT EXISTS stock_adjustment_items (
+    id SERIAL PRIMARY KEY,
+    adjustment_id INT NOT NULL REFERENCES stock_adjustments(id) ON DELETE CASCADE,
+    product_id INT NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('damaged', 'expired', 'lost', 'found', 'internal_use', 'sample')),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    harga_beli DECIMAL(15, 2) NOT NULL DEFAULT 0,       -- Snapshot saat dokumen dibuat
+    value DECIMAL(15, 2) NOT NULL DEFAULT 0,            -- quantity × harga_beli
+    note TEXT,
+    stock_before INT,                                   -- Diisi saat disetujui
+    stock_after INT
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_adjustment_items_adjustment ON stock_adjustment_items(adjustment_id);
@@ -0,0 +1,45 @@
+-- ==========================================
+-- MIGRATION: Ledger Pergerakan Stok
+-- Tanggal: 2026-10-17
+-- Deskripsi: Setiap perubahan products.stok (penjualan, pembelian, penyesuaian, retur, void,
+--            transfer) dicatat di stock_movements dalam database transaction yang sama,
+--            lengkap dengan referensi, user dan stok sebelum / sesudah. Ledger append-only.
+-- ==========================================
+
+-- product_id sengaja tanpa foreign key: riwayat stok tetap ada walau produk dihapus
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    type VARCHAR(20) NOT NULL
+        CHECK (type IN ('sale', 'purchase', 'adjustment', 'return', 'void', 'transfer')),
+    quantity INT NOT NULL CHECK (quantity <> 0),   -- + masuk, - keluar
+    stock_before INT NOT NULL,
+    stock_after INT NOT NULL,
+    reference_id INT,                              -- transaction_id / purchase_id / sales_return_id sesuai type
+    note TEXT,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (stock_after = stock_before + quantity)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements(product_id, id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference ON stock_movements(type, reference_id);
+
+-- Append-only: UPDATE / DELETE ditolak
+CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements bersifat append-only (% tidak diizinkan)', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_stock_movements_append_only ON stock_movements;
+CREATE TRIGGER trg_stock_movements_append_only
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
+
+-- Saldo awal: stok saat ini dicatat sebagai adjustment agar SUM(quantity) = products.stok
+INSERT INTO stock_movements (product_id, type, quantity, stock_before, stock_after, note)
+SELECT p.id, 'adjustment', p.stok, 0, p.stok, 'Saldo awal ledger stok'
+FROM products p
+WHERE p.stok <> 0
+  AND NOT EXISTS (SELECT 1 FROM stock_movements sm WHERE sm.product_id = p.id);
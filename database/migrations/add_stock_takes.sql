@@ -0,0 +1,61 @@
+-- ==========================================
+-- MIGRATION: Stock Opname (Hitung Fisik Stok)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Sesi stock opname (seluruh toko / per kategori). Hitungan fisik dicatat
+--            per batch scan barcode, selisih dibandingkan dengan stok sistem saat produk dihitung,
+--            lalu sesi di-posting: selisih diterapkan ke stok secara atomik dan dicatat di stock_movements.
+-- ==========================================
+
+-- 1. STOCK_TAKES: header sesi
+CREATE TABLE IF NOT EXISTS stock_takes (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    scope VARCHAR(20) NOT NULL DEFAULT 'all' CHECK (scope IN ('all', 'category')),
+    category_id INT REFERENCES categories(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'posted', 'cancelled')),
+    notes TEXT,
+    created_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    posted_by INT REFERENCES users(id) ON DELETE SET NULL,
+    posted_at TIMESTAMP WITH TIME ZONE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    products_in_scope INT,              -- Jumlah produk dalam cakupan saat posting
+    CHECK (scope = 'all' OR category_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_takes_status ON stock_takes(status);
+
+-- 2. STOCK_TAKE_ITEMS: hitungan per produk
+-- system_stock di-snapshot saat produk pertama kali dihitung (selisih tidak terpengaruh transaksi setelahnya);
+-- variance, harga_beli & variance_value di-snapshot saat posting
+CREATE TABLE IF NOT EXISTS stock_take_items (
+    id SERIAL PRIMARY KEY,
+    stock_take_id INT NOT NULL REFERENCES stock_takes(id) ON DELETE CASCADE,
+    product_id INT NOT NULL,
+    counted_qty INT NOT NULL DEFAULT 0 CHECK (counted_qty >= 0),
+    system_stock INT,                   -- Stok sistem saat produk pertama kali dihitung
+    variance INT,                       -- counted_qty - system_stock (- = susut / hilang)
+    harga_beli DECIMAL(15, 2),
+    variance_value DECIMAL(15, 2),      -- variance × harga_beli
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (stock_take_id, product_id)
+);
+
+-- 3. STOCK_TAKE_COUNTS: log setiap scan / batch hitungan (audit trail)
+CREATE TABLE IF NOT EXISTS stock_take_counts (
+    id SERIAL PRIMARY KEY,
+    stock_take_id INT NOT NULL REFERENCES stock_takes(id) ON DELETE CASCADE,
+    product_id INT NOT NULL,
+    barcode VARCHAR(100),
+    quantity INT NOT NULL,              -- + tambah hitungan, - koreksi
+    counted_qty INT NOT NULL,           -- total hitungan produk setelah baris ini
+    counted_by INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_take_counts_session ON stock_take_counts(stock_take_id, product_id);
+
+-- 4. STOCK_MOVEMENTS: tipe baru 'stock_take' (reference_id = stock_takes.id)
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_type_check;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_type_check
+    CHECK (type IN ('sale', 'purchase', 'adjustment', 'return', 'void', 'transfer', 'stock_take'));
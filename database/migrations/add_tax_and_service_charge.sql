@@ -0,0 +1,54 @@
+-- ==========================================
+-- MIGRATION: Pajak (PPN) & Service Charge
+-- Tanggal: 2026-10-17
+-- Deskripsi: Tabel tax_classes (tarif, inclusive/exclusive, bebas pajak),
+--            tax_class_id di products & categories, snapshot pajak per baris
+--            transaction_details dan total pajak/service charge di header transaksi.
+--            Prioritas kelas pajak: produk → kategori → kelas default (is_default).
+--            Tanpa kelas default, transaksi tidak dikenakan pajak (perilaku lama).
+-- ==========================================
+
+CREATE TABLE IF NOT EXISTS tax_classes (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,                   -- Contoh: "PPN 11%", "Bebas PPN"
+    rate DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK (rate >= 0 AND rate <= 100),
+    is_inclusive BOOLEAN NOT NULL DEFAULT FALSE,         -- TRUE = harga jual sudah termasuk pajak
+    is_exempt BOOLEAN NOT NULL DEFAULT FALSE,            -- TRUE = barang/jasa dibebaskan dari pajak
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,           -- Dipakai jika produk & kategori tidak punya kelas pajak
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Hanya boleh ada 1 kelas pajak default
+CREATE UNIQUE INDEX IF NOT EXISTS uq_tax_classes_default ON tax_classes(is_default) WHERE is_default = TRUE;
+
+INSERT INTO tax_classes (name, rate, is_inclusive, is_exempt) VALUES
+    ('PPN 11%', 11, FALSE, FALSE),
+    ('Bebas PPN', 0, FALSE, TRUE)
+ON CONFLICT (name) DO NOTHING;
+
+-- Kelas pajak per produk / kategori (NULL = ikut kategori / default)
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_class_id INT REFERENCES tax_classes(id) ON DELETE SET NULL;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS tax_class_id INT REFERENCES tax_classes(id) ON DELETE SET NULL;
+
+-- Snapshot pajak per baris transaksi
+ALTER TABLE transaction_details
+    ADD COLUMN IF NOT EXISTS tax_class_id INT,                                    -- Kelas pajak saat transaksi (tanpa FK agar histori aman)
+    ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS taxable_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,     -- DPP (termasuk service charge yang kena pajak)
+    ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,         -- PPN baris ini (barang + service charge)
+    ADD COLUMN IF NOT EXISTS service_charge_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+-- Total pajak & service charge di header transaksi
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,          -- Total PPN
+    ADD COLUMN IF NOT EXISTS tax_included_amount DECIMAL(15, 2) NOT NULL DEFAULT 0, -- Bagian PPN yang sudah termasuk di harga (inclusive)
+    ADD COLUMN IF NOT EXISTS service_charge_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+-- PPN yang ikut dikembalikan saat retur (mengurangi PPN terutang)
+ALTER TABLE sales_return_items
+    ADD COLUMN IF NOT EXISTS tax_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_transaction_details_tax_class_id ON transaction_details(tax_class_id);
@@ -0,0 +1,22 @@
+-- ==========================================
+-- MIGRATION: Tambah Tabel transaction_payments (Split Tender)
+-- Tanggal: 2026-10-17
+-- Deskripsi: Menyimpan rincian metode pembayaran per transaksi
+--            (cash, qris, debit, transfer) — 1 transaksi bisa dibayar campuran.
+--            Transaksi lama tanpa baris pembayaran dianggap cash.
+-- ==========================================
+
+CREATE TABLE IF NOT EXISTS transaction_payments (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    method VARCHAR(20) NOT NULL,                         -- cash / qris / debit / transfer
+    amount DECIMAL(15, 2) NOT NULL DEFAULT 0,            -- Nominal yang dipakai untuk membayar (setelah kembalian)
+    tendered_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,   -- Nominal yang diserahkan customer (cash bisa > amount)
+    reference VARCHAR(100),                              -- No. referensi EDC / QRIS / transfer (optional)
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_transaction_payments_transaction FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    CONSTRAINT chk_transaction_payments_method CHECK (method IN ('cash', 'qris', 'debit', 'transfer'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_payments_transaction_id ON transaction_payments(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_payments_method ON transaction_payments(method);
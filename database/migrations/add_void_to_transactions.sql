@@ -0,0 +1,13 @@
+-- ==========================================
+-- MIGRATION: Tambah fitur void (pembatalan) transaksi
+-- Tanggal: 2026-10-17
+-- Deskripsi: Menyimpan waktu, user, dan alasan pembatalan transaksi.
+--            Transaksi yang sudah di-void tidak dihitung di laporan & arus kas.
+-- ==========================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS voided_at TIMESTAMP DEFAULT NULL;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS voided_by INT REFERENCES users(id);
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS void_reason TEXT;
+
+-- Index parsial untuk filter transaksi aktif (non-void) di laporan
+CREATE INDEX IF NOT EXISTS idx_transactions_not_voided ON transactions(created_at) WHERE voided_at IS NULL;
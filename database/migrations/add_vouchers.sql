@@ -0,0 +1,52 @@
+-- ==========================================
+-- MIGRATION: Voucher / Kode Kupon
+-- Tanggal: 2026-10-17
+-- Deskripsi: Kode voucher yang bisa dicetak (sekali pakai / berkali-kali), dengan kuota total,
+--            batas per pelanggan dan masa berlaku. Dipakai saat checkout lewat voucher_code
+--            (pengganti discount_id). Setiap pemakaian dicatat di voucher_redemptions.
+-- ==========================================
+
+-- 1. TABLE: VOUCHERS
+-- max_redemptions    = kuota total (NULL = tanpa batas, 1 = sekali pakai)
+-- per_customer_limit = batas pemakaian per pelanggan (NULL = tanpa batas, wajib customer_id jika diisi)
+-- redemption_count   = jumlah pemakaian aktif (di-increment atomik saat checkout, dikurangi saat void)
+CREATE TABLE IF NOT EXISTS vouchers (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('PERCENTAGE', 'FIXED')),
+    value DECIMAL(15, 2) NOT NULL CHECK (value > 0),
+    min_order_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    max_redemptions INT CHECK (max_redemptions > 0),
+    per_customer_limit INT CHECK (per_customer_limit > 0),
+    redemption_count INT NOT NULL DEFAULT 0 CHECK (redemption_count >= 0),
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by INT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_vouchers_code UNIQUE (code),
+    CONSTRAINT chk_vouchers_quota CHECK (max_redemptions IS NULL OR redemption_count <= max_redemptions)
+);
+
+-- 2. TABLE: VOUCHER_REDEMPTIONS
+-- voided_at diisi saat transaksi di-void (pemakaian dikembalikan ke kuota)
+CREATE TABLE IF NOT EXISTS voucher_redemptions (
+    id SERIAL PRIMARY KEY,
+    voucher_id INT NOT NULL REFERENCES vouchers(id),
+    transaction_id INT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    customer_id INT REFERENCES customers(id) ON DELETE SET NULL,
+    discount_amount DECIMAL(15, 2) NOT NULL DEFAULT 0,
+    created_by INT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    voided_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_voucher_redemptions_voucher ON voucher_redemptions(voucher_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_voucher_redemptions_customer ON voucher_redemptions(voucher_id, customer_id) WHERE voided_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_voucher_redemptions_transaction ON voucher_redemptions(transaction_id);
+
+-- 3. Voucher yang dipakai di transaksi (diskon global dari voucher, discount_id tetap NULL)
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS voucher_id INT REFERENCES vouchers(id);
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+)
+
+// CashMovementHandler handles HTTP requests for drawer cash movements
+type CashMovementHandler struct {
+	service *services.CashMovementService
+}
+
+// NewCashMovementHandler creates a new CashMovementHandler
+func NewCashMovementHandler(service *services.CashMovementService) *CashMovementHandler {
+	return &CashMovementHandler{service: service}
+}
+
+// HandleCashMovements handles /api/cash-movements (GET riwayat, POST catat baru)
+func (h *CashMovementHandler) HandleCashMovements(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetAll(w, r)
+	case "POST":
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Create handles POST /api/cash-movements
+func (h *CashMovementHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CashMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	movement, err := h.service.Create(user.ID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidCashMovement), errors.Is(err, models.ErrInvalidCashAmount):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, models.ErrNoOpenShift):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Pergerakan kas berhasil dicatat",
+		"data":    movement,
+	})
+}
+
+// GetAll handles GET /api/cash-movements?shift_id=
+func (h *CashMovementHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var shiftID *int
+	if shiftIDStr := r.URL.Query().Get("shift_id"); shiftIDStr != "" {
+		id, err := strconv.Atoi(shiftIDStr)
+		if err != nil {
+			http.Error(w, "shift_id tidak valid", http.StatusBadRequest)
+			return
+		}
+		shiftID = &id
+	}
+
+	movements, err := h.service.GetAll(user, shiftID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": movements,
+	})
+}
@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CustomerHandler handles HTTP requests for customers
+type CustomerHandler struct {
+	service        *services.CustomerService
+	creditService  *services.CustomerCreditService
+	loyaltyService *services.LoyaltyService
+}
+
+// NewCustomerHandler creates a new CustomerHandler
+func NewCustomerHandler(service *services.CustomerService, creditService *services.CustomerCreditService, loyaltyService *services.LoyaltyService) *CustomerHandler {
+	return &CustomerHandler{service: service, creditService: creditService, loyaltyService: loyaltyService}
+}
+
+// HandleCustomers handles /api/customers (GET list / lookup by phone, POST create)
+func (h *CustomerHandler) HandleCustomers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetAll(w, r)
+	case "POST":
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleCustomerByID handles /api/customers/{id} (GET, PUT, DELETE),
+// GET /api/customers/{id}/transactions, GET /api/customers/{id}/credit,
+// POST /api/customers/{id}/credit/payments dan GET /api/customers/{id}/points
+func (h *CustomerHandler) HandleCustomerByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/customers/")
+	if strings.HasSuffix(path, "/credit/payments") {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.RepayCredit(w, r)
+		return
+	}
+	if strings.HasSuffix(path, "/credit") {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetCredit(w, r)
+		return
+	}
+	if strings.HasSuffix(path, "/points") {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetPoints(w, r)
+		return
+	}
+	if strings.HasSuffix(path, "/transactions") {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetTransactions(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(path)
+	if err != nil {
+		http.Error(w, "ID pelanggan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		customer, err := h.service.GetByID(id)
+		if err != nil {
+			writeCustomerError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": customer,
+		})
+	case "PUT":
+		h.Update(w, r, id)
+	case "DELETE":
+		h.SoftDelete(w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll handles GET /api/customers?search=...&sort=name|spend|visits|recent&page=1&limit=10
+// GET /api/customers?phone=0812... → lookup 1 pelanggan by no. HP (natural key)
+func (h *CustomerHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	if phone := r.URL.Query().Get("phone"); phone != "" {
+		customer, err := h.service.GetByPhone(phone)
+		if err != nil {
+			writeCustomerError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": customer,
+		})
+		return
+	}
+
+	pagination := parseCustomerPagination(r)
+	customers, totalCount, err := h.service.GetAll(r.URL.Query().Get("search"), r.URL.Query().Get("sort"), &pagination)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.PaginatedResponse{
+		Data: customers,
+		Pagination: models.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: totalCount,
+			TotalPages: models.CalculateTotalPages(totalCount, pagination.Limit),
+		},
+	})
+}
+
+// Create handles POST /api/customers
+func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.CustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !canSetCreditLimit(r, req) {
+		http.Error(w, "Forbidden: Hanya Admin yang bisa mengatur limit kasbon", http.StatusForbidden)
+		return
+	}
+
+	customer, err := h.service.Create(req)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Pelanggan berhasil dibuat",
+		"data":    customer,
+	})
+}
+
+// Update handles PUT /api/customers/{id}
+func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.CustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !canSetCreditLimit(r, req) {
+		http.Error(w, "Forbidden: Hanya Admin yang bisa mengatur limit kasbon", http.StatusForbidden)
+		return
+	}
+
+	customer, err := h.service.Update(id, req)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Pelanggan berhasil diupdate",
+		"data":    customer,
+	})
+}
+
+// SoftDelete handles DELETE /api/customers/{id} (Admin only)
+func (h *CustomerHandler) SoftDelete(w http.ResponseWriter, r *http.Request, id int) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Hanya Admin yang bisa menonaktifkan pelanggan", http.StatusForbidden)
+		return
+	}
+
+	if err := h.service.SoftDelete(id); err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Pelanggan berhasil dinonaktifkan",
+	})
+}
+
+// GetTransactions handles GET /api/customers/{id}/transactions?page=1&limit=10
+// Riwayat belanja pelanggan + ringkasan total belanja
+func (h *CustomerHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/customers/"), "/transactions")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "ID pelanggan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	customer, err := h.service.GetByID(id)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	pagination := parseCustomerPagination(r)
+	transactions, totalCount, err := h.service.GetTransactions(id, &pagination)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"customer": customer,
+		"data":     transactions,
+		"pagination": models.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: totalCount,
+			TotalPages: models.CalculateTotalPages(totalCount, pagination.Limit),
+		},
+	})
+}
+
+// GetCredit handles GET /api/customers/{id}/credit
+// Saldo kasbon, limit, aging (0-30 / 31-60 / >60 hari) dan riwayat buku besar
+func (h *CustomerHandler) GetCredit(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/customers/"), "/credit")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "ID pelanggan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.creditService.GetSummary(id)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": summary,
+	})
+}
+
+// RepayCredit handles POST /api/customers/{id}/credit/payments
+// Body: {"amount": 50000, "method": "cash", "reference": "", "note": "Cicilan"}
+func (h *CustomerHandler) RepayCredit(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/customers/"), "/credit/payments")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "ID pelanggan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	var req models.CreditRepaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if user := middleware.GetUserFromContext(r.Context()); user != nil {
+		req.CreatedBy = user.ID
+	}
+
+	entry, err := h.creditService.Repay(id, &req)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	summary, err := h.creditService.GetSummary(id)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     "Pembayaran kasbon berhasil dicatat",
+		"data":        entry,
+		"outstanding": summary.Outstanding,
+	})
+}
+
+// GetPoints handles GET /api/customers/{id}/points
+// Saldo poin loyalitas, poin yang akan kedaluwarsa dan riwayat buku besar poin
+func (h *CustomerHandler) GetPoints(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/customers/"), "/points")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "ID pelanggan tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	summary, err := h.loyaltyService.GetSummary(id)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": summary,
+	})
+}
+
+// GetReceivables handles GET /api/report/receivables (Admin)
+// Daftar piutang pelanggan dengan aging 0-30 / 31-60 / >60 hari
+func (h *CustomerHandler) GetReceivables(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	report, err := h.creditService.GetReceivables()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": report,
+	})
+}
+
+// canSetCreditLimit = limit kasbon hanya boleh diisi / diubah oleh Admin
+func canSetCreditLimit(r *http.Request, req models.CustomerRequest) bool {
+	if req.CreditLimit == nil {
+		return true
+	}
+	user := middleware.GetUserFromContext(r.Context())
+	return user != nil && user.IsAdmin()
+}
+
+func parseCustomerPagination(r *http.Request) models.PaginationParams {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	return models.NewPaginationParams(page, limit)
+}
+
+func writeCustomerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrCustomerNameEmpty), errors.Is(err, models.ErrInvalidCustomerPhone),
+		errors.Is(err, models.ErrInvalidCreditLimit), errors.Is(err, models.ErrInvalidRepaymentAmount),
+		errors.Is(err, models.ErrInvalidPaymentMethod), errors.Is(err, models.ErrRepaymentExceedsBalance):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrCustomerPhoneExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case strings.Contains(err.Error(), "tidak ditemukan"):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -63,9 +63,9 @@ func (h *DiscountHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Simple validation
-	if d.Name == "" || d.Value <= 0 {
-		http.Error(w, "Name and Value are required", http.StatusBadRequest)
+	d.Normalize()
+	if err := d.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -107,6 +107,12 @@ func (h *DiscountHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	d.Normalize()
+	if err := d.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err := h.repo.Update(id, &d); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HeldCartHandler handles HTTP requests for held (parked) carts
+type HeldCartHandler struct {
+	service *services.HeldCartService
+}
+
+// NewHeldCartHandler creates a new HeldCartHandler
+func NewHeldCartHandler(service *services.HeldCartService) *HeldCartHandler {
+	return &HeldCartHandler{service: service}
+}
+
+// HandleCarts handles /api/carts (GET list, POST park cart)
+func (h *HeldCartHandler) HandleCarts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetAll(w, r)
+	case "POST":
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleCartByID handles /api/carts/{id} (GET, DELETE) dan /api/carts/{id}/checkout (POST)
+func (h *HeldCartHandler) HandleCartByID(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/checkout") {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Checkout(w, r)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		h.GetByID(w, r)
+	case "DELETE":
+		h.Delete(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Create handles POST /api/carts
+func (h *HeldCartHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.HeldCartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	cart, err := h.service.Create(&req, user.ID)
+	if err != nil {
+		writeCheckoutError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Keranjang berhasil disimpan",
+		"data":    cart,
+	})
+}
+
+// GetAll handles GET /api/carts
+func (h *HeldCartHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	carts, err := h.service.GetAll(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": carts,
+	})
+}
+
+// GetByID handles GET /api/carts/{id} (resume)
+func (h *HeldCartHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/carts/"))
+	if err != nil {
+		http.Error(w, "ID keranjang tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	cart, err := h.service.GetByID(id, user)
+	if err != nil {
+		writeHeldCartError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": cart,
+	})
+}
+
+// Delete handles DELETE /api/carts/{id}
+func (h *HeldCartHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/carts/"))
+	if err != nil {
+		http.Error(w, "ID keranjang tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(id, user); err != nil {
+		writeHeldCartError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Keranjang berhasil dihapus"})
+}
+
+// Checkout handles POST /api/carts/{id}/checkout
+// Body optional: {"payment_amount": ..., "payments": [...]}
+func (h *HeldCartHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/carts/"), "/checkout")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "ID keranjang tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	var req models.HeldCartCheckoutRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	transaction, err := h.service.Checkout(id, &req, user)
+	if err != nil {
+		if errors.Is(err, models.ErrForbidden) || strings.Contains(err.Error(), "keranjang dengan ID") {
+			writeHeldCartError(w, err)
+			return
+		}
+		writeCheckoutError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transaction)
+}
+
+func writeHeldCartError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case strings.Contains(err.Error(), "tidak ditemukan"):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
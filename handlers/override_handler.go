@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"log/slog"
+	"net/http"
+)
+
+// OverrideHandler handles supervisor approval for price / discount overrides
+type OverrideHandler struct {
+	service *services.OverrideService
+}
+
+// NewOverrideHandler creates a new OverrideHandler
+func NewOverrideHandler(service *services.OverrideService) *OverrideHandler {
+	return &OverrideHandler{service: service}
+}
+
+// Approve handles POST /api/overrides/approve
+// Dipanggil dari perangkat kasir: admin mengisi username + password/PIN dan alasan,
+// hasilnya approval_token untuk baris checkout yang di-override.
+func (h *OverrideHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cashier := middleware.GetUserFromContext(r.Context())
+	if cashier == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.OverrideApprovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Username == "" || req.Password == "" {
+		http.Error(w, "Username dan password admin wajib diisi", http.StatusBadRequest)
+		return
+	}
+
+	approval, err := h.service.Approve(cashier, &req)
+	if err != nil {
+		slog.Warn("Override approval failed", "cashier", cashier.Username, "approver", req.Username, "error", err)
+		switch {
+		case errors.Is(err, models.ErrOverrideReasonEmpty), errors.Is(err, models.ErrOverrideProductEmpty),
+			errors.Is(err, models.ErrOverrideNothingApproved), errors.Is(err, models.ErrInvalidOverridePrice),
+			errors.Is(err, models.ErrInvalidMaxDiscount):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, models.ErrInvalidCredentials):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case errors.Is(err, models.ErrApproverNotAdmin):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	slog.Info("Override approved", "cashier", cashier.Username, "approver", approval.ApproverName, "reason", approval.Reason)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Override disetujui",
+		"data":    approval,
+	})
+}
@@ -14,13 +14,14 @@ import (
 // ProductHandler handles HTTP requests for products
 // Handler adalah layer yang berhadapan langsung dengan HTTP request/response
 type ProductHandler struct {
-	service *services.ProductService // Pointer ke ProductService
+	service        *services.ProductService       // Pointer ke ProductService
+	stockMovements *services.StockMovementService // Ledger pergerakan stok
 }
 
 // NewProductHandler creates a new ProductHandler
 // Fungsi ini adalah "constructor" untuk membuat instance ProductHandler
-func NewProductHandler(service *services.ProductService) *ProductHandler {
-	return &ProductHandler{service: service} // Return struct dengan service yang sudah di-inject
+func NewProductHandler(service *services.ProductService, stockMovements *services.StockMovementService) *ProductHandler {
+	return &ProductHandler{service: service, stockMovements: stockMovements} // Return struct dengan service yang sudah di-inject
 }
 
 // HandleProducts handles /api/produk (GET all only)
@@ -37,9 +38,24 @@ func (h *ProductHandler) HandleProducts(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// HandleProductByID handles /api/produk/{id} or /api/produk/barcode/{code}
+// HandleProductByID handles /api/produk/{id}, /api/produk/barcode/{code},
+// /api/produk/{id}/stock-history dan /api/produk/stock-check
 // Fungsi ini handle: GET (by ID), PUT (update), DELETE (hapus), atau GET by barcode
 func (h *ProductHandler) HandleProductByID(w http.ResponseWriter, r *http.Request) {
+	// Riwayat & pengecekan ledger stok (Admin)
+	if strings.HasSuffix(r.URL.Path, "/stock-history") || r.URL.Path == "/api/produk/stock-check" {
+		if r.Method != "GET" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.URL.Path == "/api/produk/stock-check" {
+			h.StockCheck(w, r)
+		} else {
+			h.GetStockHistory(w, r)
+		}
+		return
+	}
+
 	// Cek apakah ini route barcode: /api/produk/barcode/{code}
 	if strings.HasPrefix(r.URL.Path, "/api/produk/barcode/") {
 		if r.Method == "GET" {
@@ -191,6 +207,68 @@ func (h *ProductHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(product)
 }
 
+// GetStockHistory retrieves the stock ledger of a product
+// Fungsi ini handle GET /api/produk/{id}/stock-history?page=1&limit=20 (Admin)
+func (h *ProductHandler) GetStockHistory(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Only Admin can view stock history", http.StatusForbidden)
+		return
+	}
+
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/produk/"), "/stock-history")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid Product ID", http.StatusBadRequest)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	pagination := models.NewPaginationParams(page, limit)
+
+	movements, totalCount, err := h.stockMovements.GetProductHistory(id, &pagination)
+	if err != nil {
+		log.Printf("❌ Handler: Error getting stock history product ID %d: %v", id, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.PaginatedResponse{
+		Data: movements,
+		Pagination: models.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: totalCount,
+			TotalPages: models.CalculateTotalPages(totalCount, pagination.Limit),
+		},
+	})
+}
+
+// StockCheck rebuilds stock from the ledger and reports mismatches
+// Fungsi ini handle GET /api/produk/stock-check?all=true (Admin)
+func (h *ProductHandler) StockCheck(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Only Admin can check stock ledger", http.StatusForbidden)
+		return
+	}
+
+	report, err := h.stockMovements.Verify(r.URL.Query().Get("all") == "true")
+	if err != nil {
+		log.Printf("❌ Handler: Error checking stock ledger: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if report.Mismatches > 0 {
+		log.Printf("⚠️ Ledger stok: %d dari %d produk tidak konsisten", report.Mismatches, report.CheckedProducts)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
 // GetByBarcode retrieves a product by barcode
 // Fungsi ini handle GET /api/produk/barcode/{code}
 func (h *ProductHandler) GetByBarcode(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"errors"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ReceiptHandler handles HTTP requests for transaction receipts
+type ReceiptHandler struct {
+	service *services.ReceiptService
+}
+
+// NewReceiptHandler creates a new ReceiptHandler
+func NewReceiptHandler(service *services.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{service: service}
+}
+
+// GetReceipt handles GET /api/transactions/{id}/receipt?format=escpos|text|html
+// Preview struk (default format: text) — tidak dihitung sebagai cetak, aman di-refresh / retry.
+func (h *ReceiptHandler) GetReceipt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.render(w, r, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/transactions/"), "/receipt"), false)
+}
+
+// PrintReceipt handles POST /api/transactions/{id}/receipt/print?format=escpos|text|html
+// Aksi cetak eksplisit: print_count bertambah, cetakan ke-2 dst ditandai CETAK ULANG.
+func (h *ReceiptHandler) PrintReceipt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.render(w, r, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/transactions/"), "/receipt/print"), true)
+}
+
+// render menulis struk transaksi idStr (print = true → dihitung sebagai cetak)
+func (h *ReceiptHandler) render(w http.ResponseWriter, r *http.Request, idStr string, print bool) {
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "ID transaksi tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = models.ReceiptFormatText
+	}
+
+	content, contentType, err := h.service.Render(id, format, print)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidReceiptFormat):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case strings.Contains(err.Error(), "tidak ditemukan"):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if format == models.ReceiptFormatESCPOS {
+		w.Header().Set("Content-Disposition", "attachment; filename=\"struk-"+idStr+".bin\"")
+	}
+	w.Write(content)
+}
@@ -335,3 +335,245 @@ func (h *ReportHandler) GetDashboardAssets(w http.ResponseWriter, r *http.Reques
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(report)
 }
+
+// GetTaxReport handles GET /api/report/tax?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&timezone=Asia/Jakarta
+// Rekap PPN per kelas pajak: DPP, PPN, PPN retur, dan total service charge
+func (h *ReportHandler) GetTaxReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDateStr := r.URL.Query().Get("start_date")
+	endDateStr := r.URL.Query().Get("end_date")
+	if startDateStr == "" || endDateStr == "" {
+		http.Error(w, "start_date dan end_date harus diisi (format: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	loc, _ := parseTimezone(r)
+
+	startDate, err := time.ParseInLocation("2006-01-02", startDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format start_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDateParsed, err := time.ParseInLocation("2006-01-02", endDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format end_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDate := time.Date(endDateParsed.Year(), endDateParsed.Month(), endDateParsed.Day(), 23, 59, 59, 999999999, loc)
+
+	report, err := h.service.GetTaxReport(startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetOverrideReport handles GET /api/report/overrides?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&user_id=&timezone=Asia/Jakarta
+// Daftar override harga / diskon yang disetujui supervisor, per kasir
+func (h *ReportHandler) GetOverrideReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDateStr := r.URL.Query().Get("start_date")
+	endDateStr := r.URL.Query().Get("end_date")
+	if startDateStr == "" || endDateStr == "" {
+		http.Error(w, "start_date dan end_date harus diisi (format: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	loc, _ := parseTimezone(r)
+
+	var userID *int
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		if id, err := strconv.Atoi(userIDStr); err == nil {
+			userID = &id
+		}
+	}
+
+	startDate, err := time.ParseInLocation("2006-01-02", startDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format start_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDateParsed, err := time.ParseInLocation("2006-01-02", endDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format end_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDate := time.Date(endDateParsed.Year(), endDateParsed.Month(), endDateParsed.Day(), 23, 59, 59, 999999999, loc)
+
+	report, err := h.service.GetOverrideReport(startDate, endDate, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetVoucherReport handles GET /api/report/vouchers?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&timezone=Asia/Jakarta
+// Rekap pemakaian voucher per kode: jumlah pemakaian, pelanggan, total potongan & sisa kuota
+func (h *ReportHandler) GetVoucherReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDateStr := r.URL.Query().Get("start_date")
+	endDateStr := r.URL.Query().Get("end_date")
+	if startDateStr == "" || endDateStr == "" {
+		http.Error(w, "start_date dan end_date harus diisi (format: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	loc, _ := parseTimezone(r)
+
+	startDate, err := time.ParseInLocation("2006-01-02", startDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format start_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDateParsed, err := time.ParseInLocation("2006-01-02", endDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format end_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDate := time.Date(endDateParsed.Year(), endDateParsed.Month(), endDateParsed.Day(), 23, 59, 59, 999999999, loc)
+
+	report, err := h.service.GetVoucherReport(startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetDiscountReport handles GET /api/report/discounts?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&timezone=Asia/Jakarta
+// Rekap per diskon / promosi: jumlah pemakaian, total potongan, penjualan & laba kotor baris terdampak,
+// dibandingkan dengan periode sama panjang sebelum promo dimulai
+func (h *ReportHandler) GetDiscountReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDateStr := r.URL.Query().Get("start_date")
+	endDateStr := r.URL.Query().Get("end_date")
+	if startDateStr == "" || endDateStr == "" {
+		http.Error(w, "start_date dan end_date harus diisi (format: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	loc, _ := parseTimezone(r)
+
+	startDate, err := time.ParseInLocation("2006-01-02", startDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format start_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDateParsed, err := time.ParseInLocation("2006-01-02", endDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format end_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDate := time.Date(endDateParsed.Year(), endDateParsed.Month(), endDateParsed.Day(), 23, 59, 59, 999999999, loc)
+
+	report, err := h.service.GetDiscountReport(startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetShrinkageReport handles GET /api/report/shrinkage?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&timezone=Asia/Jakarta
+// Rekap selisih stock opname yang diposting per produk: unit & nilai susut / lebih (harga beli)
+func (h *ReportHandler) GetShrinkageReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDateStr := r.URL.Query().Get("start_date")
+	endDateStr := r.URL.Query().Get("end_date")
+	if startDateStr == "" || endDateStr == "" {
+		http.Error(w, "start_date dan end_date harus diisi (format: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	loc, _ := parseTimezone(r)
+
+	startDate, err := time.ParseInLocation("2006-01-02", startDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format start_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDateParsed, err := time.ParseInLocation("2006-01-02", endDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format end_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDate := time.Date(endDateParsed.Year(), endDateParsed.Month(), endDateParsed.Day(), 23, 59, 59, 999999999, loc)
+
+	report, err := h.service.GetShrinkageReport(startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetWriteOffReport handles GET /api/report/write-offs?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&timezone=Asia/Jakarta
+// Rekap penyesuaian stok yang disetujui per reason & per produk, dinilai dengan harga beli
+func (h *ReportHandler) GetWriteOffReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	startDateStr := r.URL.Query().Get("start_date")
+	endDateStr := r.URL.Query().Get("end_date")
+	if startDateStr == "" || endDateStr == "" {
+		http.Error(w, "start_date dan end_date harus diisi (format: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	loc, _ := parseTimezone(r)
+
+	startDate, err := time.ParseInLocation("2006-01-02", startDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format start_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDateParsed, err := time.ParseInLocation("2006-01-02", endDateStr, loc)
+	if err != nil {
+		http.Error(w, "Format end_date tidak valid (gunakan: YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+	endDate := time.Date(endDateParsed.Year(), endDateParsed.Month(), endDateParsed.Day(), 23, 59, 59, 999999999, loc)
+
+	report, err := h.service.GetWriteOffReport(startDate, endDate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
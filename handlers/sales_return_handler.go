@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SalesReturnHandler handles HTTP requests for sales returns
+type SalesReturnHandler struct {
+	service *services.SalesReturnService
+}
+
+// NewSalesReturnHandler creates a new SalesReturnHandler
+func NewSalesReturnHandler(service *services.SalesReturnService) *SalesReturnHandler {
+	return &SalesReturnHandler{service: service}
+}
+
+// HandleReturns handles /api/transactions/{id}/returns
+// POST → buat retur baru, GET → riwayat retur transaksi
+func (h *SalesReturnHandler) HandleReturns(w http.ResponseWriter, r *http.Request) {
+	// Extract ID dari URL: /api/transactions/{id}/returns
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/transactions/"), "/returns")
+	transactionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "ID transaksi tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "POST":
+		h.Create(w, r, transactionID)
+	case "GET":
+		h.GetByTransactionID(w, r, transactionID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Create handles POST /api/transactions/{id}/returns
+func (h *SalesReturnHandler) Create(w http.ResponseWriter, r *http.Request, transactionID int) {
+	var req models.SalesReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	currentUser := middleware.GetUserFromContext(r.Context())
+	if currentUser != nil {
+		req.CreatedBy = currentUser.ID
+	}
+
+	result, err := h.service.Create(transactionID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrTransactionVoided):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, models.ErrReturnQuantityExceeded):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case strings.Contains(err.Error(), "tidak ditemukan"):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Retur berhasil diproses",
+		"data":    result,
+	})
+}
+
+// GetByTransactionID handles GET /api/transactions/{id}/returns
+func (h *SalesReturnHandler) GetByTransactionID(w http.ResponseWriter, r *http.Request, transactionID int) {
+	returns, err := h.service.GetByTransactionID(transactionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": returns,
+	})
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// StockAdjustmentHandler handles HTTP requests for manual stock adjustments
+type StockAdjustmentHandler struct {
+	service *services.StockAdjustmentService
+}
+
+// NewStockAdjustmentHandler creates a new StockAdjustmentHandler
+func NewStockAdjustmentHandler(service *services.StockAdjustmentService) *StockAdjustmentHandler {
+	return &StockAdjustmentHandler{service: service}
+}
+
+// HandleStockAdjustments handles /api/stock-adjustments (GET list, POST create)
+func (h *StockAdjustmentHandler) HandleStockAdjustments(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetAll(w, r)
+	case "POST":
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleStockAdjustmentByID handles /api/stock-adjustments/{id} (GET),
+// /api/stock-adjustments/{id}/approve (POST) dan /api/stock-adjustments/{id}/reject (POST)
+func (h *StockAdjustmentHandler) HandleStockAdjustmentByID(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/approve") {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Approve(w, r)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/reject") {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Reject(w, r)
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.GetByID(w, r)
+}
+
+// stockAdjustmentID mengambil ID dokumen dari /api/stock-adjustments/{id}[/suffix]
+func stockAdjustmentID(r *http.Request, suffix string) (int, error) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/stock-adjustments/"), suffix)
+	return strconv.Atoi(idStr)
+}
+
+// Create handles POST /api/stock-adjustments
+// Body: {"notes": "...", "items": [{"product_id": 1, "reason": "damaged", "quantity": 2, "note": "pecah"}]}
+func (h *StockAdjustmentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	adj, err := h.service.Create(&req, user)
+	if err != nil {
+		writeStockAdjustmentError(w, err)
+		return
+	}
+
+	message := "Penyesuaian stok berhasil diproses"
+	if adj.Status == models.StockAdjustmentStatusPending {
+		message = "Penyesuaian stok menunggu persetujuan admin"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": message,
+		"data":    adj,
+	})
+}
+
+// GetAll handles GET /api/stock-adjustments?status=pending&page=1&limit=10 (admin only)
+func (h *StockAdjustmentHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Only Admin can view stock adjustments", http.StatusForbidden)
+		return
+	}
+
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	pagination := models.NewPaginationParams(page, limit)
+
+	adjustments, totalCount, err := h.service.GetAll(r.URL.Query().Get("status"), &pagination)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.PaginatedResponse{
+		Data: adjustments,
+		Pagination: models.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: totalCount,
+			TotalPages: models.CalculateTotalPages(totalCount, pagination.Limit),
+		},
+	})
+}
+
+// GetByID handles GET /api/stock-adjustments/{id} (admin, atau kasir pembuat dokumen)
+func (h *StockAdjustmentHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := stockAdjustmentID(r, "")
+	if err != nil {
+		http.Error(w, "ID penyesuaian stok tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	adj, err := h.service.GetByID(id, user)
+	if err != nil {
+		writeStockAdjustmentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": adj,
+	})
+}
+
+// Approve handles POST /api/stock-adjustments/{id}/approve (admin only)
+func (h *StockAdjustmentHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Only Admin can approve stock adjustments", http.StatusForbidden)
+		return
+	}
+
+	id, err := stockAdjustmentID(r, "/approve")
+	if err != nil {
+		http.Error(w, "ID penyesuaian stok tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	adj, err := h.service.Approve(id, user.ID)
+	if err != nil {
+		writeStockAdjustmentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Penyesuaian stok disetujui",
+		"data":    adj,
+	})
+}
+
+// Reject handles POST /api/stock-adjustments/{id}/reject (admin only)
+// Body: {"reason": "jumlah tidak sesuai"}
+func (h *StockAdjustmentHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Only Admin can reject stock adjustments", http.StatusForbidden)
+		return
+	}
+
+	id, err := stockAdjustmentID(r, "/reject")
+	if err != nil {
+		http.Error(w, "ID penyesuaian stok tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	var req models.StockAdjustmentRejectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Reject(id, user.ID, &req); err != nil {
+		writeStockAdjustmentError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Penyesuaian stok ditolak"})
+}
+
+// writeStockAdjustmentError memetakan error penyesuaian stok ke HTTP status
+// Stok kurang dikembalikan sebagai 409 dengan detail produk (sama seperti checkout)
+func writeStockAdjustmentError(w http.ResponseWriter, err error) {
+	var stockErr *models.InsufficientStockError
+	switch {
+	case errors.As(err, &stockErr):
+		writeCheckoutError(w, err)
+	case errors.Is(err, models.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, models.ErrStockAdjustmentNotPending):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case strings.Contains(err.Error(), "tidak ditemukan"):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// StockTakeHandler handles HTTP requests for stock opname sessions
+type StockTakeHandler struct {
+	service *services.StockTakeService
+}
+
+// NewStockTakeHandler creates a new StockTakeHandler
+func NewStockTakeHandler(service *services.StockTakeService) *StockTakeHandler {
+	return &StockTakeHandler{service: service}
+}
+
+// HandleStockTakes handles /api/stock-takes (GET list, POST buka sesi)
+func (h *StockTakeHandler) HandleStockTakes(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetAll(w, r)
+	case "POST":
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleStockTakeByID handles /api/stock-takes/{id} (GET, DELETE),
+// /api/stock-takes/{id}/counts (POST) dan /api/stock-takes/{id}/post (POST)
+func (h *StockTakeHandler) HandleStockTakeByID(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/counts") {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.RecordCounts(w, r)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/post") {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Post(w, r)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		h.GetByID(w, r)
+	case "DELETE":
+		h.Cancel(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// stockTakeID mengambil ID sesi dari /api/stock-takes/{id}[/suffix]
+func stockTakeID(r *http.Request, suffix string) (int, error) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/stock-takes/"), suffix)
+	return strconv.Atoi(idStr)
+}
+
+// Create handles POST /api/stock-takes (admin only)
+// Body: {"name": "Opname Akhir Bulan", "scope": "all"} atau {"scope": "category", "category_id": 3}
+func (h *StockTakeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Only Admin can open a stock take", http.StatusForbidden)
+		return
+	}
+
+	var req models.StockTakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	stockTake, err := h.service.Create(&req, user.ID)
+	if err != nil {
+		writeStockTakeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Stock opname berhasil dibuka",
+		"data":    stockTake,
+	})
+}
+
+// GetAll handles GET /api/stock-takes?status=open
+// Bisa diakses kasir (untuk memilih sesi yang sedang dihitung)
+func (h *StockTakeHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	stockTakes, err := h.service.GetAll(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": stockTakes,
+	})
+}
+
+// GetByID handles GET /api/stock-takes/{id}?uncounted=true (admin only)
+// Menampilkan hitungan, selisih terhadap stok sistem dan nilainya (harga beli)
+func (h *StockTakeHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Only Admin can view stock take variances", http.StatusForbidden)
+		return
+	}
+
+	id, err := stockTakeID(r, "")
+	if err != nil {
+		http.Error(w, "ID stock opname tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	stockTake, err := h.service.GetByID(id, r.URL.Query().Get("uncounted") == "true")
+	if err != nil {
+		writeStockTakeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": stockTake,
+	})
+}
+
+// RecordCounts handles POST /api/stock-takes/{id}/counts
+// Body: {"mode": "add", "items": [{"barcode": "899...", "quantity": 12}, {"product_id": 5, "quantity": 3}]}
+// Bisa dipanggil berkali-kali oleh kasir / staf gudang (per rak / batch scan)
+func (h *StockTakeHandler) RecordCounts(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := stockTakeID(r, "/counts")
+	if err != nil {
+		http.Error(w, "ID stock opname tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	var req models.StockCountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	items, err := h.service.RecordCounts(id, &req, user.ID)
+	if err != nil {
+		writeStockTakeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Hitungan berhasil dicatat",
+		"data":    items,
+	})
+}
+
+// Post handles POST /api/stock-takes/{id}/post (admin only)
+// Body optional: {"uncounted_as_zero": true}
+func (h *StockTakeHandler) Post(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Only Admin can post a stock take", http.StatusForbidden)
+		return
+	}
+
+	id, err := stockTakeID(r, "/post")
+	if err != nil {
+		http.Error(w, "ID stock opname tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	var req models.StockTakePostRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	stockTake, err := h.service.Post(id, &req, user.ID)
+	if err != nil {
+		writeStockTakeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Stock opname berhasil diposting",
+		"data":    stockTake,
+	})
+}
+
+// Cancel handles DELETE /api/stock-takes/{id} (admin only)
+func (h *StockTakeHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Only Admin can cancel a stock take", http.StatusForbidden)
+		return
+	}
+
+	id, err := stockTakeID(r, "")
+	if err != nil {
+		http.Error(w, "ID stock opname tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Cancel(id); err != nil {
+		writeStockTakeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Stock opname berhasil dibatalkan"})
+}
+
+// writeStockTakeError memetakan error stock opname ke HTTP status
+func writeStockTakeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrStockTakeOverlap), errors.Is(err, models.ErrStockTakeNotOpen),
+		errors.Is(err, models.ErrStockTakeCountOutdated):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case strings.Contains(err.Error(), "tidak ditemukan"):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// TaxClassHandler handles HTTP requests for tax classes (Admin)
+type TaxClassHandler struct {
+	repo *repositories.TaxClassRepository
+}
+
+// NewTaxClassHandler creates a new TaxClassHandler
+func NewTaxClassHandler(repo *repositories.TaxClassRepository) *TaxClassHandler {
+	return &TaxClassHandler{repo: repo}
+}
+
+// HandleTaxClasses handles /api/tax-classes (GET list, POST create)
+func (h *TaxClassHandler) HandleTaxClasses(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		classes, err := h.repo.GetAll()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": classes,
+		})
+	case "POST":
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleTaxClassByID handles /api/tax-classes/{id} (GET, PUT, DELETE)
+func (h *TaxClassHandler) HandleTaxClassByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/tax-classes/"))
+	if err != nil {
+		http.Error(w, "ID kelas pajak tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		taxClass, err := h.repo.GetByID(id)
+		if err != nil {
+			writeTaxClassError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": taxClass,
+		})
+	case "PUT":
+		h.Update(w, r, id)
+	case "DELETE":
+		if err := h.repo.Delete(id); err != nil {
+			writeTaxClassError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Kelas pajak berhasil dihapus",
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Create handles POST /api/tax-classes
+func (h *TaxClassHandler) Create(w http.ResponseWriter, r *http.Request) {
+	// Default aktif jika is_active tidak dikirim
+	taxClass := models.TaxClass{IsActive: true}
+	if err := json.NewDecoder(r.Body).Decode(&taxClass); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	taxClass.Name = strings.TrimSpace(taxClass.Name)
+	if err := taxClass.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.Create(&taxClass); err != nil {
+		writeTaxClassError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Kelas pajak berhasil dibuat",
+		"data":    taxClass,
+	})
+}
+
+// Update handles PUT /api/tax-classes/{id}
+func (h *TaxClassHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var taxClass models.TaxClass
+	if err := json.NewDecoder(r.Body).Decode(&taxClass); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	taxClass.Name = strings.TrimSpace(taxClass.Name)
+	if err := taxClass.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.Update(id, &taxClass); err != nil {
+		writeTaxClassError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Kelas pajak berhasil diupdate",
+		"data":    taxClass,
+	})
+}
+
+func writeTaxClassError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrTaxClassNameEmpty), errors.Is(err, models.ErrInvalidTaxRate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case strings.Contains(err.Error(), "tidak ditemukan"):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case strings.Contains(err.Error(), "duplicate key"):
+		http.Error(w, "nama kelas pajak sudah dipakai", http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
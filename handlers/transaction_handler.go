@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"kasir-api/middleware"
 	"kasir-api/models"
 	"kasir-api/services"
@@ -37,14 +38,16 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 1. Get current user
+	// Admin boleh mengirim diskon manual yang berbeda dengan pricing engine
 	currentUser := middleware.GetUserFromContext(r.Context())
 	if currentUser != nil {
 		req.CreatedBy = currentUser.ID
+		req.DiscountOverride = currentUser.IsAdmin()
 	}
 
 	transaction, err := h.service.Checkout(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeCheckoutError(w, err)
 		return
 	}
 
@@ -53,6 +56,92 @@ func (h *TransactionHandler) Checkout(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(transaction)
 }
 
+// Preview handles POST /api/checkout/preview
+// Body sama dengan POST /api/checkout; mengembalikan harga per baris & total dari pricing engine
+func (h *TransactionHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if currentUser := middleware.GetUserFromContext(r.Context()); currentUser != nil {
+		req.CreatedBy = currentUser.ID
+		req.DiscountOverride = currentUser.IsAdmin()
+	}
+
+	preview, err := h.service.Preview(&req)
+	if err != nil {
+		writeCheckoutError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": preview,
+	})
+}
+
+// writeCheckoutError menulis response error checkout
+// Stok kurang → 409 dengan detail produk agar frontend bisa menandai item yang bermasalah
+// Diskon tidak sesuai → 409 dengan nilai yang benar agar frontend bisa refresh harga
+func writeCheckoutError(w http.ResponseWriter, err error) {
+	var stockErr *models.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":        stockErr.Error(),
+			"product_id":   stockErr.ProductID,
+			"product_name": stockErr.ProductName,
+			"available":    stockErr.Available,
+			"requested":    stockErr.Requested,
+		})
+		return
+	}
+	var discountErr *models.DiscountMismatchError
+	if errors.As(err, &discountErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":         discountErr.Error(),
+			"product_id":    discountErr.ProductID,
+			"client_amount": discountErr.ClientAmount,
+			"server_amount": discountErr.ServerAmount,
+		})
+		return
+	}
+	var creditErr *models.CreditLimitExceededError
+	if errors.As(err, &creditErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":        creditErr.Error(),
+			"customer_id":  creditErr.CustomerID,
+			"credit_limit": creditErr.CreditLimit,
+			"outstanding":  creditErr.Outstanding,
+			"requested":    creditErr.Requested,
+		})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidApprovalToken) || errors.Is(err, models.ErrApprovalTokenMismatch) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, models.ErrInsufficientStock) || errors.Is(err, models.ErrInsufficientPoints) ||
+		errors.Is(err, models.ErrVoucherExhausted) || errors.Is(err, models.ErrVoucherCustomerLimit) ||
+		errors.Is(err, models.ErrApprovalTokenUsed) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
+
 // HandleTransactions handles GET /api/transactions?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&timezone=Asia/Makassar
 // Mendukung filter tanggal opsional. Tanpa filter → semua transaksi.
 func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.Request) {
@@ -118,7 +207,17 @@ func (h *TransactionHandler) HandleTransactions(w http.ResponseWriter, r *http.R
 }
 
 // HandleTransactionByID handles /api/transactions/{id} (GET by ID)
+// dan /api/transactions/{id}/void (POST, Admin Only)
 func (h *TransactionHandler) HandleTransactionByID(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/void") {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Void(w, r)
+		return
+	}
+
 	if r.Method != "GET" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -152,3 +251,48 @@ func (h *TransactionHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		"data": result,
 	})
 }
+
+// Void handles POST /api/transactions/{id}/void
+// Membatalkan transaksi (full refund) dan mengembalikan stok. Hanya Admin.
+func (h *TransactionHandler) Void(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !user.IsAdmin() {
+		http.Error(w, "Forbidden: Hanya Admin yang bisa membatalkan transaksi", http.StatusForbidden)
+		return
+	}
+
+	// Extract ID dari URL: /api/transactions/{id}/void
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/transactions/"), "/void")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "ID transaksi tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	var req models.VoidTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.service.Void(id, req.Reason, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrVoidReasonEmpty):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, models.ErrTransactionVoided), errors.Is(err, models.ErrTransactionHasReturns):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case strings.Contains(err.Error(), "tidak ditemukan"):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Transaksi berhasil dibatalkan",
+		"data":    transaction,
+	})
+}
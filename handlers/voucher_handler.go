@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"kasir-api/middleware"
+	"kasir-api/models"
+	"kasir-api/services"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// VoucherHandler handles HTTP requests for vouchers (Admin)
+type VoucherHandler struct {
+	service *services.VoucherService
+}
+
+// NewVoucherHandler creates a new VoucherHandler
+func NewVoucherHandler(service *services.VoucherService) *VoucherHandler {
+	return &VoucherHandler{service: service}
+}
+
+// HandleVouchers handles /api/vouchers (GET list, POST create)
+func (h *VoucherHandler) HandleVouchers(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.GetAll(w, r)
+	case "POST":
+		h.Create(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// HandleVoucherByID handles /api/vouchers/{id} (GET, PUT, DELETE) dan POST /api/vouchers/generate
+func (h *VoucherHandler) HandleVoucherByID(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/vouchers/")
+	if path == "generate" {
+		if r.Method != "POST" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.Generate(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(path)
+	if err != nil {
+		http.Error(w, "ID voucher tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		voucher, err := h.service.GetByID(id)
+		if err != nil {
+			writeVoucherError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": voucher,
+		})
+	case "PUT":
+		h.Update(w, r, id)
+	case "DELETE":
+		if err := h.service.Deactivate(id); err != nil {
+			writeVoucherError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"message": "Voucher berhasil dinonaktifkan",
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// GetAll handles GET /api/vouchers?search=...&page=1&limit=10
+func (h *VoucherHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	pagination := models.NewPaginationParams(page, limit)
+
+	vouchers, totalCount, err := h.service.GetAll(r.URL.Query().Get("search"), &pagination)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.PaginatedResponse{
+		Data: vouchers,
+		Pagination: models.PaginationMeta{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalItems: totalCount,
+			TotalPages: models.CalculateTotalPages(totalCount, pagination.Limit),
+		},
+	})
+}
+
+// Create handles POST /api/vouchers
+func (h *VoucherHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req models.VoucherRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	voucher, err := h.service.Create(req, voucherUserID(r))
+	if err != nil {
+		writeVoucherError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Voucher berhasil dibuat",
+		"data":    voucher,
+	})
+}
+
+// Generate handles POST /api/vouchers/generate
+// Body = aturan voucher + {"prefix": "LEBARAN", "count": 100}
+func (h *VoucherHandler) Generate(w http.ResponseWriter, r *http.Request) {
+	var req models.VoucherGenerateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	vouchers, err := h.service.Generate(req, voucherUserID(r))
+	if err != nil {
+		writeVoucherError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Voucher berhasil dibuat",
+		"count":   len(vouchers),
+		"data":    vouchers,
+	})
+}
+
+// Update handles PUT /api/vouchers/{id}
+func (h *VoucherHandler) Update(w http.ResponseWriter, r *http.Request, id int) {
+	var req models.VoucherRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	voucher, err := h.service.Update(id, req)
+	if err != nil {
+		writeVoucherError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Voucher berhasil diupdate",
+		"data":    voucher,
+	})
+}
+
+func voucherUserID(r *http.Request) int {
+	if user := middleware.GetUserFromContext(r.Context()); user != nil {
+		return user.ID
+	}
+	return 0
+}
+
+func writeVoucherError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrVoucherCodeExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case strings.Contains(err.Error(), "tidak ditemukan"):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrInvalidVoucherCode), errors.Is(err, models.ErrVoucherNameEmpty),
+		errors.Is(err, models.ErrInvalidVoucherType), errors.Is(err, models.ErrInvalidVoucherValue),
+		errors.Is(err, models.ErrInvalidVoucherPeriod), errors.Is(err, models.ErrInvalidVoucherLimit),
+		errors.Is(err, models.ErrInvalidVoucherCount):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
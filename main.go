@@ -7,6 +7,7 @@ import (
 	"kasir-api/database"     // Import package database untuk koneksi DB
 	"kasir-api/handlers"     // Import package handlers untuk HTTP handlers
 	"kasir-api/middleware"   // Import package middleware untuk auth, logging, CORS
+	"kasir-api/models"       // Import package models untuk setting struk
 	"kasir-api/repositories" // Import package repositories untuk database operations
 	"kasir-api/services"     // Import package services untuk business logic
 	"log"                    // Package untuk logging
@@ -64,31 +65,108 @@ func main() {
 	userHandler := handlers.NewUserHandler(userService) // Inject service ke handler
 
 	// Product layers
-	productRepo := repositories.NewProductRepository(db)                    // Inject db ke repository
+	productRepo := repositories.NewProductRepository(db, cfg.CostingMethod) // Inject db ke repository
 	productService := services.NewProductService(productRepo, cacheService) // Inject repo dan cache ke service
-	productHandler := handlers.NewProductHandler(productService)            // Inject service ke handler
+	stockMovementRepo := repositories.NewStockMovementRepository(db)        // Ledger pergerakan stok
+	stockMovementService := services.NewStockMovementService(stockMovementRepo)
+	productHandler := handlers.NewProductHandler(productService, stockMovementService) // Inject service ke handler
 
 	// Category layers
 	categoryRepo := repositories.NewCategoryRepository(db)                     // Inject db ke repository
 	categoryService := services.NewCategoryService(categoryRepo, cacheService) // Inject repo dan cache ke service
 	categoryHandler := handlers.NewCategoryHandler(categoryService)            // Inject service ke handler
 
+	// Timezone toko untuk jadwal diskon (hari / jam)
+	storeLoc := cfg.StoreLocation()
+
 	// Transaction layers
-	transactionRepo := repositories.NewTransactionRepository(db)             // Inject db ke repository
+	loyaltySettings := models.LoyaltySettings{
+		EarnAmount: cfg.LoyaltyEarnAmount,
+		PointValue: cfg.LoyaltyPointValue,
+		ExpiryDays: cfg.LoyaltyPointExpiryDays,
+	}
+	transactionRepo := repositories.NewTransactionRepository(db, models.ServiceChargeSettings{ // Inject db, setting service charge, pembulatan tunai & poin loyalitas ke repository
+		Percent: cfg.ServiceChargePercent,
+		Taxable: cfg.ServiceChargeTaxable,
+	}, models.CashRoundingSettings{
+		Unit: cfg.CashRoundingUnit,
+		Mode: cfg.CashRoundingMode,
+	}, loyaltySettings, storeLoc, cfg.CostingMethod)
 	transactionService := services.NewTransactionService(transactionRepo)    // Inject repo ke service
 	transactionHandler := handlers.NewTransactionHandler(transactionService) // Inject service ke handler
 
+	// Receipt layers (struk ESC/POS, text, HTML)
+	receiptService := services.NewReceiptService(transactionRepo, models.ReceiptSettings{
+		StoreName:    cfg.StoreName,
+		StoreAddress: cfg.StoreAddress,
+		Footer:       cfg.StoreFooter,
+		PaperWidth:   cfg.ReceiptPaperWidth,
+	})
+	receiptHandler := handlers.NewReceiptHandler(receiptService)
+
+	// Sales Return layers
+	salesReturnRepo := repositories.NewSalesReturnRepository(db, cfg.CostingMethod)
+	salesReturnService := services.NewSalesReturnService(salesReturnRepo)
+	salesReturnHandler := handlers.NewSalesReturnHandler(salesReturnService)
+
+	// Stock take layers (stock opname / hitung fisik stok)
+	stockTakeRepo := repositories.NewStockTakeRepository(db, cfg.CostingMethod)
+	stockTakeService := services.NewStockTakeService(stockTakeRepo)
+	stockTakeHandler := handlers.NewStockTakeHandler(stockTakeService)
+
+	// Stock adjustment layers (penyesuaian stok manual dengan reason code)
+	stockAdjustmentRepo := repositories.NewStockAdjustmentRepository(db, cfg.CostingMethod)
+	stockAdjustmentService := services.NewStockAdjustmentService(stockAdjustmentRepo, cfg.StockAdjustmentApprovalLimit)
+	stockAdjustmentHandler := handlers.NewStockAdjustmentHandler(stockAdjustmentService)
+
+	// Held Cart layers (keranjang parkir, checkout lewat TransactionService)
+	heldCartRepo := repositories.NewHeldCartRepository(db)
+	heldCartService := services.NewHeldCartService(heldCartRepo, transactionService, cfg.HeldCartReservationMinutes)
+	heldCartHandler := handlers.NewHeldCartHandler(heldCartService)
+
+	// Shift layers (sesi laci kasir)
+	shiftRepo := repositories.NewShiftRepository(db)
+	shiftService := services.NewShiftService(shiftRepo)
+	shiftHandler := handlers.NewShiftHandler(shiftService)
+
+	// Cash movement layers (pay in / pay out / setor brankas dari laci kasir)
+	cashMovementRepo := repositories.NewCashMovementRepository(db)
+	cashMovementService := services.NewCashMovementService(cashMovementRepo)
+	cashMovementHandler := handlers.NewCashMovementHandler(cashMovementService)
+
+	// Override layers (persetujuan supervisor untuk harga khusus / diskon manual)
+	overrideService := services.NewOverrideService(userRepo, cfg.OverrideTokenMinutes)
+	overrideHandler := handlers.NewOverrideHandler(overrideService)
+
 	// Report layers
 	reportRepo := repositories.NewReportRepository(db)        // Inject db ke repository
 	reportService := services.NewReportService(reportRepo)    // Inject repo ke service
 	reportHandler := handlers.NewReportHandler(reportService) // Inject service ke handler
 
 	// Discount layers
-	discountRepo := repositories.NewDiscountRepository(db)
+	discountRepo := repositories.NewDiscountRepository(db, storeLoc)
 	discountHandler := handlers.NewDiscountHandler(discountRepo)
 
+	// Voucher layers (Admin Only, dipakai kasir lewat voucher_code saat checkout)
+	voucherRepo := repositories.NewVoucherRepository(db)
+	voucherService := services.NewVoucherService(voucherRepo)
+	voucherHandler := handlers.NewVoucherHandler(voucherService)
+
+	// Tax class layers (Admin Only)
+	taxClassRepo := repositories.NewTaxClassRepository(db)
+	taxClassHandler := handlers.NewTaxClassHandler(taxClassRepo)
+
+	// Customer layers
+	customerRepo := repositories.NewCustomerRepository(db)
+	customerService := services.NewCustomerService(customerRepo)
+	customerCreditRepo := repositories.NewCustomerCreditRepository(db)
+	customerCreditService := services.NewCustomerCreditService(customerCreditRepo)
+	loyaltyRepo := repositories.NewLoyaltyRepository(db, loyaltySettings)
+	loyaltyService := services.NewLoyaltyService(loyaltyRepo)
+	customerHandler := handlers.NewCustomerHandler(customerService, customerCreditService, loyaltyService)
+
 	// Purchase layers (Admin Only)
-	purchaseRepo := repositories.NewPurchaseRepository(db)
+	purchaseRepo := repositories.NewPurchaseRepository(db, cfg.CostingMethod)
 	purchaseService := services.NewPurchaseService(purchaseRepo, cacheService)
 	purchaseHandler := handlers.NewPurchaseHandler(purchaseService)
 
@@ -112,6 +190,9 @@ func main() {
 	cashFlowService := services.NewCashFlowService(cashFlowRepo)
 	cashFlowHandler := handlers.NewCashFlowHandler(cashFlowService)
 
+	// Idempotency layers (Idempotency-Key untuk checkout & pembelian)
+	idempotencyRepo := repositories.NewIdempotencyRepository(db)
+
 	// ==================== SETUP ROUTER WITH MIDDLEWARE ====================
 	// Create a new ServeMux for better routing
 	mux := http.NewServeMux()
@@ -196,7 +277,7 @@ func main() {
 	// Purchase routes (Admin Only)
 	// /api/purchases -> GET (list), POST (create)
 	mux.Handle("/api/purchases/", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(purchaseHandler.HandlePurchaseByID))))
-	mux.Handle("/api/purchases", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(purchaseHandler.HandlePurchases))))
+	mux.Handle("/api/purchases", middleware.AuthMiddleware(middleware.RequireAdmin(middleware.Idempotency(idempotencyRepo, "purchase")(http.HandlerFunc(purchaseHandler.HandlePurchases)))))
 
 	// Dashboard routes
 	// /api/dashboard/sales-trend -> GET (Admin Only) ?period=day|month|year&start_date=YYYY-MM-DD&end_date=YYYY-MM-DD
@@ -242,6 +323,24 @@ func main() {
 		}
 	}))))
 
+	// Voucher routes (Admin Only)
+	// /api/vouchers  -> GET, POST
+	// /api/vouchers/ -> GET, PUT, DELETE /{id}, POST /generate
+	mux.Handle("/api/vouchers", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(voucherHandler.HandleVouchers))))
+	mux.Handle("/api/vouchers/", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(voucherHandler.HandleVoucherByID))))
+
+	// Tax class routes (Admin Only)
+	// /api/tax-classes  -> GET, POST
+	// /api/tax-classes/ -> GET, PUT, DELETE /{id}
+	mux.Handle("/api/tax-classes", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(taxClassHandler.HandleTaxClasses))))
+	mux.Handle("/api/tax-classes/", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(taxClassHandler.HandleTaxClassByID))))
+
+	// Customer routes (kasir bisa daftar & cari pelanggan; DELETE Admin only di handler)
+	// /api/customers  -> GET (search / ?phone=), POST
+	// /api/customers/ -> GET, PUT, DELETE /{id}, GET /{id}/transactions, GET /{id}/credit, POST /{id}/credit/payments, GET /{id}/points
+	mux.Handle("/api/customers", middleware.AuthMiddleware(http.HandlerFunc(customerHandler.HandleCustomers)))
+	mux.Handle("/api/customers/", middleware.AuthMiddleware(http.HandlerFunc(customerHandler.HandleCustomerByID)))
+
 	// ==================== PUBLIC ROUTES (No Auth Required) ====================
 
 	// Health check endpoint
@@ -295,13 +394,60 @@ func main() {
 	mux.Handle("/api/categories", middleware.AuthMiddleware(http.HandlerFunc(categoryHandler.HandleCategories)))
 
 	// Transaction routes
-	mux.Handle("/api/checkout", middleware.AuthMiddleware(http.HandlerFunc(transactionHandler.Checkout)))
-	mux.Handle("/api/transactions/", middleware.AuthMiddleware(http.HandlerFunc(transactionHandler.HandleTransactionByID))) // GET by ID
-	mux.Handle("/api/transactions", middleware.AuthMiddleware(http.HandlerFunc(transactionHandler.HandleTransactions)))     // GET all
+	mux.Handle("/api/checkout", middleware.AuthMiddleware(middleware.Idempotency(idempotencyRepo, "checkout")(http.HandlerFunc(transactionHandler.Checkout)))) // Support header Idempotency-Key
+	mux.Handle("/api/overrides/approve", middleware.AuthMiddleware(http.HandlerFunc(overrideHandler.Approve)))                                                 // Admin setujui override dari perangkat kasir
+	mux.Handle("/api/checkout/preview", middleware.AuthMiddleware(http.HandlerFunc(transactionHandler.Preview)))                                               // Hitung harga tanpa menyimpan
+	// /api/transactions/ -> GET by ID, POST /void, GET/POST /returns, GET /receipt, POST /receipt/print
+	mux.Handle("/api/transactions/", middleware.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/returns") {
+			salesReturnHandler.HandleReturns(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/receipt/print") {
+			receiptHandler.PrintReceipt(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/receipt") {
+			receiptHandler.GetReceipt(w, r)
+			return
+		}
+		transactionHandler.HandleTransactionByID(w, r)
+	})))
+	mux.Handle("/api/transactions", middleware.AuthMiddleware(http.HandlerFunc(transactionHandler.HandleTransactions))) // GET all
+
+	// Held Cart routes
+	// /api/carts/ -> GET (resume), DELETE, POST /checkout
+	mux.Handle("/api/carts/", middleware.AuthMiddleware(middleware.Idempotency(idempotencyRepo, "cart_checkout")(http.HandlerFunc(heldCartHandler.HandleCartByID))))
+	mux.Handle("/api/carts", middleware.AuthMiddleware(http.HandlerFunc(heldCartHandler.HandleCarts))) // GET list, POST park
+
+	// Shift routes
+	// /api/shifts/ -> POST /open, POST /close, GET /current, GET /{id}/report
+	mux.Handle("/api/shifts/", middleware.AuthMiddleware(http.HandlerFunc(shiftHandler.HandleShiftRoutes)))
+	mux.Handle("/api/shifts", middleware.AuthMiddleware(http.HandlerFunc(shiftHandler.HandleShifts))) // GET riwayat
+
+	// Stock take routes
+	// /api/stock-takes/ -> GET (Admin), DELETE cancel (Admin), POST /counts, POST /post (Admin)
+	mux.Handle("/api/stock-takes/", middleware.AuthMiddleware(http.HandlerFunc(stockTakeHandler.HandleStockTakeByID)))
+	mux.Handle("/api/stock-takes", middleware.AuthMiddleware(http.HandlerFunc(stockTakeHandler.HandleStockTakes))) // GET list, POST buka sesi (Admin)
+
+	// Stock adjustment routes
+	// /api/stock-adjustments/ -> GET, POST /approve (Admin), POST /reject (Admin)
+	mux.Handle("/api/stock-adjustments/", middleware.AuthMiddleware(http.HandlerFunc(stockAdjustmentHandler.HandleStockAdjustmentByID)))
+	mux.Handle("/api/stock-adjustments", middleware.AuthMiddleware(http.HandlerFunc(stockAdjustmentHandler.HandleStockAdjustments))) // GET list (Admin), POST create
+
+	// Cash movement routes
+	mux.Handle("/api/cash-movements", middleware.AuthMiddleware(http.HandlerFunc(cashMovementHandler.HandleCashMovements)))
 
 	// Report routes
 	mux.Handle("/api/report/hari-ini", middleware.AuthMiddleware(http.HandlerFunc(reportHandler.GetDailySalesReport)))
 	mux.Handle("/api/report", middleware.AuthMiddleware(http.HandlerFunc(reportHandler.GetSalesReportByDateRange)))
+	mux.Handle("/api/report/tax", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(reportHandler.GetTaxReport))))
+	mux.Handle("/api/report/overrides", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(reportHandler.GetOverrideReport))))
+	mux.Handle("/api/report/receivables", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(customerHandler.GetReceivables))))
+	mux.Handle("/api/report/vouchers", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(reportHandler.GetVoucherReport))))
+	mux.Handle("/api/report/discounts", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(reportHandler.GetDiscountReport))))
+	mux.Handle("/api/report/write-offs", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(reportHandler.GetWriteOffReport))))
+	mux.Handle("/api/report/shrinkage", middleware.AuthMiddleware(middleware.RequireAdmin(http.HandlerFunc(reportHandler.GetShrinkageReport))))
 
 	// ==================== APPLY GLOBAL MIDDLEWARE ====================
 	// Middleware chain: CORS -> Logging -> Handler
@@ -350,9 +496,26 @@ func main() {
 	fmt.Println("  - POST   /api/produk")
 	fmt.Println("  - GET    /api/produk/{id}")
 	fmt.Println("  - GET    /api/produk/barcode/{code}")
+	fmt.Println("  - GET    /api/produk/{id}/stock-history (Admin)")
+	fmt.Println("  - GET    /api/produk/stock-check?all=true (Admin)")
 	fmt.Println("  - PUT    /api/produk/{id}")
 	fmt.Println("  - DELETE /api/produk/{id}")
 	fmt.Println("")
+	fmt.Println("📚 Stock Take (Stock Opname) Endpoints:")
+	fmt.Println("  - POST   /api/stock-takes (Admin)")
+	fmt.Println("  - GET    /api/stock-takes?status=open|posted|cancelled")
+	fmt.Println("  - GET    /api/stock-takes/{id}?uncounted=true (Admin)")
+	fmt.Println("  - POST   /api/stock-takes/{id}/counts")
+	fmt.Println("  - POST   /api/stock-takes/{id}/post (Admin)")
+	fmt.Println("  - DELETE /api/stock-takes/{id} (Admin)")
+	fmt.Println("")
+	fmt.Println("📚 Stock Adjustment Endpoints:")
+	fmt.Println("  - POST   /api/stock-adjustments")
+	fmt.Println("  - GET    /api/stock-adjustments?status=pending|approved|rejected (Admin)")
+	fmt.Println("  - GET    /api/stock-adjustments/{id}")
+	fmt.Println("  - POST   /api/stock-adjustments/{id}/approve (Admin)")
+	fmt.Println("  - POST   /api/stock-adjustments/{id}/reject (Admin)")
+	fmt.Println("")
 	fmt.Println("📚 Category Endpoints:")
 	fmt.Println("  - GET    /api/categories")
 	fmt.Println("  - POST   /api/categories")
@@ -362,16 +525,73 @@ func main() {
 	fmt.Println("")
 	fmt.Println("📚 Transaction Endpoints:")
 	fmt.Println("  - POST   /api/checkout")
+	fmt.Println("  - POST   /api/checkout/preview")
+	fmt.Println("  - POST   /api/overrides/approve")
 	fmt.Println("  - GET    /api/transactions")
+	fmt.Println("  - POST   /api/transactions/{id}/void (Admin Only)")
+	fmt.Println("  - POST   /api/transactions/{id}/returns")
+	fmt.Println("  - GET    /api/transactions/{id}/returns")
+	fmt.Println("  - GET    /api/transactions/{id}/receipt?format=escpos|text|html")
+	fmt.Println("  - POST   /api/transactions/{id}/receipt/print?format=escpos|text|html")
+	fmt.Println("")
+	fmt.Println("📚 Held Cart Endpoints:")
+	fmt.Println("  - POST   /api/carts")
+	fmt.Println("  - GET    /api/carts")
+	fmt.Println("  - GET    /api/carts/{id}")
+	fmt.Println("  - DELETE /api/carts/{id}")
+	fmt.Println("  - POST   /api/carts/{id}/checkout")
+	fmt.Println("")
+	fmt.Println("📚 Shift Endpoints:")
+	fmt.Println("  - POST   /api/shifts/open")
+	fmt.Println("  - POST   /api/shifts/close")
+	fmt.Println("  - GET    /api/shifts/current")
+	fmt.Println("  - GET    /api/shifts")
+	fmt.Println("  - GET    /api/shifts/{id}/report")
+	fmt.Println("  - POST   /api/cash-movements")
+	fmt.Println("  - GET    /api/cash-movements")
+	fmt.Println("")
+	fmt.Println("📚 Customer Endpoints:")
+	fmt.Println("  - GET    /api/customers?search=&sort=name|spend|visits|recent")
+	fmt.Println("  - GET    /api/customers?phone=08xxx")
+	fmt.Println("  - POST   /api/customers")
+	fmt.Println("  - GET    /api/customers/{id}")
+	fmt.Println("  - PUT    /api/customers/{id}")
+	fmt.Println("  - DELETE /api/customers/{id} (Admin Only)")
+	fmt.Println("  - GET    /api/customers/{id}/transactions")
+	fmt.Println("  - GET    /api/customers/{id}/credit")
+	fmt.Println("  - POST   /api/customers/{id}/credit/payments")
+	fmt.Println("  - GET    /api/customers/{id}/points")
 	fmt.Println("")
 	fmt.Println("📚 Purchase Endpoints (Admin Only):")
 	fmt.Println("  - POST   /api/purchases")
 	fmt.Println("  - GET    /api/purchases")
 	fmt.Println("  - GET    /api/purchases/{id}")
 	fmt.Println("")
+	fmt.Println("📚 Tax Class Endpoints (Admin Only):")
+	fmt.Println("  - GET    /api/tax-classes")
+	fmt.Println("  - POST   /api/tax-classes")
+	fmt.Println("  - GET    /api/tax-classes/{id}")
+	fmt.Println("  - PUT    /api/tax-classes/{id}")
+	fmt.Println("  - DELETE /api/tax-classes/{id}")
+	fmt.Println("")
+	fmt.Println("📚 Voucher Endpoints (Admin Only):")
+	fmt.Println("  - GET    /api/vouchers?search=")
+	fmt.Println("  - POST   /api/vouchers")
+	fmt.Println("  - POST   /api/vouchers/generate")
+	fmt.Println("  - GET    /api/vouchers/{id}")
+	fmt.Println("  - PUT    /api/vouchers/{id}")
+	fmt.Println("  - DELETE /api/vouchers/{id}")
+	fmt.Println("")
 	fmt.Println("📚 Report & Dashboard Endpoints (Admin Only):")
 	fmt.Println("  - GET    /api/report/hari-ini")
 	fmt.Println("  - GET    /api/report?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD")
+	fmt.Println("  - GET    /api/report/tax?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD")
+	fmt.Println("  - GET    /api/report/overrides?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&user_id=")
+	fmt.Println("  - GET    /api/report/receivables")
+	fmt.Println("  - GET    /api/report/vouchers?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD")
+	fmt.Println("  - GET    /api/report/discounts?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD")
+	fmt.Println("  - GET    /api/report/shrinkage?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD")
+	fmt.Println("  - GET    /api/report/write-offs?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD")
 	fmt.Println("  - GET    /api/dashboard/summary?start_date=YYYY-MM-DD&end_date=YYYY-MM-DD&low_stock_threshold=5")
 	fmt.Println("  - GET    /api/dashboard/sales-trend?period=day|month|year&start_date=YYYY-MM-DD&end_date=YYYY-MM-DD")
 	fmt.Println("  - GET    /api/dashboard/top-products?limit=5")
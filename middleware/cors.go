@@ -10,7 +10,7 @@ func CORSMiddleware(next http.Handler) http.Handler {
 		// Set CORS headers untuk development (Allow All)
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Accept, Origin, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Accept, Origin, X-Requested-With, Idempotency-Key")
 		// Note: Access-Control-Allow-Credentials tidak boleh true jika Origin adalah *
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"kasir-api/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IdempotencyHeader adalah nama header yang dikirim client
+const IdempotencyHeader = "Idempotency-Key"
+
+// idempotencyCompleteAttempts adalah jumlah percobaan menyimpan response sukses
+const idempotencyCompleteAttempts = 3
+
+// IdempotencyStore adalah penyimpanan idempotency key (diimplementasikan oleh repositories.IdempotencyRepository)
+type IdempotencyStore interface {
+	Reserve(scope, key string, userID int, requestHash string) (*models.IdempotencyKey, bool, error)
+	Complete(id int, responseStatus int, responseBody string, resourceID *int) error
+	Release(id int) error
+}
+
+// recordingWriter menyalin response agar bisa disimpan untuk replay
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// formatResourceID menampilkan ID resource untuk log (nil = "-")
+func formatResourceID(id *int) string {
+	if id == nil {
+		return "-"
+	}
+	return strconv.Itoa(*id)
+}
+
+// hashRequest menghitung SHA-256 dari method + path + body
+// Path ikut di-hash karena 1 scope bisa melayani banyak resource (misal /api/carts/{id}/checkout):
+// key yang sama untuk cart lain harus ditolak, bukan me-replay hasil checkout cart sebelumnya.
+func hashRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Idempotency adalah middleware untuk POST yang membawa header Idempotency-Key
+// - Request pertama: diproses normal, response sukses (2xx) disimpan
+// - Replay dengan method, path & body sama: response pertama dikirim ulang tanpa memproses lagi
+// - Replay dengan path / body berbeda: 422
+// - Replay saat request pertama masih diproses: 409 (kecuali sudah macet > models.IdempotencyProcessingTimeout)
+// Request tanpa header diproses seperti biasa. Harus dipasang SETELAH AuthMiddleware (butuh user).
+func Idempotency(store IdempotencyStore, scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := strings.TrimSpace(r.Header.Get(IdempotencyHeader))
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > 255 {
+				http.Error(w, models.ErrIdempotencyKeyTooLong.Error(), http.StatusBadRequest)
+				return
+			}
+
+			user := GetUserFromContext(r.Context())
+			if user == nil {
+				http.Error(w, `{"error":"User not found in context"}`, http.StatusUnauthorized)
+				return
+			}
+
+			// Baca body untuk di-hash, lalu kembalikan ke request agar handler tetap bisa decode
+			bodyBytes, err := io.ReadAll(r.Body)
+			if err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+			requestHash := hashRequest(r, bodyBytes)
+
+			stored, created, err := store.Reserve(scope, key, user.ID, requestHash)
+			if err != nil {
+				log.Printf("❌ Idempotency: %v", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if !created {
+				if stored.RequestHash != requestHash {
+					http.Error(w, models.ErrIdempotencyKeyReused.Error(), http.StatusUnprocessableEntity)
+					return
+				}
+				if stored.Status != models.IdempotencyStatusCompleted || stored.ResponseStatus == nil || stored.ResponseBody == nil {
+					http.Error(w, models.ErrIdempotencyInProgress.Error(), http.StatusConflict)
+					return
+				}
+				// Replay response pertama
+				log.Printf("🔁 Idempotency replay: scope=%s key=%s user=%d", scope, key, user.ID)
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(*stored.ResponseStatus)
+				w.Write([]byte(*stored.ResponseBody))
+				return
+			}
+
+			// Handler panic → lepas key agar retry tidak tertahan 409, lalu teruskan panic ke net/http
+			defer func() {
+				if p := recover(); p != nil {
+					if err := store.Release(stored.ID); err != nil {
+						log.Printf("⚠️ Idempotency: %v", err)
+					}
+					panic(p)
+				}
+			}()
+
+			recorder := &recordingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			// Hanya response sukses yang disimpan; request gagal boleh di-retry dengan key yang sama
+			if recorder.statusCode < 200 || recorder.statusCode >= 300 {
+				if err := store.Release(stored.ID); err != nil {
+					log.Printf("⚠️ Idempotency: %v", err)
+				}
+				return
+			}
+
+			// Ambil ID resource (transaksi / pembelian) dari response
+			var resource struct {
+				ID int `json:"id"`
+			}
+			var resourceID *int
+			if json.Unmarshal(recorder.body.Bytes(), &resource) == nil && resource.ID > 0 {
+				resourceID = &resource.ID
+			}
+
+			// Response sudah terkirim ke client; jika gagal disimpan, key tetap processing dan retry
+			// baru bisa mengambil alih setelah timeout (berisiko diproses ulang) → coba beberapa kali
+			for attempt := 1; attempt <= idempotencyCompleteAttempts; attempt++ {
+				if err = store.Complete(stored.ID, recorder.statusCode, recorder.body.String(), resourceID); err == nil {
+					return
+				}
+				time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+			}
+			log.Printf("❌ Idempotency: response TIDAK tersimpan (scope=%s key=%s user=%d resource_id=%s), retry bisa diproses ulang: %v",
+				scope, key, user.ID, formatResourceID(resourceID), err)
+		})
+	}
+}
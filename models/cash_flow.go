@@ -2,12 +2,20 @@ package models
 
 // CashFlowSummary merepresentasikan ringkasan arus kas (cash in & cash out)
 type CashFlowSummary struct {
-	CashIn           float64 `json:"cash_in"`            // Pemasukan dari penjualan (transactions)
-	CashOutPurchases float64 `json:"cash_out_purchases"` // Pengeluaran untuk beli stok
-	CashOutPayroll   float64 `json:"cash_out_payroll"`   // Pengeluaran untuk bayar gaji karyawan
-	CashOutExpenses  float64 `json:"cash_out_expenses"`  // Pengeluaran operasional tambahan
-	CashOutTotal     float64 `json:"cash_out_total"`     // Total semua pengeluaran
-	NetCashFlow      float64 `json:"net_cash_flow"`      // Cash In - Cash Out Total
+	CashIn           float64              `json:"cash_in"`            // Pemasukan dari penjualan (transactions) + pembulatan tunai + cicilan kasbon, sudah dikurangi refund retur & penjualan kasbon
+	CashRefunds      float64              `json:"cash_refunds"`       // Refund retur penjualan tunai (informasi, sudah dikurangkan dari CashIn)
+	CreditSales      float64              `json:"credit_sales"`       // Penjualan kasbon (informasi, tidak termasuk CashIn sampai dibayar)
+	CreditRepayments float64              `json:"credit_repayments"`  // Cicilan kasbon pelanggan (sudah termasuk di CashIn)
+	PointsRedeemed   float64              `json:"points_redeemed"`    // Pembayaran dengan poin loyalitas (informasi, bukan uang masuk)
+	TotalRounding    float64              `json:"total_rounding"`     // Selisih pembulatan tunai (sudah termasuk di CashIn)
+	CashOutPurchases float64              `json:"cash_out_purchases"` // Pengeluaran untuk beli stok
+	CashOutPayroll   float64              `json:"cash_out_payroll"`   // Pengeluaran untuk bayar gaji karyawan
+	CashOutExpenses  float64              `json:"cash_out_expenses"`  // Pengeluaran operasional tambahan
+	PettyCashIn      float64              `json:"petty_cash_in"`      // Pay-in laci kasir (di luar penjualan)
+	PettyCashOut     float64              `json:"petty_cash_out"`     // Pay-out kas kecil dari laci kasir
+	CashOutTotal     float64              `json:"cash_out_total"`     // Total semua pengeluaran (termasuk kas kecil)
+	NetCashFlow      float64              `json:"net_cash_flow"`      // Cash In + Petty Cash In - Cash Out Total
+	CashInByMethod   []PaymentMethodTotal `json:"cash_in_by_method"`  // Rincian Cash In per metode pembayaran
 }
 
 // CashFlowTrendData merepresentasikan data per periode (harian/bulanan)
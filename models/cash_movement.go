@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Tipe pergerakan kas laci
+const (
+	CashMovementPayIn  = "pay_in"  // Uang masuk ke laci (bukan penjualan)
+	CashMovementPayOut = "pay_out" // Uang keluar dari laci (kas kecil)
+	CashMovementDrop   = "drop"    // Setor uang laci ke brankas
+)
+
+// IsValidCashMovementType mengecek apakah tipe pergerakan kas dikenali
+func IsValidCashMovementType(t string) bool {
+	switch t {
+	case CashMovementPayIn, CashMovementPayOut, CashMovementDrop:
+		return true
+	}
+	return false
+}
+
+// CashMovement represents a drawer pay-in / pay-out / drop
+// Struct untuk pergerakan uang laci di luar penjualan, terhubung ke shift kasir
+type CashMovement struct {
+	ID        int       `json:"id"`
+	ShiftID   int       `json:"shift_id"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username,omitempty"` // Nama kasir (dari JOIN users)
+	Type      string    `json:"type"`               // pay_in / pay_out / drop
+	Amount    float64   `json:"amount"`
+	Note      *string   `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CashMovementRequest represents the request body for POST /api/cash-movements
+type CashMovementRequest struct {
+	Type   string  `json:"type"`   // pay_in / pay_out / drop
+	Amount float64 `json:"amount"` // Harus > 0
+	Note   *string `json:"note"`   // Optional
+}
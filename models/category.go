@@ -7,6 +7,7 @@ type Category struct {
 	Description   string    `json:"description"`
 	DiscountType  *string   `json:"discount_type,omitempty"` // "percentage" atau "fixed" (nullable)
 	DiscountValue float64   `json:"discount_value"`          // Nilai diskon (0 = tidak ada diskon)
+	TaxClassID    *int      `json:"tax_class_id,omitempty"`  // Kelas pajak default produk di kategori ini
 	Products      []Product `json:"products,omitempty"`      // List products dalam category ini (untuk GET by ID)
 }
 
@@ -0,0 +1,10 @@
+package models
+
+// Metode harga pokok persediaan (COSTING_METHOD)
+// Lapisan biaya (cost_layers) selalu dicatat; metode menentukan harga pokok yang dipakai
+// untuk products.harga_beli dan snapshot transaction_details.harga_beli saat penjualan.
+const (
+	CostingAverage = "average" // Moving weighted average: rata-rata tertimbang setiap barang masuk
+	CostingFIFO    = "fifo"    // First in first out: barang keluar memakai harga lapisan terlama
+	CostingLast    = "last"    // Harga beli terakhir (perilaku lama)
+)
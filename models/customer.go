@@ -0,0 +1,69 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Customer merepresentasikan tabel customers
+// LifetimeSpend, VisitCount & LastVisit dihitung dari transaksi yang tidak dibatalkan
+type Customer struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone"` // Natural key (unik, sudah dinormalisasi)
+	Email     *string   `json:"email,omitempty"`
+	Address   *string   `json:"address,omitempty"`
+	Notes     *string   `json:"notes,omitempty"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	LifetimeSpend float64    `json:"lifetime_spend"` // Total belanja bersih (dikurangi refund retur)
+	VisitCount    int        `json:"visit_count"`    // Jumlah transaksi
+	LastVisit     *time.Time `json:"last_visit"`     // Waktu transaksi terakhir
+
+	CreditLimit   float64 `json:"credit_limit"`   // Limit kasbon (0 = tidak boleh kasbon)
+	CreditBalance float64 `json:"credit_balance"` // Sisa kasbon yang belum dibayar
+}
+
+// CustomerRequest DTO untuk POST / PUT /api/customers
+type CustomerRequest struct {
+	Name     string  `json:"name"`
+	Phone    string  `json:"phone"`
+	Email    *string `json:"email,omitempty"`
+	Address  *string `json:"address,omitempty"`
+	Notes    *string `json:"notes,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"` // Default true
+
+	CreditLimit *float64 `json:"credit_limit,omitempty"` // Hanya Admin; tidak dikirim = tidak berubah (default 0)
+}
+
+// NormalizePhone menyeragamkan format no. HP agar bisa dipakai sebagai natural key
+// Contoh: "+62 812-3456-789", "62812345678 9", "0812 3456 789" → "08123456789"
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	digits := b.String()
+	if strings.HasPrefix(digits, "62") {
+		digits = "0" + strings.TrimPrefix(digits, "62")
+	}
+	return digits
+}
+
+// Validate mengecek field wajib; Phone harus sudah dinormalisasi
+func (r *CustomerRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return ErrCustomerNameEmpty
+	}
+	if len(r.Phone) < 8 || len(r.Phone) > 15 {
+		return ErrInvalidCustomerPhone
+	}
+	if r.CreditLimit != nil && *r.CreditLimit < 0 {
+		return ErrInvalidCreditLimit
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Tipe baris buku besar kasbon pelanggan
+// charge menambah piutang; repayment, return & void menguranginya
+const (
+	CreditEntryCharge    = "charge"
+	CreditEntryRepayment = "repayment"
+	CreditEntryReturn    = "return"
+	CreditEntryVoid      = "void"
+)
+
+// Batas umur piutang (hari) untuk laporan aging
+const (
+	CreditAgingCurrentDays = 30
+	CreditAgingMidDays     = 60
+)
+
+// CreditLedgerEntry merepresentasikan 1 baris tabel customer_credit_ledger
+type CreditLedgerEntry struct {
+	ID            int       `json:"id"`
+	CustomerID    int       `json:"customer_id"`
+	Type          string    `json:"type"`   // charge / repayment / return / void
+	Amount        float64   `json:"amount"` // Selalu positif, arah ditentukan oleh type
+	TransactionID *int      `json:"transaction_id,omitempty"`
+	SalesReturnID *int      `json:"sales_return_id,omitempty"`
+	Method        *string   `json:"method,omitempty"` // Metode pembayaran cicilan
+	Reference     *string   `json:"reference,omitempty"`
+	Note          *string   `json:"note,omitempty"`
+	CreatedBy     *int      `json:"created_by,omitempty"`
+	Username      string    `json:"username,omitempty"` // Nama kasir (dari JOIN users)
+	ShiftID       *int      `json:"shift_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// CreditAging adalah sisa piutang dikelompokkan berdasarkan umur kasbon
+// Cicilan dialokasikan ke kasbon paling lama terlebih dahulu (FIFO)
+type CreditAging struct {
+	Days0To30  float64 `json:"days_0_30"`
+	Days31To60 float64 `json:"days_31_60"`
+	DaysOver60 float64 `json:"days_over_60"`
+}
+
+// Add menambahkan sisa piutang ke bucket sesuai umur (hari)
+func (a *CreditAging) Add(ageDays int, amount float64) {
+	switch {
+	case ageDays <= CreditAgingCurrentDays:
+		a.Days0To30 += amount
+	case ageDays <= CreditAgingMidDays:
+		a.Days31To60 += amount
+	default:
+		a.DaysOver60 += amount
+	}
+}
+
+// CustomerCreditSummary adalah saldo kasbon 1 pelanggan
+// Response untuk GET /api/customers/{id}/credit
+type CustomerCreditSummary struct {
+	CustomerID      int                 `json:"customer_id"`
+	CustomerName    string              `json:"customer_name"`
+	Phone           string              `json:"phone"`
+	CreditLimit     float64             `json:"credit_limit"`
+	Outstanding     float64             `json:"outstanding"`      // Sisa kasbon yang belum dibayar
+	AvailableCredit float64             `json:"available_credit"` // Limit - outstanding (min 0)
+	OldestChargeAt  *time.Time          `json:"oldest_charge_at,omitempty"`
+	Aging           CreditAging         `json:"aging"`
+	Entries         []CreditLedgerEntry `json:"entries,omitempty"` // Riwayat kasbon & cicilan (terbaru dulu)
+}
+
+// CreditRepaymentRequest represents the request body for POST /api/customers/{id}/credit/payments
+type CreditRepaymentRequest struct {
+	Amount    float64 `json:"amount"`
+	Method    string  `json:"method"` // cash (default) / qris / debit / transfer
+	Reference string  `json:"reference"`
+	Note      string  `json:"note"`
+	CreatedBy int     `json:"-"` // Diisi dari context auth
+}
+
+// ReceivablesReport adalah ringkasan piutang semua pelanggan
+// Response untuk GET /api/report/receivables
+type ReceivablesReport struct {
+	AsOf             time.Time               `json:"as_of"`
+	TotalOutstanding float64                 `json:"total_outstanding"`
+	Aging            CreditAging             `json:"aging"`
+	Customers        []CustomerCreditSummary `json:"customers"` // Hanya pelanggan dengan sisa kasbon > 0
+}
@@ -1,42 +1,296 @@
 package models
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
 // DiscountType represents type of discount: PERCENTAGE (0) or FIXED (1)
 // Tipe diskon: Persentase (misal 10%) atau Nominal (misal Rp 5.000)
+// Tipe promosi (dihitung otomatis per baris oleh pricing engine):
+// BUY_X_GET_Y (beli X gratis Y), BUNDLE (paket harga tetap), QTY_TIER (harga grosir per qty)
 type DiscountType string
 
 const (
 	DiscountPercentage DiscountType = "PERCENTAGE"
 	DiscountFixed      DiscountType = "FIXED"
+	DiscountBuyXGetY   DiscountType = "BUY_X_GET_Y"
+	DiscountBundle     DiscountType = "BUNDLE"
+	DiscountQtyTier    DiscountType = "QTY_TIER"
+)
+
+// StackingMode menentukan apakah diskon boleh digabung dengan diskon lain di baris yang sama
+type StackingMode string
+
+const (
+	StackingStackable StackingMode = "STACKABLE" // Default: boleh digabung dengan diskon STACKABLE lain
+	StackingExclusive StackingMode = "EXCLUSIVE" // Hanya dipakai di baris yang belum dapat diskon, lalu baris ditutup untuk diskon lain
 )
 
+// Level diskon di audit transaction_discounts
+const (
+	DiscountLevelItem      = "ITEM"      // Diskon otomatis produk / kategori
+	DiscountLevelPromotion = "PROMOTION" // BUY_X_GET_Y / BUNDLE / QTY_TIER
+	DiscountLevelOrder     = "ORDER"     // Diskon transaksi (discount_id / voucher_code), porsi per baris
+	DiscountLevelManual    = "MANUAL"    // Diskon manual (admin / persetujuan supervisor)
+)
+
+// IsPromotion mengecek apakah tipe diskon adalah promosi (BUY_X_GET_Y / BUNDLE / QTY_TIER)
+func (t DiscountType) IsPromotion() bool {
+	return t == DiscountBuyXGetY || t == DiscountBundle || t == DiscountQtyTier
+}
+
+// DiscountBundleItem adalah isi 1 paket bundling (produk & jumlah per paket)
+type DiscountBundleItem struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name,omitempty"` // Dari JOIN products
+	Quantity    int    `json:"quantity"`
+}
+
+// DiscountTier adalah 1 tingkat harga grosir: beli >= MinQty → harga satuan UnitPrice
+type DiscountTier struct {
+	MinQty    int     `json:"min_qty"`
+	UnitPrice float64 `json:"unit_price"`
+}
+
 // Discount represents a promotional code or automatic discount
 // Struct ini untuk diskon yang tersedia
 type Discount struct {
 	ID             int          `json:"id" db:"id"`
 	Name           string       `json:"name" db:"name"`
-	Type           DiscountType `json:"type" db:"type"`                         // Const: PERCENTAGE / FIXED
-	Value          float64      `json:"value" db:"value"`                       // 10.0 (10%) or 5000 (Rp 5,000)
+	Type           DiscountType `json:"type" db:"type"`                         // Const: PERCENTAGE / FIXED / BUY_X_GET_Y / BUNDLE / QTY_TIER
+	Value          float64      `json:"value" db:"value"`                       // 10.0 (10%) or 5000 (Rp 5,000); BUY_X_GET_Y: % potongan unit gratis; BUNDLE: harga paket
 	MinOrderAmount float64      `json:"min_order_amount" db:"min_order_amount"` // Minimal belanja Rp 50,000 baru aktif
 	ProductID      *int         `json:"product_id" db:"product_id"`             // Nullable: Jika set, hanya apply ke produk ini
 	CategoryID     *int         `json:"category_id" db:"category_id"`           // Nullable: Jika set, hanya apply ke kategori ini
 	StartDate      time.Time    `json:"start_date" db:"start_date"`
 	EndDate        time.Time    `json:"end_date" db:"end_date"`
 	IsActive       bool         `json:"is_active" db:"is_active"`
+
+	// Jadwal berulang (happy hour) di timezone toko (STORE_TIMEZONE), di dalam periode start_date - end_date.
+	// DaysOfWeek: 1 = Senin ... 7 = Minggu (kosong = setiap hari).
+	// TimeStart inklusif, TimeEnd eksklusif ("15:00" - "17:00"); TimeEnd < TimeStart = melewati tengah malam
+	// dan jam setelah tengah malam ikut hari mulainya (Jumat 22:00 - 02:00 berlaku sampai Sabtu 01:59).
+	DaysOfWeek []int   `json:"days_of_week,omitempty" db:"days_of_week"`
+	TimeStart  *string `json:"time_start,omitempty" db:"time_start"`
+	TimeEnd    *string `json:"time_end,omitempty" db:"time_end"`
+
+	// Aturan stacking: diskon dievaluasi dari priority terbesar; EXCLUSIVE tidak digabung dengan diskon lain.
+	// MaxTotalPercent membatasi total diskon baris (% dari harga × qty) jika diskon ini ikut dipakai.
+	StackingMode    StackingMode `json:"stacking_mode" db:"stacking_mode"`
+	Priority        int          `json:"priority" db:"priority"`
+	MaxTotalPercent *float64     `json:"max_total_percent,omitempty" db:"max_total_percent"`
+
+	// Parameter promosi
+	BuyQty      *int                 `json:"buy_qty,omitempty" db:"buy_qty"` // BUY_X_GET_Y: jumlah beli
+	GetQty      *int                 `json:"get_qty,omitempty" db:"get_qty"` // BUY_X_GET_Y: jumlah gratis
+	BundleItems []DiscountBundleItem `json:"bundle_items,omitempty"`         // BUNDLE: isi paket
+	Tiers       []DiscountTier       `json:"tiers,omitempty"`                // QTY_TIER: urut min_qty naik
+}
+
+// Normalize mengisi nilai default sebelum validasi (stacking_mode kosong = STACKABLE)
+func (d *Discount) Normalize() {
+	d.Name = strings.TrimSpace(d.Name)
+	if d.StackingMode == "" {
+		d.StackingMode = StackingStackable
+	}
+}
+
+// Validate mengecek aturan diskon / promosi sebelum disimpan
+func (d *Discount) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return ErrDiscountNameEmpty
+	}
+	if d.StackingMode != StackingStackable && d.StackingMode != StackingExclusive {
+		return ErrInvalidStackingMode
+	}
+	if d.MaxTotalPercent != nil && (*d.MaxTotalPercent <= 0 || *d.MaxTotalPercent > 100) {
+		return ErrInvalidMaxDiscountPercent
+	}
+	if d.ProductID != nil && d.CategoryID != nil {
+		return ErrInvalidDiscountScope
+	}
+	if err := d.validateSchedule(); err != nil {
+		return err
+	}
+	scoped := d.ProductID != nil || d.CategoryID != nil
+
+	switch d.Type {
+	case DiscountPercentage, DiscountFixed:
+		if d.Value <= 0 || (d.Type == DiscountPercentage && d.Value > 100) {
+			return ErrInvalidDiscountValue
+		}
+	case DiscountBuyXGetY:
+		if !scoped || d.BuyQty == nil || d.GetQty == nil || *d.BuyQty <= 0 || *d.GetQty <= 0 {
+			return ErrInvalidBuyGetQty
+		}
+		// Value 0 = unit gratis penuh (100%)
+		if d.Value < 0 || d.Value > 100 {
+			return ErrInvalidDiscountValue
+		}
+	case DiscountBundle:
+		if scoped || d.Value <= 0 || len(d.BundleItems) == 0 {
+			return ErrInvalidBundleItems
+		}
+		seen := make(map[int]bool)
+		for _, item := range d.BundleItems {
+			if item.Quantity <= 0 || seen[item.ProductID] {
+				return ErrInvalidBundleItems
+			}
+			seen[item.ProductID] = true
+		}
+	case DiscountQtyTier:
+		if !scoped || len(d.Tiers) == 0 {
+			return ErrInvalidQtyTiers
+		}
+		seen := make(map[int]bool)
+		for _, tier := range d.Tiers {
+			if tier.MinQty <= 1 || tier.UnitPrice < 0 || seen[tier.MinQty] {
+				return ErrInvalidQtyTiers
+			}
+			seen[tier.MinQty] = true
+		}
+	default:
+		return ErrInvalidDiscountType
+	}
+	return nil
 }
 
-// CalculateDiscount menghitung jumlah potongan berdasarkan total belanja
+// validateSchedule mengecek hari (1-7, unik) dan jam "HH:MM" (keduanya diisi, tidak sama)
+func (d *Discount) validateSchedule() error {
+	seen := make(map[int]bool)
+	for _, day := range d.DaysOfWeek {
+		if day < 1 || day > 7 || seen[day] {
+			return ErrInvalidDiscountSchedule
+		}
+		seen[day] = true
+	}
+	if d.TimeStart == nil && d.TimeEnd == nil {
+		return nil
+	}
+	if d.TimeStart == nil || d.TimeEnd == nil {
+		return ErrInvalidDiscountSchedule
+	}
+	start, okStart := ParseClock(*d.TimeStart)
+	end, okEnd := ParseClock(*d.TimeEnd)
+	if !okStart || !okEnd || start == end {
+		return ErrInvalidDiscountSchedule
+	}
+	return nil
+}
+
+// HasSchedule mengecek apakah diskon punya jadwal hari / jam
+func (d *Discount) HasSchedule() bool {
+	return len(d.DaysOfWeek) > 0 || (d.TimeStart != nil && d.TimeEnd != nil)
+}
+
+// IsScheduledAt mengecek jadwal hari & jam pada waktu t di timezone toko loc
+// (tanpa cek is_active / start_date - end_date)
+func (d *Discount) IsScheduledAt(t time.Time, loc *time.Location) bool {
+	if !d.HasSchedule() {
+		return true
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+	local := t.In(loc)
+	day := local
+
+	if d.TimeStart != nil && d.TimeEnd != nil {
+		start, okStart := ParseClock(*d.TimeStart)
+		end, okEnd := ParseClock(*d.TimeEnd)
+		if !okStart || !okEnd {
+			return false
+		}
+		minutes := local.Hour()*60 + local.Minute()
+		switch {
+		case start < end:
+			if minutes < start || minutes >= end {
+				return false
+			}
+		case minutes >= start:
+			// Melewati tengah malam, bagian sebelum tengah malam
+		case minutes < end:
+			// Melewati tengah malam, bagian setelah tengah malam → jadwal milik hari sebelumnya
+			day = local.AddDate(0, 0, -1)
+		default:
+			return false
+		}
+	}
+
+	if len(d.DaysOfWeek) == 0 {
+		return true
+	}
+	weekday := IsoWeekday(day)
+	for _, dow := range d.DaysOfWeek {
+		if dow == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActiveAt mengecek status aktif, periode start_date - end_date dan jadwal pada waktu t
+func (d *Discount) IsActiveAt(t time.Time, loc *time.Location) bool {
+	if !d.IsActive || t.Before(d.StartDate) || t.After(d.EndDate) {
+		return false
+	}
+	return d.IsScheduledAt(t, loc)
+}
+
+// IsoWeekday mengembalikan hari ISO: 1 = Senin ... 7 = Minggu
+func IsoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+// ParseClock mengubah "HH:MM" (00:00 - 23:59) menjadi menit sejak tengah malam
+func ParseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil || len(s) != 5 {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// FormatDaysOfWeek mengubah [1, 5] menjadi "1,5" untuk disimpan (nil = setiap hari)
+func FormatDaysOfWeek(days []int) *string {
+	if len(days) == 0 {
+		return nil
+	}
+	parts := make([]string, len(days))
+	for i, day := range days {
+		parts[i] = strconv.Itoa(day)
+	}
+	s := strings.Join(parts, ",")
+	return &s
+}
+
+// ParseDaysOfWeek mengubah "1,5" dari database menjadi [1, 5]
+func ParseDaysOfWeek(s string) []int {
+	var days []int
+	for _, part := range strings.Split(s, ",") {
+		if day, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// CalculateDiscount menghitung jumlah potongan berdasarkan total belanja pada waktu now
+// (jadwal hari & jam dicek di timezone toko loc)
 // Return: jumlah potongan (amount), bukan harga akhir
-func (d *Discount) CalculateDiscount(totalAmount float64) float64 {
+func (d *Discount) CalculateDiscount(totalAmount float64, now time.Time, loc *time.Location) float64 {
 	// Cek minimal belanja
 	if totalAmount < d.MinOrderAmount {
 		return 0
 	}
 
-	// Cek periode aktif
-	now := time.Now()
-	if now.Before(d.StartDate) || now.After(d.EndDate) || !d.IsActive {
+	// Cek periode aktif & jadwal
+	if !d.IsActiveAt(now, loc) {
 		return 0
 	}
 
@@ -53,3 +307,63 @@ func (d *Discount) CalculateDiscount(totalAmount float64) float64 {
 	}
 	return discountAmount
 }
+
+// TransactionDiscount adalah 1 diskon yang dipakai di 1 baris transaksi (audit kombinasi diskon)
+// Response GET /api/transactions/{id} (discounts) & preview checkout (applied_discounts)
+type TransactionDiscount struct {
+	ID                  int          `json:"id,omitempty"`
+	TransactionDetailID *int         `json:"transaction_detail_id,omitempty"`
+	ProductID           int          `json:"product_id"`
+	DiscountID          *int         `json:"discount_id,omitempty"`
+	VoucherID           *int         `json:"voucher_id,omitempty"`
+	Level               string       `json:"level"` // ITEM / PROMOTION / ORDER / MANUAL
+	Name                string       `json:"name"`
+	StackingMode        StackingMode `json:"stacking_mode"`
+	Priority            int          `json:"priority"`
+	Amount              float64      `json:"amount"`
+	Capped              bool         `json:"capped"` // Potongan dikurangi karena max_total_percent
+}
+
+// DiscountSalesSummary adalah penjualan cakupan diskon (produk / kategori / isi paket / semua produk) dalam 1 periode
+type DiscountSalesSummary struct {
+	StartDate        time.Time `json:"start_date"`
+	EndDate          time.Time `json:"end_date"`
+	TransactionCount int       `json:"transaction_count"`
+	QuantitySold     int       `json:"quantity_sold"`
+	Revenue          float64   `json:"revenue"`      // Penjualan bersih (setelah semua diskon)
+	GrossProfit      float64   `json:"gross_profit"` // Revenue - HPP - PPN inclusive
+}
+
+// DiscountReportLine adalah ringkasan pemakaian 1 diskon / promosi dalam periode laporan
+type DiscountReportLine struct {
+	DiscountID    *int    `json:"discount_id"` // nil = diskon baris tanpa referensi (manual, transaksi lama, diskon sudah dihapus)
+	Name          string  `json:"name"`
+	Type          string  `json:"type,omitempty"`
+	Level         string  `json:"level"` // ITEM / PROMOTION / ORDER / MANUAL
+	ProductID     *int    `json:"product_id,omitempty"`
+	CategoryID    *int    `json:"category_id,omitempty"`
+	UsageCount    int     `json:"usage_count"` // Jumlah transaksi yang memakai
+	LineCount     int     `json:"line_count"`  // Jumlah baris yang mendapat potongan
+	QuantitySold  int     `json:"quantity_sold"`
+	TotalDiscount float64 `json:"total_discount"`
+	Revenue       float64 `json:"revenue"`      // Penjualan bersih baris yang mendapat potongan
+	GrossProfit   float64 `json:"gross_profit"` // Laba kotor baris yang mendapat potongan
+
+	// Perbandingan cakupan diskon: selama promo (dalam rentang laporan) vs periode sama panjang
+	// tepat sebelum start_date diskon. Kosong untuk diskon tanpa referensi.
+	PromoPeriod          *DiscountSalesSummary `json:"promo_period,omitempty"`
+	BeforePromo          *DiscountSalesSummary `json:"before_promo,omitempty"`
+	RevenueGrowthPercent *float64              `json:"revenue_growth_percent,omitempty"` // nil jika sebelum promo tidak ada penjualan
+	ProfitGrowthPercent  *float64              `json:"profit_growth_percent,omitempty"`
+}
+
+// DiscountReport represents discount & promotion usage in a period
+// Response untuk GET /api/report/discounts (voucher ada di /api/report/vouchers)
+type DiscountReport struct {
+	StartDate        time.Time            `json:"start_date"`
+	EndDate          time.Time            `json:"end_date"`
+	TotalDiscount    float64              `json:"total_discount"`
+	TotalRevenue     float64              `json:"total_revenue"`      // Penjualan bersih baris yang mendapat potongan (1 baris dihitung 1x)
+	TotalGrossProfit float64              `json:"total_gross_profit"` // Laba kotor baris yang mendapat potongan (1 baris dihitung 1x)
+	Discounts        []DiscountReportLine `json:"discounts"`
+}
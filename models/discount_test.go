@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// Test jadwal diskon (hari & jam): batas jam, tengah malam dan perbedaan timezone (UTC vs WIB / WITA)
+
+func clock(s string) *string { return &s }
+
+func loadZone(t *testing.T, name string, offsetHours int) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.FixedZone(name, offsetHours*60*60)
+	}
+	return loc
+}
+
+func TestDiscountIsScheduledAt(t *testing.T) {
+	wib := loadZone(t, "Asia/Jakarta", 7)
+	wita := loadZone(t, "Asia/Makassar", 8)
+
+	// 16 Oktober 2026 = Jumat
+	happyHour := &Discount{
+		Name: "Happy Hour Jumat", Type: DiscountPercentage, Value: 10, IsActive: true,
+		StartDate: time.Date(2026, 10, 1, 0, 0, 0, 0, wib), EndDate: time.Date(2026, 10, 31, 23, 59, 59, 0, wib),
+		DaysOfWeek: []int{5}, TimeStart: clock("15:00"), TimeEnd: clock("17:00"),
+	}
+	friday := &Discount{IsActive: true, DaysOfWeek: []int{5}}
+	lateNight := &Discount{IsActive: true, DaysOfWeek: []int{5}, TimeStart: clock("22:00"), TimeEnd: clock("02:00")}
+	weekend := &Discount{IsActive: true, DaysOfWeek: []int{6, 7}}
+
+	tests := []struct {
+		name     string
+		discount *Discount
+		at       time.Time
+		store    *time.Location
+		want     bool
+	}{
+		// Jumat 15:00 - 17:00 (WIB)
+		{"Jumat 14:59:59 WIB → belum mulai", happyHour, time.Date(2026, 10, 16, 14, 59, 59, 0, wib), wib, false},
+		{"Jumat 15:00 WIB → mulai (inklusif)", happyHour, time.Date(2026, 10, 16, 15, 0, 0, 0, wib), wib, true},
+		{"Jumat 16:59:59 WIB → masih berlaku", happyHour, time.Date(2026, 10, 16, 16, 59, 59, 0, wib), wib, true},
+		{"Jumat 17:00 WIB → selesai (eksklusif)", happyHour, time.Date(2026, 10, 16, 17, 0, 0, 0, wib), wib, false},
+		{"Kamis 16:00 WIB → bukan hari jadwal", happyHour, time.Date(2026, 10, 15, 16, 0, 0, 0, wib), wib, false},
+		{"Jumat 08:30 UTC (= 15:30 WIB) → berlaku", happyHour, time.Date(2026, 10, 16, 8, 30, 0, 0, time.UTC), wib, true},
+		{"Jumat 15:30 UTC (= 22:30 WIB) → tidak berlaku", happyHour, time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC), wib, false},
+		{"Toko WITA: Jumat 15:30 WIB (= 16:30 WITA) → berlaku", happyHour, time.Date(2026, 10, 16, 15, 30, 0, 0, wib), wita, true},
+		{"Toko WITA: Jumat 16:30 WIB (= 17:30 WITA) → tidak berlaku", happyHour, time.Date(2026, 10, 16, 16, 30, 0, 0, wib), wita, false},
+
+		// Sepanjang hari Jumat: hari di UTC berbeda dengan hari lokal toko
+		{"Kamis 17:00 UTC (= Jumat 00:00 WIB) → berlaku", friday, time.Date(2026, 10, 15, 17, 0, 0, 0, time.UTC), wib, true},
+		{"Kamis 16:59:59 UTC (= Kamis 23:59:59 WIB) → tidak berlaku", friday, time.Date(2026, 10, 15, 16, 59, 59, 0, time.UTC), wib, false},
+		{"Jumat 17:00 UTC (= Sabtu 00:00 WIB) → tidak berlaku", friday, time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC), wib, false},
+
+		// Jumat 22:00 - 02:00: melewati tengah malam, berlaku sampai Sabtu 01:59
+		{"Jumat 21:59 → belum mulai", lateNight, time.Date(2026, 10, 16, 21, 59, 0, 0, wib), wib, false},
+		{"Jumat 22:00 → mulai", lateNight, time.Date(2026, 10, 16, 22, 0, 0, 0, wib), wib, true},
+		{"Jumat 23:59:59 → berlaku", lateNight, time.Date(2026, 10, 16, 23, 59, 59, 0, wib), wib, true},
+		{"Sabtu 00:00 → masih jadwal Jumat", lateNight, time.Date(2026, 10, 17, 0, 0, 0, 0, wib), wib, true},
+		{"Sabtu 01:59 → masih jadwal Jumat", lateNight, time.Date(2026, 10, 17, 1, 59, 0, 0, wib), wib, true},
+		{"Sabtu 02:00 → selesai", lateNight, time.Date(2026, 10, 17, 2, 0, 0, 0, wib), wib, false},
+		{"Jumat 01:00 → jadwal Kamis, tidak berlaku", lateNight, time.Date(2026, 10, 16, 1, 0, 0, 0, wib), wib, false},
+		{"Sabtu 22:30 → bukan hari jadwal", lateNight, time.Date(2026, 10, 17, 22, 30, 0, 0, wib), wib, false},
+
+		// Akhir pekan (Sabtu & Minggu) sepanjang hari
+		{"Minggu 23:59:59 → berlaku", weekend, time.Date(2026, 10, 18, 23, 59, 59, 0, wib), wib, true},
+		{"Senin 00:00 → tidak berlaku", weekend, time.Date(2026, 10, 19, 0, 0, 0, 0, wib), wib, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.discount.IsScheduledAt(tt.at, tt.store); got != tt.want {
+				t.Errorf("IsScheduledAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscountCalculateDiscountWithSchedule(t *testing.T) {
+	wib := loadZone(t, "Asia/Jakarta", 7)
+	happyHour := &Discount{
+		Name: "Happy Hour Jumat", Type: DiscountPercentage, Value: 10, IsActive: true,
+		StartDate: time.Date(2026, 10, 1, 0, 0, 0, 0, wib), EndDate: time.Date(2026, 10, 31, 23, 59, 59, 0, wib),
+		DaysOfWeek: []int{5}, TimeStart: clock("15:00"), TimeEnd: clock("17:00"),
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want float64
+	}{
+		{"Rp100.000 Jumat 15:30 → potong Rp10.000", time.Date(2026, 10, 16, 15, 30, 0, 0, wib), 10000},
+		{"Rp100.000 Jumat 17:00 → tanpa potongan", time.Date(2026, 10, 16, 17, 0, 0, 0, wib), 0},
+		{"Jumat setelah end_date → tanpa potongan", time.Date(2026, 11, 6, 15, 30, 0, 0, wib), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := happyHour.CalculateDiscount(100000, tt.at, wib); got != tt.want {
+				t.Errorf("CalculateDiscount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if happyHour.IsActiveAt(time.Date(2026, 11, 6, 15, 30, 0, 0, wib), wib) {
+		t.Error("IsActiveAt() setelah end_date = true, want false")
+	}
+}
+
+func TestDiscountValidateSchedule(t *testing.T) {
+	tests := []struct {
+		discount *Discount
+		want     error
+	}{
+		{&Discount{Name: "Hari 0", Type: DiscountFixed, Value: 1000, DaysOfWeek: []int{0}}, ErrInvalidDiscountSchedule},
+		{&Discount{Name: "Hari dobel", Type: DiscountFixed, Value: 1000, DaysOfWeek: []int{5, 5}}, ErrInvalidDiscountSchedule},
+		{&Discount{Name: "Jam tanpa selesai", Type: DiscountFixed, Value: 1000, TimeStart: clock("15:00")}, ErrInvalidDiscountSchedule},
+		{&Discount{Name: "Jam 24:00", Type: DiscountFixed, Value: 1000, TimeStart: clock("22:00"), TimeEnd: clock("24:00")}, ErrInvalidDiscountSchedule},
+		{&Discount{Name: "Jam sama", Type: DiscountFixed, Value: 1000, TimeStart: clock("15:00"), TimeEnd: clock("15:00")}, ErrInvalidDiscountSchedule},
+		{&Discount{Name: "Format 9:00", Type: DiscountFixed, Value: 1000, TimeStart: clock("9:00"), TimeEnd: clock("17:00")}, ErrInvalidDiscountSchedule},
+		{&Discount{Name: "Happy Hour Jumat", Type: DiscountPercentage, Value: 10, DaysOfWeek: []int{5}, TimeStart: clock("15:00"), TimeEnd: clock("17:00")}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.discount.Name, func(t *testing.T) {
+			tt.discount.Normalize()
+			if got := tt.discount.Validate(); got != tt.want {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Product errors
 var (
@@ -32,7 +35,191 @@ var (
 
 // Transaction errors
 var (
-	ErrEmptyCart         = errors.New("keranjang belanja kosong")
-	ErrInvalidQuantity   = errors.New("jumlah tidak valid")
-	ErrTransactionFailed = errors.New("transaksi gagal")
+	ErrEmptyCart              = errors.New("keranjang belanja kosong")
+	ErrInvalidQuantity        = errors.New("jumlah tidak valid")
+	ErrTransactionFailed      = errors.New("transaksi gagal")
+	ErrTransactionVoided      = errors.New("transaksi sudah dibatalkan")
+	ErrVoidReasonEmpty        = errors.New("alasan pembatalan wajib diisi")
+	ErrTransactionHasReturns  = errors.New("transaksi sudah memiliki retur, tidak bisa dibatalkan")
+	ErrReturnQuantityExceeded = errors.New("jumlah retur melebihi sisa quantity yang bisa diretur")
+	ErrInvalidPaymentMethod   = errors.New("metode pembayaran tidak valid")
+	ErrPaymentInsufficient    = errors.New("total pembayaran kurang dari total belanja")
+	ErrNonCashOverpayment     = errors.New("pembayaran non-tunai melebihi total belanja")
+	ErrInvalidReceiptFormat   = errors.New("format struk harus escpos, text, atau html")
+	ErrDiscountMismatch       = errors.New("diskon tidak sesuai dengan perhitungan server")
+)
+
+// Shift errors
+var (
+	ErrShiftAlreadyOpen    = errors.New("masih ada shift yang terbuka, tutup dulu sebelum membuka shift baru")
+	ErrNoOpenShift         = errors.New("tidak ada shift yang sedang terbuka")
+	ErrInvalidOpeningFloat = errors.New("modal awal tidak boleh negatif")
+	ErrInvalidCountedCash  = errors.New("hitungan uang tidak valid")
+	ErrInvalidCashMovement = errors.New("tipe pergerakan kas harus pay_in, pay_out, atau drop")
+	ErrInvalidCashAmount   = errors.New("nominal pergerakan kas harus lebih dari 0")
+)
+
+// Tax errors
+var (
+	ErrTaxClassNameEmpty = errors.New("nama kelas pajak wajib diisi")
+	ErrInvalidTaxRate    = errors.New("tarif pajak harus antara 0 dan 100")
+)
+
+// Override errors
+var (
+	ErrOverrideReasonEmpty     = errors.New("alasan override wajib diisi")
+	ErrApproverNotAdmin        = errors.New("persetujuan override hanya bisa diberikan oleh admin")
+	ErrApprovalTokenRequired   = errors.New("override harga / diskon membutuhkan approval_token")
+	ErrInvalidApprovalToken    = errors.New("approval token tidak valid atau sudah kedaluwarsa")
+	ErrInvalidOverridePrice    = errors.New("harga override tidak boleh negatif")
+	ErrOverrideProductEmpty    = errors.New("product_id wajib diisi untuk persetujuan override")
+	ErrOverrideNothingApproved = errors.New("persetujuan override harus berisi override_price dan / atau max_discount_amount")
+	ErrInvalidMaxDiscount      = errors.New("max_discount_amount harus lebih dari 0")
+	ErrApprovalTokenMismatch   = errors.New("approval token tidak berlaku untuk baris ini (produk, harga atau batas diskon berbeda)")
+	ErrApprovalTokenUsed       = errors.New("approval token sudah pernah dipakai")
+)
+
+// Customer errors
+var (
+	ErrCustomerNameEmpty    = errors.New("nama pelanggan wajib diisi")
+	ErrInvalidCustomerPhone = errors.New("no. HP pelanggan harus 8-15 digit")
+	ErrCustomerPhoneExists  = errors.New("no. HP sudah terdaftar untuk pelanggan lain")
+	ErrCustomerInactive     = errors.New("pelanggan tidak aktif")
+)
+
+// Customer credit (kasbon) errors
+var (
+	ErrCreditRequiresCustomer  = errors.New("pembayaran kasbon (credit) wajib memilih pelanggan")
+	ErrCreditLimitExceeded     = errors.New("kasbon melebihi limit pelanggan")
+	ErrInvalidCreditLimit      = errors.New("limit kasbon tidak boleh negatif")
+	ErrInvalidRepaymentAmount  = errors.New("nominal pembayaran kasbon harus lebih dari 0")
+	ErrRepaymentExceedsBalance = errors.New("nominal pembayaran melebihi sisa kasbon")
+)
+
+// Loyalty errors
+var (
+	ErrPointsRequireCustomer = errors.New("pembayaran dengan poin wajib memilih pelanggan")
+	ErrInsufficientPoints    = errors.New("saldo poin pelanggan tidak mencukupi")
+	ErrInvalidPointsAmount   = errors.New("nominal pembayaran poin harus kelipatan nilai 1 poin")
+)
+
+// Voucher errors
+var (
+	ErrInvalidVoucherCode      = errors.New("kode voucher harus 3-32 karakter (huruf, angka atau tanda hubung)")
+	ErrVoucherCodeExists       = errors.New("kode voucher sudah dipakai")
+	ErrVoucherNameEmpty        = errors.New("nama voucher wajib diisi")
+	ErrInvalidVoucherType      = errors.New("tipe voucher harus PERCENTAGE atau FIXED")
+	ErrInvalidVoucherValue     = errors.New("nilai voucher harus lebih dari 0 (persentase maksimal 100)")
+	ErrInvalidVoucherPeriod    = errors.New("end_date voucher harus setelah start_date")
+	ErrInvalidVoucherLimit     = errors.New("batas pemakaian voucher harus lebih dari 0")
+	ErrInvalidVoucherCount     = errors.New("jumlah voucher yang dibuat harus 1-1000")
+	ErrVoucherWithDiscount     = errors.New("voucher_code tidak bisa digabung dengan discount_id")
+	ErrVoucherInactive         = errors.New("voucher tidak aktif atau di luar masa berlaku")
+	ErrVoucherMinOrder         = errors.New("minimal belanja voucher tidak terpenuhi")
+	ErrVoucherExhausted        = errors.New("kuota voucher sudah habis")
+	ErrVoucherRequiresCustomer = errors.New("voucher ini hanya bisa dipakai pelanggan terdaftar (customer_id)")
+	ErrVoucherCustomerLimit    = errors.New("pelanggan sudah mencapai batas pemakaian voucher")
 )
+
+// Discount & promotion errors
+var (
+	ErrDiscountNameEmpty    = errors.New("nama diskon wajib diisi")
+	ErrInvalidDiscountType  = errors.New("tipe diskon harus PERCENTAGE, FIXED, BUY_X_GET_Y, BUNDLE atau QTY_TIER")
+	ErrInvalidDiscountValue = errors.New("nilai diskon harus lebih dari 0 (persentase maksimal 100)")
+	ErrInvalidDiscountScope = errors.New("diskon hanya boleh punya salah satu: product_id atau category_id")
+	ErrInvalidBuyGetQty     = errors.New("promo BUY_X_GET_Y wajib buy_qty & get_qty > 0 dan product_id / category_id")
+	ErrInvalidBundleItems   = errors.New("paket BUNDLE wajib punya isi (product_id unik, quantity > 0) dan harga paket > 0")
+	ErrInvalidQtyTiers      = errors.New("promo QTY_TIER wajib product_id / category_id dan tier (min_qty > 1 unik, unit_price >= 0)")
+
+	ErrInvalidStackingMode       = errors.New("stacking_mode harus STACKABLE atau EXCLUSIVE")
+	ErrInvalidMaxDiscountPercent = errors.New("max_total_percent harus lebih dari 0 dan maksimal 100")
+	ErrDiscountNotCombinable     = errors.New("diskon transaksi tidak bisa digabung: semua item sudah memakai diskon eksklusif")
+	ErrInvalidDiscountSchedule   = errors.New("jadwal diskon tidak valid: days_of_week 1-7 (Senin-Minggu), time_start & time_end format HH:MM dan tidak sama")
+	ErrDiscountOutsideSchedule   = errors.New("diskon hanya berlaku pada hari / jam tertentu")
+)
+
+// Stock take (stock opname) errors
+var (
+	ErrInvalidStockTakeScope   = errors.New("scope stock opname harus all atau category (dengan category_id)")
+	ErrStockTakeNameEmpty      = errors.New("nama stock opname tidak boleh kosong")
+	ErrStockTakeOverlap        = errors.New("masih ada stock opname terbuka untuk produk yang sama")
+	ErrStockTakeNotOpen        = errors.New("stock opname sudah diposting atau dibatalkan")
+	ErrStockTakeEmptyCount     = errors.New("hitungan stock opname minimal 1 item")
+	ErrInvalidCountQuantity    = errors.New("jumlah hitungan tidak valid (mode set: >= 0, mode add: tidak 0, total tidak boleh negatif)")
+	ErrProductOutOfStockTake   = errors.New("produk tidak termasuk cakupan stock opname ini")
+	ErrStockTakeNothingCounted = errors.New("belum ada produk yang dihitung")
+	ErrStockTakeCountOutdated  = errors.New("stok sudah berkurang melebihi hasil hitungan sejak produk dihitung, batalkan dan hitung ulang")
+)
+
+// Stock adjustment errors
+var (
+	ErrStockAdjustmentEmpty        = errors.New("penyesuaian stok minimal 1 item")
+	ErrInvalidAdjustmentReason     = errors.New("reason harus damaged, expired, lost, found, internal_use atau sample")
+	ErrInvalidAdjustmentQuantity   = errors.New("quantity penyesuaian harus lebih dari 0")
+	ErrStockAdjustmentNotPending   = errors.New("penyesuaian stok sudah disetujui atau ditolak")
+	ErrStockAdjustmentRejectReason = errors.New("alasan penolakan wajib diisi")
+)
+
+// Idempotency errors
+var (
+	ErrIdempotencyKeyReused  = errors.New("Idempotency-Key sudah dipakai untuk request dengan isi berbeda")
+	ErrIdempotencyInProgress = errors.New("request dengan Idempotency-Key ini masih diproses")
+	ErrIdempotencyKeyTooLong = errors.New("Idempotency-Key maksimal 255 karakter")
+)
+
+// InsufficientStockError adalah error stok kurang yang menyebutkan produknya
+// errors.Is(err, ErrInsufficientStock) tetap bernilai true
+type InsufficientStockError struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"product_name"`
+	Available   int    `json:"available"`
+	Requested   int    `json:"requested"`
+}
+
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("stok produk %s (ID %d) tidak mencukupi (sisa: %d, diminta: %d)", e.ProductName, e.ProductID, e.Available, e.Requested)
+}
+
+// Unwrap agar errors.Is(err, ErrInsufficientStock) bekerja
+func (e *InsufficientStockError) Unwrap() error {
+	return ErrInsufficientStock
+}
+
+// CreditLimitExceededError adalah kasbon yang ditolak karena melebihi limit pelanggan
+// errors.Is(err, ErrCreditLimitExceeded) tetap bernilai true
+type CreditLimitExceededError struct {
+	CustomerID  int     `json:"customer_id"`
+	CreditLimit float64 `json:"credit_limit"`
+	Outstanding float64 `json:"outstanding"`
+	Requested   float64 `json:"requested"`
+}
+
+func (e *CreditLimitExceededError) Error() string {
+	return fmt.Sprintf("kasbon pelanggan ID %d melebihi limit (limit: %.0f, sisa kasbon: %.0f, kasbon baru: %.0f)", e.CustomerID, e.CreditLimit, e.Outstanding, e.Requested)
+}
+
+// Unwrap agar errors.Is(err, ErrCreditLimitExceeded) bekerja
+func (e *CreditLimitExceededError) Unwrap() error {
+	return ErrCreditLimitExceeded
+}
+
+// DiscountMismatchError adalah diskon dari frontend yang berbeda dengan hasil pricing engine
+// ProductID 0 berarti discount_amount di header transaksi.
+// errors.Is(err, ErrDiscountMismatch) tetap bernilai true
+type DiscountMismatchError struct {
+	ProductID    int     `json:"product_id,omitempty"`
+	ClientAmount float64 `json:"client_amount"`
+	ServerAmount float64 `json:"server_amount"`
+}
+
+func (e *DiscountMismatchError) Error() string {
+	if e.ProductID == 0 {
+		return fmt.Sprintf("diskon transaksi tidak sesuai dengan perhitungan server (dikirim: %.0f, seharusnya: %.0f)", e.ClientAmount, e.ServerAmount)
+	}
+	return fmt.Sprintf("diskon produk ID %d tidak sesuai dengan perhitungan server (dikirim: %.0f, seharusnya: %.0f)", e.ProductID, e.ClientAmount, e.ServerAmount)
+}
+
+// Unwrap agar errors.Is(err, ErrDiscountMismatch) bekerja
+func (e *DiscountMismatchError) Unwrap() error {
+	return ErrDiscountMismatch
+}
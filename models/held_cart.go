@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// HeldCart represents a parked cart that can be resumed later
+// Struct untuk keranjang yang di-park kasir (draft CheckoutRequest)
+type HeldCart struct {
+	ID           int             `json:"id"`
+	UserID       int             `json:"user_id"`
+	Username     string          `json:"username,omitempty"` // Nama kasir (dari JOIN users)
+	Label        *string         `json:"label,omitempty"`
+	Cart         CheckoutRequest `json:"cart"`                 // Draft checkout yang disimpan
+	TotalItems   int             `json:"total_items"`          // Computed: total quantity
+	ReserveStock bool            `json:"reserve_stock"`        // Stok di-reservasi atau tidak
+	ExpiresAt    *time.Time      `json:"expires_at,omitempty"` // Batas reservasi stok
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// HeldCartRequest represents the request body for POST /api/carts
+// Bentuknya sama dengan CheckoutRequest + label & reserve_stock
+type HeldCartRequest struct {
+	CheckoutRequest
+	Label        *string `json:"label"`         // Optional
+	ReserveStock bool    `json:"reserve_stock"` // Optional: reservasi stok sampai expiry
+}
+
+// HeldCartCheckoutRequest represents the request body for POST /api/carts/{id}/checkout
+// Item & diskon diambil dari cart, pembayaran dikirim saat checkout
+type HeldCartCheckoutRequest struct {
+	PaymentAmount float64           `json:"payment_amount"`
+	Payments      []CheckoutPayment `json:"payments"`
+}
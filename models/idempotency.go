@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Status idempotency key
+const (
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+// IdempotencyProcessingTimeout adalah batas waktu key berstatus processing
+// Lewat dari ini key dianggap macet (server crash / panic) dan boleh diambil alih oleh retry dengan request yang sama
+const IdempotencyProcessingTimeout = 2 * time.Minute
+
+// IdempotencyKey represents a stored client idempotency key
+// Struct untuk menyimpan hasil request pertama dari sebuah Idempotency-Key
+type IdempotencyKey struct {
+	ID             int        `json:"id"`
+	Scope          string     `json:"scope"` // checkout / purchase
+	Key            string     `json:"key"`
+	UserID         int        `json:"user_id"`
+	RequestHash    string     `json:"request_hash"`              // SHA-256 method + path + body request
+	Status         string     `json:"status"`                    // processing / completed
+	ResponseStatus *int       `json:"response_status,omitempty"` // HTTP status response pertama
+	ResponseBody   *string    `json:"response_body,omitempty"`   // Body response pertama
+	ResourceID     *int       `json:"resource_id,omitempty"`     // ID transaksi / pembelian
+	CreatedAt      time.Time  `json:"created_at"`
+	LockedAt       time.Time  `json:"locked_at"` // Waktu mulai diproses (diperbarui saat diambil alih)
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Tipe baris buku besar poin loyalitas (points: + menambah saldo, - mengurangi)
+const (
+	PointEntryEarn    = "earn"    // Poin dari transaksi (+)
+	PointEntryRedeem  = "redeem"  // Poin dipakai sebagai pembayaran (-)
+	PointEntryReverse = "reverse" // Poin transaksi ditarik karena void / retur (-)
+	PointEntryRefund  = "refund"  // Poin yang dipakai dikembalikan karena void / retur (+)
+	PointEntryExpire  = "expire"  // Poin kedaluwarsa (-)
+)
+
+// LoyaltySettings holds loyalty points configuration
+// Diisi dari config (LOYALTY_EARN_AMOUNT, LOYALTY_POINT_VALUE, LOYALTY_POINT_EXPIRY_DAYS)
+type LoyaltySettings struct {
+	EarnAmount float64 // Kelipatan belanja (rupiah) untuk 1 poin, 0 = tidak ada poin baru
+	PointValue float64 // Nilai 1 poin (rupiah) saat dipakai membayar
+	ExpiryDays int     // Masa berlaku poin (hari), 0 = tidak kedaluwarsa
+}
+
+// EarnPoints menghitung poin yang didapat dari total transaksi (dibulatkan ke bawah)
+// Contoh EarnAmount 10.000: total 57.500 → 5 poin
+func (s LoyaltySettings) EarnPoints(total float64) int {
+	if s.EarnAmount <= 0 || total <= 0 {
+		return 0
+	}
+	return int(math.Floor(total/s.EarnAmount + 1e-9))
+}
+
+// PointsForAmount mengubah nominal pembayaran poin (rupiah) menjadi jumlah poin
+// ok = false jika nominal bukan kelipatan nilai 1 poin
+func (s LoyaltySettings) PointsForAmount(amount float64) (int, bool) {
+	if s.PointValue <= 0 || amount <= 0 {
+		return 0, false
+	}
+	points := amount / s.PointValue
+	rounded := math.Round(points)
+	if math.Abs(points-rounded) > 1e-6 {
+		return 0, false
+	}
+	return int(rounded), true
+}
+
+// ExpiresAt menghitung tanggal kedaluwarsa poin yang didapat pada earnedAt (nil = tidak kedaluwarsa)
+func (s LoyaltySettings) ExpiresAt(earnedAt time.Time) *time.Time {
+	if s.ExpiryDays <= 0 {
+		return nil
+	}
+	expiresAt := earnedAt.AddDate(0, 0, s.ExpiryDays)
+	return &expiresAt
+}
+
+// PointLedgerEntry merepresentasikan 1 baris tabel loyalty_points_ledger
+type PointLedgerEntry struct {
+	ID            int        `json:"id"`
+	CustomerID    int        `json:"customer_id"`
+	Type          string     `json:"type"`   // earn / redeem / reverse / refund / expire
+	Points        int        `json:"points"` // + menambah saldo, - mengurangi
+	TransactionID *int       `json:"transaction_id,omitempty"`
+	SalesReturnID *int       `json:"sales_return_id,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"` // Hanya untuk earn
+	Note          *string    `json:"note,omitempty"`
+	CreatedBy     *int       `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// LoyaltySummary adalah saldo poin 1 pelanggan
+// Response untuk GET /api/customers/{id}/points
+type LoyaltySummary struct {
+	CustomerID       int                `json:"customer_id"`
+	CustomerName     string             `json:"customer_name"`
+	Balance          int                `json:"balance"`       // Saldo poin (kedaluwarsa sudah dipotong)
+	BalanceValue     float64            `json:"balance_value"` // Saldo × nilai 1 poin (rupiah)
+	PointValue       float64            `json:"point_value"`
+	EarnAmount       float64            `json:"earn_amount"` // Belanja per 1 poin (0 = program nonaktif)
+	NextExpiry       *time.Time         `json:"next_expiry,omitempty"`
+	NextExpiryPoints int                `json:"next_expiry_points,omitempty"` // Poin yang akan kedaluwarsa pada NextExpiry
+	Entries          []PointLedgerEntry `json:"entries"`                      // Riwayat poin (terbaru dulu)
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// OverrideApprovalRequest represents the request body for POST /api/overrides/approve
+// Admin memasukkan kredensialnya di perangkat kasir untuk menyetujui harga khusus / diskon manual
+// Persetujuan berlaku untuk 1 baris: product_id + override_price dan / atau max_discount_amount
+type OverrideApprovalRequest struct {
+	Username          string   `json:"username"`                      // Username admin yang menyetujui
+	Password          string   `json:"password"`                      // Password / PIN admin
+	Reason            string   `json:"reason"`                        // Alasan override (wajib, disimpan di detail transaksi)
+	ProductID         int      `json:"product_id"`                    // Produk yang di-override (wajib)
+	OverridePrice     *float64 `json:"override_price,omitempty"`      // Harga satuan khusus yang disetujui
+	MaxDiscountAmount *float64 `json:"max_discount_amount,omitempty"` // Batas diskon manual baris (rupiah)
+}
+
+// OverrideApproval represents an issued approval token
+// Token dikirim sebagai approval_token di baris checkout yang di-override (sekali pakai)
+type OverrideApproval struct {
+	Token             string    `json:"approval_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	ApprovedBy        int       `json:"approved_by"`
+	ApproverName      string    `json:"approver_name"`
+	Reason            string    `json:"reason"`
+	ProductID         int       `json:"product_id"`
+	OverridePrice     *float64  `json:"override_price,omitempty"`
+	MaxDiscountAmount *float64  `json:"max_discount_amount,omitempty"`
+}
+
+// OverrideLine represents one overridden transaction line in the override report
+type OverrideLine struct {
+	TransactionID       int       `json:"transaction_id"`
+	TransactionDetailID int       `json:"transaction_detail_id"`
+	CreatedAt           time.Time `json:"created_at"`
+	ProductID           int       `json:"product_id"`
+	ProductName         string    `json:"product_name"`
+	Quantity            int       `json:"quantity"`
+	OriginalPrice       float64   `json:"original_price"`  // Harga database saat transaksi
+	Price               float64   `json:"price"`           // Harga yang dipakai
+	DiscountAmount      float64   `json:"discount_amount"` // Diskon manual baris
+	Subtotal            float64   `json:"subtotal"`
+	PriceReduction      float64   `json:"price_reduction"` // (harga asli × qty) - subtotal
+	ApprovedBy          *int      `json:"approved_by,omitempty"`
+	ApproverName        string    `json:"approver_name,omitempty"`
+	Reason              string    `json:"reason"`
+	Voided              bool      `json:"voided"` // Transaksi sudah dibatalkan
+}
+
+// OverrideCashierSummary groups override lines per cashier
+type OverrideCashierSummary struct {
+	UserID              *int           `json:"user_id,omitempty"`
+	Username            string         `json:"username"`
+	OverrideCount       int            `json:"override_count"`
+	TotalPriceReduction float64        `json:"total_price_reduction"` // Tidak termasuk transaksi void
+	Lines               []OverrideLine `json:"lines"`
+}
+
+// OverrideReport represents all approved overrides in a period, per cashier
+type OverrideReport struct {
+	StartDate           time.Time                `json:"start_date"`
+	EndDate             time.Time                `json:"end_date"`
+	OverrideCount       int                      `json:"override_count"`
+	TotalPriceReduction float64                  `json:"total_price_reduction"`
+	Cashiers            []OverrideCashierSummary `json:"cashiers"`
+}
@@ -0,0 +1,44 @@
+package models
+
+// CheckoutPreview represents server-side pricing of a cart
+// Response POST /api/checkout/preview — hasilnya sama persis dengan yang akan disimpan saat checkout
+type CheckoutPreview struct {
+	Items               []PricingLine         `json:"items"`
+	AppliedDiscounts    []TransactionDiscount `json:"applied_discounts"`      // Kombinasi diskon per baris hasil aturan stacking
+	GrossAmount         float64               `json:"gross_amount"`           // Harga × qty sebelum diskon
+	ItemDiscount        float64               `json:"item_discount"`          // Total diskon produk/kategori + promosi
+	PromotionDiscount   float64               `json:"promotion_discount"`     // Bagian item_discount dari promosi
+	Subtotal            float64               `json:"subtotal"`               // Setelah diskon item, sebelum diskon global
+	DiscountID          *int                  `json:"discount_id,omitempty"`  // Diskon global yang dipakai
+	VoucherID           *int                  `json:"voucher_id,omitempty"`   // Voucher yang dipakai (diskon global dari voucher_code)
+	VoucherCode         string                `json:"voucher_code,omitempty"` // Kode voucher (sudah dinormalisasi)
+	GlobalDiscount      float64               `json:"global_discount"`
+	TotalDiscount       float64               `json:"total_discount"` // Nilai yang dikirim sebagai discount_amount saat checkout
+	TaxAmount           float64               `json:"tax_amount"`
+	TaxIncludedAmount   float64               `json:"tax_included_amount"`
+	ServiceChargeAmount float64               `json:"service_charge_amount"`
+	TotalAmount         float64               `json:"total_amount"` // Total yang harus dibayar
+	CashTotal           float64               `json:"cash_total"`   // Total jika dibayar penuh tunai (setelah pembulatan)
+}
+
+// PricingLine represents the engine's pricing of one cart line
+type PricingLine struct {
+	ProductID           int     `json:"product_id"`
+	ProductName         string  `json:"product_name"`
+	Quantity            int     `json:"quantity"`
+	Price               float64 `json:"price"` // Harga satuan dari database
+	DiscountType        string  `json:"discount_type,omitempty"`
+	DiscountValue       float64 `json:"discount_value,omitempty"`
+	DiscountAmount      float64 `json:"discount_amount"` // Total potongan baris ini (termasuk promosi)
+	PromotionID         *int    `json:"promotion_id,omitempty"`
+	PromotionType       string  `json:"promotion_type,omitempty"`
+	PromotionName       string  `json:"promotion_name,omitempty"`
+	PromotionAmount     float64 `json:"promotion_amount,omitempty"`      // Potongan dari promosi
+	OrderDiscountAmount float64 `json:"order_discount_amount,omitempty"` // Porsi diskon transaksi di baris ini
+	Subtotal            float64 `json:"subtotal"`                        // (harga × qty) - diskon
+	TaxClassID          *int    `json:"tax_class_id,omitempty"`
+	TaxRate             float64 `json:"tax_rate"`
+	TaxInclusive        bool    `json:"tax_inclusive"`
+	TaxAmount           float64 `json:"tax_amount"`
+	ServiceChargeAmount float64 `json:"service_charge_amount"`
+}
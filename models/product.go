@@ -5,13 +5,14 @@ type Product struct {
 	ID                   int       `json:"id" db:"id"`
 	Nama                 string    `json:"nama" db:"nama"`
 	Harga                float64   `json:"harga" db:"harga"`                     // Harga jual
-	HargaBeli            *float64  `json:"harga_beli,omitempty" db:"harga_beli"` // Harga beli/modal (nullable)
+	HargaBeli            *float64  `json:"harga_beli,omitempty" db:"harga_beli"` // Harga pokok per unit sesuai COSTING_METHOD (nullable)
 	Stok                 int       `json:"stok" db:"stok"`
 	Barcode              *string   `json:"barcode,omitempty" db:"barcode"`                               // Barcode produk (nullable, unique)
 	CategoryID           *int      `json:"category_id,omitempty" db:"category_id"`                       // Foreign key ke categories (nullable)
 	DefaultDiscountType  *string   `json:"default_discount_type,omitempty" db:"default_discount_type"`   // "percentage" atau "fixed" (nullable)
 	DefaultDiscountValue *float64  `json:"default_discount_value,omitempty" db:"default_discount_value"` // Nilai diskon default (nullable)
 	IsFeatured           bool      `json:"is_featured" db:"is_featured"`                                 // Flag fitur unggulan
+	TaxClassID           *int      `json:"tax_class_id,omitempty" db:"tax_class_id"`                     // Kelas pajak (NULL = ikut kategori / default)
 	CreatedBy            *int      `json:"created_by,omitempty" db:"created_by"`                         // User ID yang menambahkan produk
 	Category             *Category `json:"category,omitempty" db:"-"`                                    // Untuk hasil JOIN (tidak disimpan di DB)
 	Margin               *float64  `json:"margin,omitempty" db:"-"`                                      // Margin keuntungan % (calculated field)
@@ -0,0 +1,35 @@
+package models
+
+// Format struk yang didukung GET /api/transactions/{id}/receipt
+const (
+	ReceiptFormatESCPOS = "escpos" // Byte command untuk printer thermal
+	ReceiptFormatText   = "text"   // Plain text (monospace)
+	ReceiptFormatHTML   = "html"   // HTML untuk preview / print dari browser
+)
+
+// IsValidReceiptFormat mengecek apakah format struk dikenali
+func IsValidReceiptFormat(format string) bool {
+	switch format {
+	case ReceiptFormatESCPOS, ReceiptFormatText, ReceiptFormatHTML:
+		return true
+	}
+	return false
+}
+
+// ReceiptSettings holds store identity and paper size used to render receipts
+// Diisi dari config (STORE_NAME, STORE_ADDRESS, STORE_FOOTER, RECEIPT_PAPER_WIDTH)
+type ReceiptSettings struct {
+	StoreName    string
+	StoreAddress string
+	Footer       string
+	PaperWidth   int // Lebar kertas dalam mm: 58 atau 80
+}
+
+// Columns mengembalikan jumlah karakter per baris (font A) untuk lebar kertas
+// 58mm = 32 kolom, 80mm = 48 kolom
+func (s ReceiptSettings) Columns() int {
+	if s.PaperWidth == 80 {
+		return 48
+	}
+	return 32
+}
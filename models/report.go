@@ -5,16 +5,29 @@ import "time"
 // SalesReport represents daily sales summary
 // Struct untuk laporan penjualan harian
 type SalesReport struct {
-	TotalRevenue     float64      `json:"total_revenue"`
-	TotalTransaksi   int          `json:"total_transaksi"`
-	TotalItemsSold   int          `json:"total_items_sold"`  // Total items terjual
-	TotalProfit      float64      `json:"total_profit"`      // Total keuntungan kotor (revenue - modal barang terjual)
-	TotalPengeluaran float64      `json:"total_pengeluaran"` // Total pembelian/pengadaan barang
-	TotalPembelian   int          `json:"total_pembelian"`   // Jumlah transaksi pembelian
-	TotalPayroll     float64      `json:"total_payroll"`     // Total gaji karyawan dibayarkan
-	TotalExpenses    float64      `json:"total_expenses"`    // Total pengeluaran operasional
-	LabaBersih       float64      `json:"laba_bersih"`       // Profit - Payroll - Expenses
-	ProdukTerlaris   []TopProduct `json:"produk_terlaris"`   // Array semua produk terjual
+	TotalRevenue     float64              `json:"total_revenue"`
+	TotalTransaksi   int                  `json:"total_transaksi"`
+	TotalItemsSold   int                  `json:"total_items_sold"`  // Total items terjual
+	TotalProfit      float64              `json:"total_profit"`      // Total keuntungan kotor (revenue - modal barang terjual)
+	TotalReturns     float64              `json:"total_returns"`     // Total refund retur penjualan (sudah dikurangkan dari revenue)
+	TotalTax         float64              `json:"total_tax"`         // PPN terutang (termasuk di revenue, tidak dihitung sebagai profit)
+	TotalRounding    float64              `json:"total_rounding"`    // Selisih pembulatan tunai (di luar revenue, termasuk di payment_breakdown cash)
+	TotalPengeluaran float64              `json:"total_pengeluaran"` // Total pembelian/pengadaan barang
+	TotalPembelian   int                  `json:"total_pembelian"`   // Jumlah transaksi pembelian
+	TotalPayroll     float64              `json:"total_payroll"`     // Total gaji karyawan dibayarkan
+	TotalExpenses    float64              `json:"total_expenses"`    // Total pengeluaran operasional
+	TotalWriteOff    float64              `json:"total_write_off"`   // Nilai barang rusak / hilang / dll (harga beli) dikurangi barang ditemukan
+	LabaBersih       float64              `json:"laba_bersih"`       // Profit - WriteOff - Payroll - Expenses
+	ProdukTerlaris   []TopProduct         `json:"produk_terlaris"`   // Array semua produk terjual
+	PaymentBreakdown []PaymentMethodTotal `json:"payment_breakdown"` // Rincian penjualan per metode pembayaran
+}
+
+// PaymentMethodTotal represents total payment received per method
+// Struct untuk rincian penerimaan per metode pembayaran (cash, qris, debit, transfer)
+type PaymentMethodTotal struct {
+	Method           string  `json:"method"`
+	Amount           float64 `json:"amount"`            // Total nominal diterima (cash sudah dikurangi refund retur)
+	TransactionCount int     `json:"transaction_count"` // Jumlah transaksi yang memakai metode ini
 }
 
 // TopProduct represents the best selling product
@@ -0,0 +1,45 @@
+package models
+
+import "math"
+
+// Mode pembulatan tunai
+const (
+	RoundingModeHalf = "half" // Ke kelipatan terdekat (setengah ke atas)
+	RoundingModeUp   = "up"   // Selalu ke atas
+	RoundingModeDown = "down" // Selalu ke bawah
+)
+
+// IsValidRoundingMode mengecek apakah mode pembulatan dikenali
+func IsValidRoundingMode(mode string) bool {
+	switch mode {
+	case RoundingModeHalf, RoundingModeUp, RoundingModeDown:
+		return true
+	}
+	return false
+}
+
+// CashRoundingSettings holds cash rounding configuration for checkout
+// Diisi dari config (CASH_ROUNDING_UNIT, CASH_ROUNDING_MODE)
+type CashRoundingSettings struct {
+	Unit float64 // Kelipatan pembulatan, misal 100 atau 500 (0 = nonaktif)
+	Mode string  // half, up, down
+}
+
+// Round membulatkan nominal tunai ke kelipatan Unit sesuai Mode
+// Contoh Unit 100: half 13.437 → 13.400, up 13.437 → 13.500, down 13.437 → 13.400
+func (s CashRoundingSettings) Round(amount float64) float64 {
+	if s.Unit <= 0 {
+		return amount
+	}
+	// Pembulatan kecil dulu agar sisa floating point (misal 13499.999999) tidak ikut dihitung
+	units := math.Round(amount/s.Unit*1e6) / 1e6
+	switch s.Mode {
+	case RoundingModeUp:
+		units = math.Ceil(units)
+	case RoundingModeDown:
+		units = math.Floor(units)
+	default:
+		units = math.Floor(units + 0.5)
+	}
+	return units * s.Unit
+}
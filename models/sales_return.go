@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// SalesReturn represents a sales return header (retur penjualan)
+// Struct ini menyimpan informasi header setiap retur terhadap 1 transaksi
+type SalesReturn struct {
+	ID            int               `json:"id"`
+	TransactionID int               `json:"transaction_id"`
+	TotalRefund   float64           `json:"total_refund"`         // Total uang kembali ke customer
+	CreditRefund  float64           `json:"credit_refund"`        // Bagian refund yang memotong kasbon
+	PointsRefund  float64           `json:"points_refund"`        // Bagian refund yang dikembalikan sebagai poin (rupiah); sisanya tunai
+	Reason        *string           `json:"reason,omitempty"`     // Alasan retur (optional)
+	CreatedBy     *int              `json:"created_by,omitempty"` // User yang memproses retur
+	Username      string            `json:"username,omitempty"`   // Nama user (dari JOIN users)
+	ShiftID       *int              `json:"shift_id,omitempty"`   // Shift kasir saat retur diproses
+	CreatedAt     time.Time         `json:"created_at"`
+	Items         []SalesReturnItem `json:"items,omitempty"`
+}
+
+// SalesReturnItem represents a returned line item
+// Struct ini menyimpan detail setiap item yang diretur
+type SalesReturnItem struct {
+	ID                  int       `json:"id"`
+	ReturnID            int       `json:"return_id"`
+	TransactionDetailID int       `json:"transaction_detail_id"`
+	ProductID           int       `json:"product_id"`
+	ProductName         string    `json:"product_name,omitempty"` // Nama produk (dari JOIN)
+	Quantity            int       `json:"quantity"`
+	RefundAmount        float64   `json:"refund_amount"` // Uang kembali untuk baris ini
+	TaxAmount           float64   `json:"tax_amount"`    // Bagian PPN dari refund (mengurangi PPN terutang)
+	HargaBeli           float64   `json:"harga_beli"`    // Snapshot HPP per unit
+	Restock             bool      `json:"restock"`       // true = masuk stok, false = write-off
+	CreatedAt           time.Time `json:"created_at,omitempty"`
+}
+
+// SalesReturnRequest represents the request body for POST /api/transactions/{id}/returns
+type SalesReturnRequest struct {
+	Reason *string                  `json:"reason"` // Optional
+	Items  []SalesReturnItemRequest `json:"items"`  // Wajib, minimal 1 item
+	// CreatedBy diisi dari context auth
+	CreatedBy int `json:"-"`
+}
+
+// SalesReturnItemRequest represents a line in the return request
+type SalesReturnItemRequest struct {
+	TransactionDetailID int   `json:"transaction_detail_id"` // ID baris transaction_details yang diretur
+	Quantity            int   `json:"quantity"`              // Jumlah diretur (<= sisa qty yang belum diretur)
+	Restock             *bool `json:"restock"`               // Default true; false = barang rusak (write-off)
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// CashierShift represents a cashier register session
+// Struct untuk shift kasir (buka laci dengan modal awal, tutup dengan hitungan uang fisik)
+type CashierShift struct {
+	ID            int                 `json:"id"`
+	UserID        int                 `json:"user_id"`
+	Username      string              `json:"username,omitempty"` // Nama kasir (dari JOIN users)
+	OpeningFloat  float64             `json:"opening_float"`      // Modal awal di laci
+	OpenedAt      time.Time           `json:"opened_at"`
+	ClosedAt      *time.Time          `json:"closed_at,omitempty"`     // NULL = masih terbuka
+	ExpectedCash  *float64            `json:"expected_cash,omitempty"` // Diisi saat tutup shift
+	CountedCash   *float64            `json:"counted_cash,omitempty"`  // Uang fisik yang dihitung
+	CashVariance  *float64            `json:"cash_variance,omitempty"` // counted - expected
+	Denominations []ShiftDenomination `json:"denominations,omitempty"` // Rincian hitungan per pecahan
+	ClosingNote   *string             `json:"closing_note,omitempty"`
+}
+
+// ShiftDenomination represents counted cash per denomination
+// Contoh: {"value": 100000, "count": 3} = 3 lembar Rp 100.000
+type ShiftDenomination struct {
+	Value float64 `json:"value"`
+	Count int     `json:"count"`
+}
+
+// OpenShiftRequest represents the request body for POST /api/shifts/open
+type OpenShiftRequest struct {
+	OpeningFloat float64 `json:"opening_float"`
+}
+
+// CloseShiftRequest represents the request body for POST /api/shifts/close
+// Isi denominations (disarankan) atau counted_cash langsung
+type CloseShiftRequest struct {
+	Denominations []ShiftDenomination `json:"denominations"`
+	CountedCash   *float64            `json:"counted_cash"`
+	Note          *string             `json:"note"`
+}
+
+// ShiftReport represents reconciliation summary of a shift
+// Expected cash = modal awal + penjualan tunai - refund tunai + cicilan kasbon tunai + pay in - pay out - setor brankas
+type ShiftReport struct {
+	Shift            CashierShift         `json:"shift"`
+	TransactionCount int                  `json:"transaction_count"`
+	TotalSales       float64              `json:"total_sales"`       // Total penjualan (semua metode)
+	CashSales        float64              `json:"cash_sales"`        // Penjualan tunai (setelah kembalian)
+	TotalRounding    float64              `json:"total_rounding"`    // Selisih pembulatan tunai (sudah termasuk di cash_sales)
+	CashRefunds      float64              `json:"cash_refunds"`      // Refund retur & void (tunai)
+	VoidRefunds      float64              `json:"void_refunds"`      // Porsi tunai transaksi yang di-void di shift ini (sudah termasuk di cash_refunds)
+	CashRepayments   float64              `json:"cash_repayments"`   // Cicilan kasbon pelanggan yang dibayar tunai
+	PayIns           float64              `json:"pay_ins"`           // Uang masuk laci di luar penjualan
+	PayOuts          float64              `json:"pay_outs"`          // Kas kecil keluar dari laci
+	Drops            float64              `json:"drops"`             // Setor ke brankas
+	CashMovements    []CashMovement       `json:"cash_movements"`    // Rincian pergerakan kas laci
+	PaymentBreakdown []PaymentMethodTotal `json:"payment_breakdown"` // Rincian per metode pembayaran
+	ExpectedCash     float64              `json:"expected_cash"`
+	CountedCash      *float64             `json:"counted_cash,omitempty"`  // NULL jika shift belum ditutup
+	CashVariance     *float64             `json:"cash_variance,omitempty"` // NULL jika shift belum ditutup
+}
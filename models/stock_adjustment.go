@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+// Alasan (reason code) penyesuaian stok manual
+const (
+	AdjustmentReasonDamaged     = "damaged"      // Rusak
+	AdjustmentReasonExpired     = "expired"      // Kedaluwarsa
+	AdjustmentReasonLost        = "lost"         // Hilang
+	AdjustmentReasonFound       = "found"        // Ditemukan (stok bertambah)
+	AdjustmentReasonInternalUse = "internal_use" // Dipakai sendiri / operasional toko
+	AdjustmentReasonSample      = "sample"       // Sampel / tester
+
+	StockAdjustmentStatusPending  = "pending"  // Menunggu persetujuan admin, stok belum berubah
+	StockAdjustmentStatusApproved = "approved" // Stok sudah disesuaikan
+	StockAdjustmentStatusRejected = "rejected"
+)
+
+// AdjustmentDirection mengembalikan arah perubahan stok untuk reason:
+// +1 = stok bertambah (found), -1 = write-off, 0 = reason tidak dikenal
+func AdjustmentDirection(reason string) int {
+	switch reason {
+	case AdjustmentReasonFound:
+		return 1
+	case AdjustmentReasonDamaged, AdjustmentReasonExpired, AdjustmentReasonLost,
+		AdjustmentReasonInternalUse, AdjustmentReasonSample:
+		return -1
+	}
+	return 0
+}
+
+// StockAdjustment represents a manual stock adjustment document (multi-line)
+type StockAdjustment struct {
+	ID            int                   `json:"id"`
+	Status        string                `json:"status"` // pending / approved / rejected
+	Notes         *string               `json:"notes,omitempty"`
+	WriteOffValue float64               `json:"write_off_value"` // Nilai barang keluar (harga beli)
+	FoundValue    float64               `json:"found_value"`     // Nilai barang ditemukan (harga beli)
+	CreatedBy     *int                  `json:"created_by,omitempty"`
+	Username      string                `json:"username,omitempty"` // Pembuat (dari JOIN users)
+	CreatedAt     time.Time             `json:"created_at"`
+	ApprovedBy    *int                  `json:"approved_by,omitempty"`
+	ApprovedAt    *time.Time            `json:"approved_at,omitempty"`
+	RejectedBy    *int                  `json:"rejected_by,omitempty"`
+	RejectedAt    *time.Time            `json:"rejected_at,omitempty"`
+	RejectReason  *string               `json:"reject_reason,omitempty"`
+	Items         []StockAdjustmentItem `json:"items,omitempty"`
+}
+
+// StockAdjustmentItem adalah 1 baris penyesuaian: produk, reason & jumlah
+type StockAdjustmentItem struct {
+	ID          int     `json:"id"`
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Reason      string  `json:"reason"`
+	Quantity    int     `json:"quantity"`   // Selalu positif
+	Change      int     `json:"change"`     // Perubahan stok (+ found, - write-off)
+	HargaBeli   float64 `json:"harga_beli"` // Harga pokok per unit (write-off: dinilai ulang saat disetujui)
+	Value       float64 `json:"value"`      // quantity × harga_beli
+	Note        *string `json:"note,omitempty"`
+	StockBefore *int    `json:"stock_before,omitempty"` // Diisi saat disetujui
+	StockAfter  *int    `json:"stock_after,omitempty"`
+}
+
+// StockAdjustmentLine adalah 1 baris request penyesuaian
+type StockAdjustmentLine struct {
+	ProductID int     `json:"product_id"`
+	Reason    string  `json:"reason"`   // damaged / expired / lost / found / internal_use / sample
+	Quantity  int     `json:"quantity"` // > 0, arah stok ditentukan reason
+	Note      *string `json:"note"`
+}
+
+// StockAdjustmentRequest represents the request body for POST /api/stock-adjustments
+type StockAdjustmentRequest struct {
+	Notes *string               `json:"notes"`
+	Items []StockAdjustmentLine `json:"items"`
+}
+
+// StockAdjustmentRejectRequest represents the request body for POST /api/stock-adjustments/{id}/reject
+type StockAdjustmentRejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+// WriteOffReasonTotal adalah total penyesuaian stok per reason dalam periode
+type WriteOffReasonTotal struct {
+	Reason   string  `json:"reason"`
+	Lines    int     `json:"lines"`
+	Quantity int     `json:"quantity"`
+	Value    float64 `json:"value"` // Harga beli
+}
+
+// WriteOffProductTotal adalah total write-off 1 produk dalam periode
+type WriteOffProductTotal struct {
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	Value       float64 `json:"value"`
+}
+
+// WriteOffReport represents approved stock adjustments in a period valued at harga_beli
+// Response untuk GET /api/report/write-offs
+type WriteOffReport struct {
+	StartDate     time.Time              `json:"start_date"`
+	EndDate       time.Time              `json:"end_date"`
+	Adjustments   int                    `json:"adjustments"`     // Dokumen disetujui dalam periode
+	WriteOffValue float64                `json:"write_off_value"` // Rusak, kedaluwarsa, hilang, pemakaian internal, sampel
+	FoundValue    float64                `json:"found_value"`
+	TotalWriteOff float64                `json:"total_write_off"` // WriteOffValue - FoundValue (mengurangi laba bersih)
+	Reasons       []WriteOffReasonTotal  `json:"reasons"`
+	Products      []WriteOffProductTotal `json:"products"` // Hanya write-off, urut dari nilai terbesar
+}
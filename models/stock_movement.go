@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Tipe pergerakan stok (stock_movements)
+const (
+	StockMovementSale       = "sale"       // Penjualan (reference_id = transaction_id)
+	StockMovementPurchase   = "purchase"   // Pembelian / restok (reference_id = purchase_id)
+	StockMovementAdjustment = "adjustment" // Penyesuaian manual / stok awal
+	StockMovementReturn     = "return"     // Retur penjualan yang masuk stok lagi (reference_id = sales_return_id)
+	StockMovementVoid       = "void"       // Pembatalan transaksi (reference_id = transaction_id)
+	StockMovementTransfer   = "transfer"   // Pindah stok antar lokasi
+	StockMovementStockTake  = "stock_take" // Penyesuaian hasil stock opname (reference_id = stock_take_id)
+)
+
+// StockMovement represents one change to products.stok
+// Ledger append-only: setiap perubahan stok dicatat dalam database transaction yang sama
+type StockMovement struct {
+	ID          int       `json:"id"`
+	ProductID   int       `json:"product_id"`
+	ProductName string    `json:"product_name,omitempty"` // Nama produk (dari JOIN products)
+	Type        string    `json:"type"`                   // sale / purchase / adjustment / return / void / transfer / stock_take
+	Quantity    int       `json:"quantity"`               // Perubahan stok (+ masuk, - keluar)
+	StockBefore int       `json:"stock_before"`
+	StockAfter  int       `json:"stock_after"`
+	ReferenceID *int      `json:"reference_id,omitempty"` // ID transaksi / pembelian / retur sesuai type
+	Note        *string   `json:"note,omitempty"`
+	CreatedBy   *int      `json:"created_by,omitempty"`
+	Username    string    `json:"username,omitempty"` // Nama user (dari JOIN users)
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// StockLedgerCheck adalah hasil pengecekan stok produk terhadap ledger stock_movements
+// Response GET /api/produk/stock-check
+type StockLedgerCheck struct {
+	ProductID      int    `json:"product_id"`
+	ProductName    string `json:"product_name"`
+	Stock          int    `json:"stock"`            // products.stok saat ini
+	LedgerStock    int    `json:"ledger_stock"`     // SUM(quantity) dari ledger
+	LastStockAfter *int   `json:"last_stock_after"` // stock_after pergerakan terakhir (nil = belum ada pergerakan)
+	Movements      int    `json:"movements"`
+	BrokenChain    int    `json:"broken_chain"` // Pergerakan yang stock_before-nya tidak sama dengan stock_after sebelumnya
+	Difference     int    `json:"difference"`   // Stock - LedgerStock
+	Consistent     bool   `json:"consistent"`
+}
+
+// StockLedgerReport represents the result of rebuilding stock from the ledger
+type StockLedgerReport struct {
+	CheckedProducts int                `json:"checked_products"`
+	Mismatches      int                `json:"mismatches"`
+	Products        []StockLedgerCheck `json:"products"` // Hanya produk yang tidak konsisten (kecuali ?all=true)
+}
@@ -0,0 +1,113 @@
+package models
+
+import "time"
+
+// Cakupan & status stock opname
+const (
+	StockTakeScopeAll      = "all"      // Seluruh produk toko
+	StockTakeScopeCategory = "category" // Produk 1 kategori
+
+	StockTakeStatusOpen      = "open"
+	StockTakeStatusPosted    = "posted"
+	StockTakeStatusCancelled = "cancelled"
+
+	StockCountModeAdd = "add" // Hitungan ditambahkan ke total (scan per rak / batch)
+	StockCountModeSet = "set" // Total hitungan diganti (koreksi)
+)
+
+// StockTake represents a stock opname session
+type StockTake struct {
+	ID           int             `json:"id"`
+	Name         string          `json:"name"`
+	Scope        string          `json:"scope"` // all / category
+	CategoryID   *int            `json:"category_id,omitempty"`
+	CategoryName string          `json:"category_name,omitempty"` // Dari JOIN categories
+	Status       string          `json:"status"`                  // open / posted / cancelled
+	Notes        *string         `json:"notes,omitempty"`
+	CreatedBy    *int            `json:"created_by,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	PostedBy     *int            `json:"posted_by,omitempty"`
+	PostedAt     *time.Time      `json:"posted_at,omitempty"`
+	CancelledAt  *time.Time      `json:"cancelled_at,omitempty"`
+	Summary      *StockTakeTotal `json:"summary,omitempty"`
+	Items        []StockTakeItem `json:"items,omitempty"`
+}
+
+// StockTakeItem adalah hitungan & selisih 1 produk
+// Sesi open: system_stock = products.stok saat ini; sesi posted: snapshot saat posting
+type StockTakeItem struct {
+	ProductID     int     `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	Barcode       *string `json:"barcode,omitempty"`
+	CountedQty    *int    `json:"counted_qty"`    // nil = belum dihitung
+	SystemStock   int     `json:"system_stock"`   // Stok sistem saat produk pertama kali dihitung (belum dihitung: stok saat ini)
+	Variance      int     `json:"variance"`       // counted_qty - system_stock (- = susut / hilang)
+	HargaBeli     float64 `json:"harga_beli"`     // Nilai selisih dihitung dengan harga beli
+	VarianceValue float64 `json:"variance_value"` // variance × harga_beli
+}
+
+// StockTakeTotal adalah ringkasan selisih 1 sesi stock opname
+type StockTakeTotal struct {
+	ProductsInScope   int     `json:"products_in_scope"`
+	CountedProducts   int     `json:"counted_products"`
+	UncountedProducts int     `json:"uncounted_products"`
+	ShrinkageQty      int     `json:"shrinkage_qty"`   // Total unit kurang (selisih negatif)
+	ShrinkageValue    float64 `json:"shrinkage_value"` // Nilai unit kurang (harga beli, positif)
+	SurplusQty        int     `json:"surplus_qty"`     // Total unit lebih (selisih positif)
+	SurplusValue      float64 `json:"surplus_value"`
+	NetVarianceValue  float64 `json:"net_variance_value"` // SurplusValue - ShrinkageValue
+}
+
+// StockTakeRequest represents the request body for POST /api/stock-takes
+type StockTakeRequest struct {
+	Name       string  `json:"name"`
+	Scope      string  `json:"scope"`       // all (default) / category
+	CategoryID *int    `json:"category_id"` // Wajib jika scope = category
+	Notes      *string `json:"notes"`
+}
+
+// StockCountLine adalah 1 baris hasil scan: barcode atau product_id
+type StockCountLine struct {
+	Barcode   string `json:"barcode"`
+	ProductID *int   `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// StockCountRequest represents the request body for POST /api/stock-takes/{id}/counts
+// Dipanggil berkali-kali (per rak / batch scan)
+type StockCountRequest struct {
+	Mode  string           `json:"mode"` // add (default) / set
+	Items []StockCountLine `json:"items"`
+}
+
+// StockTakePostRequest represents the request body for POST /api/stock-takes/{id}/post
+type StockTakePostRequest struct {
+	UncountedAsZero bool `json:"uncounted_as_zero"` // true = produk yang belum dihitung dianggap stok 0
+}
+
+// ShrinkageReportLine adalah ringkasan selisih stock opname 1 produk dalam periode
+type ShrinkageReportLine struct {
+	ProductID      int     `json:"product_id"`
+	ProductName    string  `json:"product_name"`
+	CategoryName   string  `json:"category_name,omitempty"`
+	StockTakes     int     `json:"stock_takes"` // Jumlah sesi dengan selisih untuk produk ini
+	ShrinkageQty   int     `json:"shrinkage_qty"`
+	ShrinkageValue float64 `json:"shrinkage_value"`
+	SurplusQty     int     `json:"surplus_qty"`
+	SurplusValue   float64 `json:"surplus_value"`
+	NetValue       float64 `json:"net_value"` // SurplusValue - ShrinkageValue
+}
+
+// ShrinkageReport represents stock opname variances posted in a period
+// Response untuk GET /api/report/shrinkage
+type ShrinkageReport struct {
+	StartDate      time.Time             `json:"start_date"`
+	EndDate        time.Time             `json:"end_date"`
+	StockTakes     int                   `json:"stock_takes"` // Sesi yang diposting dalam periode
+	ShrinkageQty   int                   `json:"shrinkage_qty"`
+	ShrinkageValue float64               `json:"shrinkage_value"`
+	SurplusQty     int                   `json:"surplus_qty"`
+	SurplusValue   float64               `json:"surplus_value"`
+	NetValue       float64               `json:"net_value"`
+	Products       []ShrinkageReportLine `json:"products"` // Urut dari nilai susut terbesar
+}
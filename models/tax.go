@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// TaxClass represents a tax rule that can be assigned to products or categories
+// Struct untuk kelas pajak (misal PPN 11% exclusive, PPN 11% inclusive, Bebas PPN)
+type TaxClass struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Rate        float64   `json:"rate"`         // Tarif dalam persen (11 = 11%)
+	IsInclusive bool      `json:"is_inclusive"` // true = harga jual sudah termasuk pajak
+	IsExempt    bool      `json:"is_exempt"`    // true = dibebaskan dari pajak (rate diabaikan)
+	IsDefault   bool      `json:"is_default"`   // Dipakai jika produk & kategori tidak punya kelas pajak
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Validate memvalidasi data kelas pajak sebelum disimpan
+func (t *TaxClass) Validate() error {
+	if t.Name == "" {
+		return ErrTaxClassNameEmpty
+	}
+	if t.Rate < 0 || t.Rate > 100 {
+		return ErrInvalidTaxRate
+	}
+	return nil
+}
+
+// ServiceChargeSettings holds service charge configuration for checkout
+// Diisi dari config (SERVICE_CHARGE_PERCENT, SERVICE_CHARGE_TAXABLE)
+type ServiceChargeSettings struct {
+	Percent float64 // 0 = tidak ada service charge
+	Taxable bool    // true = service charge ikut dikenakan PPN sesuai kelas pajak baris
+}
+
+// TaxSummaryLine represents tax totals for one tax class in a period
+type TaxSummaryLine struct {
+	TaxClassID       *int    `json:"tax_class_id"` // NULL = penjualan tanpa kelas pajak
+	TaxClassName     string  `json:"tax_class_name"`
+	Rate             float64 `json:"rate"`
+	IsInclusive      bool    `json:"is_inclusive"`
+	IsExempt         bool    `json:"is_exempt"`
+	TaxableAmount    float64 `json:"taxable_amount"`    // DPP penjualan
+	TaxAmount        float64 `json:"tax_amount"`        // PPN keluaran
+	ReturnedTax      float64 `json:"returned_tax"`      // PPN atas retur penjualan
+	NetTax           float64 `json:"net_tax"`           // TaxAmount - ReturnedTax
+	TransactionCount int     `json:"transaction_count"` // Jumlah transaksi yang memuat kelas ini
+}
+
+// TaxReport represents tax summary for monthly filing
+// Response untuk GET /api/report/tax
+type TaxReport struct {
+	StartDate          time.Time        `json:"start_date"`
+	EndDate            time.Time        `json:"end_date"`
+	TotalTaxableAmount float64          `json:"total_taxable_amount"` // Total DPP (tidak termasuk penjualan bebas pajak)
+	TotalExemptSales   float64          `json:"total_exempt_sales"`   // Penjualan bebas pajak
+	TotalTax           float64          `json:"total_tax"`            // Total PPN keluaran
+	TotalReturnedTax   float64          `json:"total_returned_tax"`   // PPN atas retur
+	NetTax             float64          `json:"net_tax"`              // PPN terutang = TotalTax - TotalReturnedTax
+	TotalServiceCharge float64          `json:"total_service_charge"` // Total service charge yang ditagihkan
+	Lines              []TaxSummaryLine `json:"lines"`                // Rincian per kelas pajak
+}
@@ -4,49 +4,127 @@ import "time"
 
 // Transaction represents a transaction header
 type Transaction struct {
-	ID             int       `json:"id" db:"id"`
-	TotalAmount    float64   `json:"total_amount" db:"total_amount"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	DiscountID     *int      `json:"discount_id,omitempty" db:"discount_id"`
-	DiscountAmount float64   `json:"discount_amount" db:"discount_amount"`
-	PaymentAmount  float64   `json:"payment_amount" db:"payment_amount"`   // Uang bayar customer
-	ChangeAmount   float64   `json:"change_amount" db:"change_amount"`     // Uang kembalian
-	TotalItems     int       `json:"total_items"`                          // Computed: total items
-	Profit         float64   `json:"profit"`                               // Computed: keuntungan
-	CreatedBy      *int      `json:"created_by,omitempty" db:"created_by"` // User ID pembuat transaksi
-	Username       string    `json:"username,omitempty"`                   // Nama kasir (dari JOIN users)
+	ID                  int                  `json:"id" db:"id"`
+	TotalAmount         float64              `json:"total_amount" db:"total_amount"`
+	CreatedAt           time.Time            `json:"created_at" db:"created_at"`
+	DiscountID          *int                 `json:"discount_id,omitempty" db:"discount_id"`
+	DiscountAmount      float64              `json:"discount_amount" db:"discount_amount"`
+	PaymentAmount       float64              `json:"payment_amount" db:"payment_amount"`     // Uang bayar customer
+	ChangeAmount        float64              `json:"change_amount" db:"change_amount"`       // Uang kembalian
+	TotalItems          int                  `json:"total_items"`                            // Computed: total items
+	Profit              float64              `json:"profit"`                                 // Computed: keuntungan
+	CreatedBy           *int                 `json:"created_by,omitempty" db:"created_by"`   // User ID pembuat transaksi
+	Username            string               `json:"username,omitempty"`                     // Nama kasir (dari JOIN users)
+	VoidedAt            *time.Time           `json:"voided_at,omitempty" db:"voided_at"`     // Waktu transaksi dibatalkan (NULL = aktif)
+	VoidedBy            *int                 `json:"voided_by,omitempty" db:"voided_by"`     // User ID yang membatalkan
+	VoidReason          *string              `json:"void_reason,omitempty" db:"void_reason"` // Alasan pembatalan
+	Payments            []TransactionPayment `json:"payments,omitempty"`                     // Rincian pembayaran (split tender)
+	ShiftID             *int                 `json:"shift_id,omitempty" db:"shift_id"`       // Shift kasir saat transaksi dibuat
+	TaxAmount           float64              `json:"tax_amount"`                             // Total PPN (inclusive + exclusive)
+	TaxIncludedAmount   float64              `json:"tax_included_amount"`                    // Bagian PPN yang sudah termasuk di harga
+	ServiceChargeAmount float64              `json:"service_charge_amount"`                  // Total service charge
+	RoundingAmount      float64              `json:"rounding_amount"`                        // Selisih pembulatan tunai (+ dibulatkan ke atas)
+	CustomerID          *int                 `json:"customer_id,omitempty" db:"customer_id"` // Pelanggan (NULL = umum)
+	VoucherID           *int                 `json:"voucher_id,omitempty" db:"voucher_id"`   // Voucher yang dipakai (diskon global dari voucher_code)
+	PointsEarned        int                  `json:"points_earned,omitempty"`                // Poin loyalitas yang didapat (response checkout)
+	PointsRedeemed      int                  `json:"points_redeemed,omitempty"`              // Poin loyalitas yang dipakai membayar (response checkout)
 }
 
 // TransactionDetail represents a transaction detail item
 type TransactionDetail struct {
-	ID             int       `json:"id"`
-	TransactionID  int       `json:"transaction_id"`
-	ProductID      int       `json:"product_id"`
-	ProductName    string    `json:"product_name"` // Nama produk (dari JOIN)
-	Quantity       int       `json:"quantity"`
-	Price          float64   `json:"price"`
-	Subtotal       float64   `json:"subtotal"`
-	DiscountType   string    `json:"discount_type,omitempty"`   // Tipe diskon item: percentage / fixed
-	DiscountValue  float64   `json:"discount_value,omitempty"`  // Nilai diskon (persen atau nominal)
-	DiscountAmount float64   `json:"discount_amount,omitempty"` // Total potongan nominal untuk item ini
-	HargaBeli      float64   `json:"harga_beli,omitempty"`      // Snapshot harga beli
-	CreatedAt      time.Time `json:"created_at,omitempty"`
+	ID                  int       `json:"id"`
+	TransactionID       int       `json:"transaction_id"`
+	ProductID           int       `json:"product_id"`
+	ProductName         string    `json:"product_name"` // Nama produk (dari JOIN)
+	Quantity            int       `json:"quantity"`
+	Price               float64   `json:"price"`
+	Subtotal            float64   `json:"subtotal"`
+	DiscountType        string    `json:"discount_type,omitempty"`   // Tipe diskon item: percentage / fixed
+	DiscountValue       float64   `json:"discount_value,omitempty"`  // Nilai diskon (persen atau nominal)
+	DiscountAmount      float64   `json:"discount_amount,omitempty"` // Total potongan nominal untuk item ini
+	HargaBeli           float64   `json:"harga_beli,omitempty"`      // Harga pokok per unit saat terjual (sesuai COSTING_METHOD)
+	OriginalPrice       *float64  `json:"original_price,omitempty"`  // Harga database jika baris di-override
+	OverrideApprovedBy  *int      `json:"override_approved_by,omitempty"`
+	OverrideReason      *string   `json:"override_reason,omitempty"`
+	PromotionID         *int      `json:"promotion_id,omitempty"`          // Promosi yang dipakai baris ini (BUY_X_GET_Y / BUNDLE / QTY_TIER)
+	PromotionType       string    `json:"promotion_type,omitempty"`        // Snapshot tipe promosi
+	PromotionName       string    `json:"promotion_name,omitempty"`        // Nama promosi (dari JOIN discounts)
+	PromotionAmount     float64   `json:"promotion_amount,omitempty"`      // Bagian discount_amount yang berasal dari promosi
+	OrderDiscountAmount float64   `json:"order_discount_amount,omitempty"` // Porsi diskon transaksi (discount_id / voucher) di baris ini
+	TaxClassID          *int      `json:"tax_class_id,omitempty"`          // Snapshot kelas pajak
+	TaxRate             float64   `json:"tax_rate"`                        // Snapshot tarif pajak (%)
+	TaxInclusive        bool      `json:"tax_inclusive"`                   // Harga sudah termasuk pajak
+	TaxableAmount       float64   `json:"taxable_amount"`                  // DPP baris (termasuk service charge kena pajak)
+	TaxAmount           float64   `json:"tax_amount"`                      // PPN baris
+	ServiceChargeAmount float64   `json:"service_charge_amount"`           // Service charge baris
+	CreatedAt           time.Time `json:"created_at,omitempty"`
 }
 
 // TransactionWithItems represents full transaction detail with items
 // Response struct untuk GET /api/transactions/{id}
 type TransactionWithItems struct {
-	ID             int                 `json:"id"`
-	TotalAmount    float64             `json:"total_amount"`
-	DiscountAmount float64             `json:"discount_amount"`
-	PaymentAmount  float64             `json:"payment_amount"`
-	ChangeAmount   float64             `json:"change_amount"`
-	Profit         float64             `json:"profit"`
-	TotalItems     int                 `json:"total_items"`
-	CreatedBy      *int                `json:"created_by,omitempty"`
-	Username       string              `json:"username,omitempty"` // Nama kasir
-	CreatedAt      time.Time           `json:"created_at"`
-	Items          []TransactionDetail `json:"items"`
+	ID                  int                   `json:"id"`
+	TotalAmount         float64               `json:"total_amount"`
+	DiscountAmount      float64               `json:"discount_amount"`
+	PaymentAmount       float64               `json:"payment_amount"`
+	ChangeAmount        float64               `json:"change_amount"`
+	TaxAmount           float64               `json:"tax_amount"`          // Total PPN
+	TaxIncludedAmount   float64               `json:"tax_included_amount"` // PPN yang sudah termasuk di harga
+	ServiceChargeAmount float64               `json:"service_charge_amount"`
+	RoundingAmount      float64               `json:"rounding_amount"` // Selisih pembulatan tunai
+	Profit              float64               `json:"profit"`
+	TotalItems          int                   `json:"total_items"`
+	CreatedBy           *int                  `json:"created_by,omitempty"`
+	Username            string                `json:"username,omitempty"` // Nama kasir
+	CustomerID          *int                  `json:"customer_id,omitempty"`
+	CustomerName        string                `json:"customer_name,omitempty"` // Nama pelanggan (dari JOIN customers)
+	VoucherCode         string                `json:"voucher_code,omitempty"`  // Kode voucher yang dipakai (dari JOIN vouchers)
+	CreatedAt           time.Time             `json:"created_at"`
+	VoidedAt            *time.Time            `json:"voided_at,omitempty"`   // Waktu transaksi dibatalkan
+	VoidedBy            *int                  `json:"voided_by,omitempty"`   // User ID yang membatalkan
+	VoidReason          *string               `json:"void_reason,omitempty"` // Alasan pembatalan
+	Items               []TransactionDetail   `json:"items"`
+	Discounts           []TransactionDiscount `json:"discounts"`   // Kombinasi diskon yang dipakai per baris (audit)
+	Payments            []TransactionPayment  `json:"payments"`    // Rincian pembayaran per metode
+	PrintCount          int                   `json:"print_count"` // Berapa kali struk sudah dicetak
+}
+
+// Metode pembayaran yang didukung
+const (
+	PaymentMethodCash     = "cash"
+	PaymentMethodQRIS     = "qris"
+	PaymentMethodDebit    = "debit"
+	PaymentMethodTransfer = "transfer"
+	PaymentMethodCredit   = "credit" // Kasbon: dicatat sebagai piutang pelanggan, bukan uang masuk
+	PaymentMethodPoints   = "points" // Poin loyalitas pelanggan (nominal rupiah = poin × nilai poin)
+)
+
+// IsValidPaymentMethod mengecek apakah metode pembayaran dikenali
+func IsValidPaymentMethod(method string) bool {
+	switch method {
+	case PaymentMethodCash, PaymentMethodQRIS, PaymentMethodDebit, PaymentMethodTransfer, PaymentMethodCredit, PaymentMethodPoints:
+		return true
+	}
+	return false
+}
+
+// TransactionPayment represents one tender line of a transaction
+// Struct untuk 1 baris pembayaran (transaksi bisa dibayar dengan beberapa metode)
+type TransactionPayment struct {
+	ID             int       `json:"id"`
+	TransactionID  int       `json:"transaction_id"`
+	Method         string    `json:"method"`              // cash / qris / debit / transfer / credit / points
+	Amount         float64   `json:"amount"`              // Nominal yang dipakai membayar (cash: setelah kembalian)
+	TenderedAmount float64   `json:"tendered_amount"`     // Nominal yang diserahkan customer
+	Reference      *string   `json:"reference,omitempty"` // No. referensi EDC / QRIS / transfer
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+}
+
+// CheckoutPayment represents a tender line in checkout request
+type CheckoutPayment struct {
+	Method    string  `json:"method"`    // cash / qris / debit / transfer / credit (kasbon) / points (poin) — credit & points wajib customer_id
+	Amount    float64 `json:"amount"`    // Nominal yang diserahkan (cash boleh lebih, sisanya jadi kembalian)
+	Reference string  `json:"reference"` // Optional: no. referensi non-tunai
 }
 
 // CheckoutItem represents an item in checkout request
@@ -57,13 +135,34 @@ type CheckoutItem struct {
 	DiscountType   string  `json:"discount_type"`   // "percentage" atau "fixed"
 	DiscountValue  float64 `json:"discount_value"`  // Nilai diskon (persen atau nominal)
 	DiscountAmount float64 `json:"discount_amount"` // Total potongan nominal sudah dihitung frontend
+
+	// Override harga / diskon dengan persetujuan supervisor (POST /api/overrides/approve)
+	OverridePrice *float64 `json:"override_price,omitempty"` // Harga satuan khusus (menggantikan harga & diskon otomatis)
+	ApprovalToken string   `json:"approval_token,omitempty"` // Wajib jika override_price diisi / diskon manual
+	// Diisi server dari approval token yang valid
+	OverrideApprovedBy *int   `json:"-"`
+	OverrideReason     string `json:"-"`
+	OverrideTokenID    string `json:"-"` // jti token, dicatat saat checkout agar token tidak bisa dipakai ulang
 }
 
 // CheckoutRequest represents the checkout request body
 type CheckoutRequest struct {
-	Items          []CheckoutItem `json:"items"`
-	DiscountID     *int           `json:"discount_id"`     // Optional: ID diskon global
-	DiscountAmount float64        `json:"discount_amount"` // Total diskon transaksi (dari frontend)
-	PaymentAmount  float64        `json:"payment_amount"`  // Uang bayar customer
-	CreatedBy      int            `json:"-"`               // User ID pembuat transaksi (diisi dari context auth)
+	Items          []CheckoutItem    `json:"items"`
+	DiscountID     *int              `json:"discount_id"`     // Optional: ID diskon global
+	VoucherCode    string            `json:"voucher_code"`    // Optional: kode voucher (pengganti discount_id, tidak bisa digabung)
+	DiscountAmount float64           `json:"discount_amount"` // Total diskon transaksi (dari frontend)
+	PaymentAmount  float64           `json:"payment_amount"`  // Uang bayar customer (legacy: dianggap cash jika payments kosong)
+	Payments       []CheckoutPayment `json:"payments"`        // Optional: split tender (cash, qris, debit, transfer)
+	CustomerID     *int              `json:"customer_id"`     // Optional: pelanggan terdaftar (kosong = pelanggan umum)
+	CreatedBy      int               `json:"-"`               // User ID pembuat transaksi (diisi dari context auth)
+	HeldCartID     *int              `json:"-"`               // Diisi jika checkout dari held cart (reservasi cart ini tidak dihitung)
+	// DiscountOverride = diskon dari frontend boleh berbeda dengan pricing engine
+	// (diisi server: checkout oleh admin)
+	DiscountOverride bool `json:"-"`
+}
+
+// VoidTransactionRequest represents the request body for voiding a transaction
+// Body untuk POST /api/transactions/{id}/void
+type VoidTransactionRequest struct {
+	Reason string `json:"reason"` // Alasan pembatalan (wajib)
 }
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Voucher merepresentasikan tabel vouchers
+// Kode yang bisa dicetak & dipakai saat checkout (voucher_code) sebagai diskon global
+type Voucher struct {
+	ID               int          `json:"id"`
+	Code             string       `json:"code"` // Unik, huruf besar
+	Name             string       `json:"name"`
+	Type             DiscountType `json:"type"`  // PERCENTAGE / FIXED
+	Value            float64      `json:"value"` // 10.0 (10%) atau 5000 (Rp 5.000)
+	MinOrderAmount   float64      `json:"min_order_amount"`
+	MaxRedemptions   *int         `json:"max_redemptions"`    // Kuota total (null = tanpa batas, 1 = sekali pakai)
+	PerCustomerLimit *int         `json:"per_customer_limit"` // Batas per pelanggan (null = tanpa batas)
+	RedemptionCount  int          `json:"redemption_count"`   // Jumlah pemakaian (tidak termasuk transaksi void)
+	Remaining        *int         `json:"remaining"`          // Sisa kuota (null = tanpa batas)
+	StartDate        time.Time    `json:"start_date"`
+	EndDate          time.Time    `json:"end_date"`
+	IsActive         bool         `json:"is_active"`
+	CreatedBy        *int         `json:"created_by,omitempty"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+// SetRemaining menghitung sisa kuota dari max_redemptions & redemption_count
+func (v *Voucher) SetRemaining() {
+	v.Remaining = nil
+	if v.MaxRedemptions != nil {
+		remaining := *v.MaxRedemptions - v.RedemptionCount
+		if remaining < 0 {
+			remaining = 0
+		}
+		v.Remaining = &remaining
+	}
+}
+
+// IsValidAt mengecek status aktif & masa berlaku voucher pada waktu t
+func (v *Voucher) IsValidAt(t time.Time) bool {
+	return v.IsActive && !t.Before(v.StartDate) && !t.After(v.EndDate)
+}
+
+// CalculateDiscount menghitung potongan voucher dari total belanja (setelah diskon item)
+// Return 0 jika minimal belanja tidak terpenuhi; potongan tidak melebihi total
+func (v *Voucher) CalculateDiscount(totalAmount float64) float64 {
+	if totalAmount < v.MinOrderAmount {
+		return 0
+	}
+	var discountAmount float64
+	if v.Type == DiscountPercentage {
+		discountAmount = totalAmount * (v.Value / 100)
+	} else {
+		discountAmount = v.Value
+	}
+	if discountAmount > totalAmount {
+		return totalAmount
+	}
+	return discountAmount
+}
+
+// VoucherRequest DTO untuk POST / PUT /api/vouchers
+type VoucherRequest struct {
+	Code             string       `json:"code"` // Kosong saat create = dibuat otomatis
+	Name             string       `json:"name"`
+	Type             DiscountType `json:"type"`
+	Value            float64      `json:"value"`
+	MinOrderAmount   float64      `json:"min_order_amount"`
+	MaxRedemptions   *int         `json:"max_redemptions"`
+	PerCustomerLimit *int         `json:"per_customer_limit"`
+	StartDate        time.Time    `json:"start_date"`
+	EndDate          time.Time    `json:"end_date"`
+	IsActive         *bool        `json:"is_active,omitempty"` // Default true
+}
+
+// VoucherGenerateRequest DTO untuk POST /api/vouchers/generate
+// Membuat banyak kode sekaligus (misal untuk dicetak) dengan aturan yang sama
+type VoucherGenerateRequest struct {
+	VoucherRequest
+	Prefix string `json:"prefix"` // Awalan kode, misal "LEBARAN" → LEBARAN-7KQ2M9XA
+	Count  int    `json:"count"`  // Jumlah kode (1-1000)
+}
+
+// NormalizeVoucherCode menyeragamkan kode voucher (huruf besar, tanpa spasi di awal/akhir)
+func NormalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsValidVoucherCode mengecek format kode: 3-32 karakter, huruf / angka / tanda hubung
+func IsValidVoucherCode(code string) bool {
+	if len(code) < 3 || len(code) > 32 {
+		return false
+	}
+	for _, r := range code {
+		if !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate mengecek aturan voucher (Code dicek terpisah karena bisa dibuat otomatis)
+func (r *VoucherRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return ErrVoucherNameEmpty
+	}
+	if r.Type != DiscountPercentage && r.Type != DiscountFixed {
+		return ErrInvalidVoucherType
+	}
+	if r.Value <= 0 || (r.Type == DiscountPercentage && r.Value > 100) {
+		return ErrInvalidVoucherValue
+	}
+	if r.MinOrderAmount < 0 {
+		return ErrInvalidVoucherValue
+	}
+	if !r.EndDate.After(r.StartDate) {
+		return ErrInvalidVoucherPeriod
+	}
+	if (r.MaxRedemptions != nil && *r.MaxRedemptions <= 0) || (r.PerCustomerLimit != nil && *r.PerCustomerLimit <= 0) {
+		return ErrInvalidVoucherLimit
+	}
+	return nil
+}
+
+// VoucherReportLine adalah ringkasan pemakaian 1 voucher dalam periode laporan
+type VoucherReportLine struct {
+	VoucherID       int     `json:"voucher_id"`
+	Code            string  `json:"code"`
+	Name            string  `json:"name"`
+	RedemptionCount int     `json:"redemption_count"` // Pemakaian dalam periode (tidak termasuk void)
+	UniqueCustomers int     `json:"unique_customers"` // Pelanggan terdaftar yang memakai
+	TotalDiscount   float64 `json:"total_discount"`   // Total potongan voucher
+	TotalSales      float64 `json:"total_sales"`      // Total transaksi yang memakai voucher
+	VoidedCount     int     `json:"voided_count"`     // Pemakaian yang dibatalkan (void) dalam periode
+	MaxRedemptions  *int    `json:"max_redemptions"`
+	TotalRedeemed   int     `json:"total_redeemed"` // Pemakaian sepanjang waktu
+	Remaining       *int    `json:"remaining"`      // Sisa kuota saat ini
+}
+
+// VoucherReport represents voucher redemptions in a period
+// Response untuk GET /api/report/vouchers
+type VoucherReport struct {
+	StartDate       time.Time           `json:"start_date"`
+	EndDate         time.Time           `json:"end_date"`
+	RedemptionCount int                 `json:"redemption_count"`
+	TotalDiscount   float64             `json:"total_discount"`
+	TotalSales      float64             `json:"total_sales"`
+	Vouchers        []VoucherReportLine `json:"vouchers"`
+}
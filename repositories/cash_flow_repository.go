@@ -18,16 +18,65 @@ func NewCashFlowRepository(db *sql.DB) *CashFlowRepository {
 func (r *CashFlowRepository) GetSummary(startDate, endDate time.Time) (*models.CashFlowSummary, error) {
 	var summary models.CashFlowSummary
 
-	// 1. Cash In (Total Pemasukan dari Transaksi)
+	// 1. Cash In (Total Pemasukan dari Transaksi, kecuali yang sudah di-void)
+	// Pembulatan tunai ikut dihitung karena uangnya benar-benar masuk/keluar laci
 	queryCashIn := `
-		SELECT COALESCE(SUM(total_amount), 0)
+		SELECT COALESCE(SUM(total_amount), 0), COALESCE(SUM(rounding_amount), 0)
 		FROM transactions
+		WHERE created_at BETWEEN $1 AND $2 AND voided_at IS NULL
+	`
+	err := r.db.QueryRow(queryCashIn, startDate, endDate).Scan(&summary.CashIn, &summary.TotalRounding)
+	if err != nil {
+		return nil, err
+	}
+	summary.CashIn += summary.TotalRounding
+
+	// 1B. Refund retur penjualan (uang keluar kembali ke customer) → mengurangi Cash In
+	// Bagian refund yang memotong kasbon / dikembalikan sebagai poin bukan uang keluar
+	queryRefunds := `
+		SELECT COALESCE(SUM(total_refund - COALESCE(credit_refund, 0) - COALESCE(points_refund, 0)), 0)
+		FROM sales_returns
 		WHERE created_at BETWEEN $1 AND $2
 	`
-	err := r.db.QueryRow(queryCashIn, startDate, endDate).Scan(&summary.CashIn)
+	err = r.db.QueryRow(queryRefunds, startDate, endDate).Scan(&summary.CashRefunds)
 	if err != nil {
 		return nil, err
 	}
+	summary.CashIn -= summary.CashRefunds
+
+	// 1C. Rincian Cash In per metode pembayaran
+	// Penjualan kasbon (tender credit) belum menjadi uang masuk → dikeluarkan dari Cash In
+	// Pembayaran dengan poin loyalitas juga bukan uang masuk
+	breakdown, err := getPaymentBreakdown(r.db, startDate, endDate, nil)
+	if err != nil {
+		return nil, err
+	}
+	cashInByMethod := make([]models.PaymentMethodTotal, 0, len(breakdown))
+	for _, m := range breakdown {
+		if m.Method == models.PaymentMethodCredit {
+			summary.CreditSales = m.Amount
+			continue
+		}
+		if m.Method == models.PaymentMethodPoints {
+			summary.PointsRedeemed = m.Amount
+			continue
+		}
+		cashInByMethod = append(cashInByMethod, m)
+	}
+	summary.CashIn -= summary.CreditSales + summary.PointsRedeemed
+	cashInByMethod = subtractCashRefund(cashInByMethod, summary.CashRefunds)
+
+	// 1D. Cicilan kasbon pelanggan = uang masuk (sesuai metode pembayarannya)
+	repayments, err := getCreditRepaymentBreakdown(r.db, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range repayments {
+		summary.CreditRepayments += m.Amount
+		cashInByMethod = addMethodAmount(cashInByMethod, m.Method, m.Amount)
+	}
+	summary.CashIn += summary.CreditRepayments
+	summary.CashInByMethod = cashInByMethod
 
 	// 2. Cash Out: Purchases
 	queryPurchases := `
@@ -62,9 +111,23 @@ func (r *CashFlowRepository) GetSummary(startDate, endDate time.Time) (*models.C
 		return nil, err
 	}
 
+	// 5. Kas kecil laci kasir (pay in / pay out)
+	// Setor ke brankas (drop) tidak dihitung karena hanya perpindahan uang internal
+	queryPettyCash := `
+		SELECT
+			COALESCE(SUM(amount) FILTER (WHERE type = 'pay_in'), 0),
+			COALESCE(SUM(amount) FILTER (WHERE type = 'pay_out'), 0)
+		FROM cash_movements
+		WHERE created_at BETWEEN $1 AND $2
+	`
+	err = r.db.QueryRow(queryPettyCash, startDate, endDate).Scan(&summary.PettyCashIn, &summary.PettyCashOut)
+	if err != nil {
+		return nil, err
+	}
+
 	// Hitung Aggregasi Akhir
-	summary.CashOutTotal = summary.CashOutPurchases + summary.CashOutPayroll + summary.CashOutExpenses
-	summary.NetCashFlow = summary.CashIn - summary.CashOutTotal
+	summary.CashOutTotal = summary.CashOutPurchases + summary.CashOutPayroll + summary.CashOutExpenses + summary.PettyCashOut
+	summary.NetCashFlow = summary.CashIn + summary.PettyCashIn - summary.CashOutTotal
 
 	return &summary, nil
 }
@@ -73,17 +136,35 @@ func (r *CashFlowRepository) GetSummary(startDate, endDate time.Time) (*models.C
 // tzName: nama timezone untuk mapping timestamp UTC ke regional user.
 // format: "YYYY-MM-DD" untuk daily atau "YYYY-MM" untuk monthly
 func (r *CashFlowRepository) GetTrend(startDate, endDate time.Time, format, tzName string) (*models.CashFlowTrendResponse, error) {
-	// CTE (Common Table Expression) untuk menggabungkan Cash In (transactions)
-	// dan Cash Out (purchases + payroll + expenses) pada timezone specifik lalu group by Period format.
+	// CTE (Common Table Expression) untuk menggabungkan Cash In (transactions - kasbon - poin - refund tunai + cicilan kasbon)
+	// dan Cash Out (purchases + payroll + expenses + kas kecil) pada timezone specifik lalu group by Period format.
 	query := `
 		WITH cash_in AS (
 			SELECT 
 				TO_CHAR((created_at AT TIME ZONE 'UTC' AT TIME ZONE $1), $2) as period,
-				SUM(total_amount) as amount
+				SUM(total_amount + COALESCE(rounding_amount, 0) - COALESCE(
+					(SELECT SUM(tp.amount) FROM transaction_payments tp WHERE tp.transaction_id = transactions.id AND tp.method IN ('credit', 'points')), 0
+				)) as amount
 			FROM transactions
+			WHERE created_at BETWEEN $3 AND $4 AND voided_at IS NULL
+			GROUP BY period
+		),
+		cash_refunds AS (
+			SELECT 
+				TO_CHAR((created_at AT TIME ZONE 'UTC' AT TIME ZONE $1), $2) as period,
+				SUM(total_refund - COALESCE(credit_refund, 0) - COALESCE(points_refund, 0)) as amount
+			FROM sales_returns
 			WHERE created_at BETWEEN $3 AND $4
 			GROUP BY period
 		),
+		credit_repayments AS (
+			SELECT 
+				TO_CHAR((created_at AT TIME ZONE 'UTC' AT TIME ZONE $1), $2) as period,
+				SUM(amount) as amount
+			FROM customer_credit_ledger
+			WHERE created_at BETWEEN $3 AND $4 AND type = 'repayment'
+			GROUP BY period
+		),
 		cash_out_purchases AS (
 			SELECT 
 				TO_CHAR((created_at AT TIME ZONE 'UTC' AT TIME ZONE $1), $2) as period,
@@ -108,18 +189,33 @@ func (r *CashFlowRepository) GetTrend(startDate, endDate time.Time, format, tzNa
 			WHERE expense_date BETWEEN $3 AND $4
 			GROUP BY period
 		),
+		petty_cash AS (
+			SELECT 
+				TO_CHAR((created_at AT TIME ZONE 'UTC' AT TIME ZONE $1), $2) as period,
+				SUM(amount) FILTER (WHERE type = 'pay_in') as amount_in,
+				SUM(amount) FILTER (WHERE type = 'pay_out') as amount_out
+			FROM cash_movements
+			WHERE created_at BETWEEN $3 AND $4 AND type IN ('pay_in', 'pay_out')
+			GROUP BY period
+		),
 		all_periods AS (
 			SELECT period FROM cash_in
+			UNION SELECT period FROM cash_refunds
+			UNION SELECT period FROM credit_repayments
+			UNION SELECT period FROM petty_cash
 			UNION SELECT period FROM cash_out_purchases
 			UNION SELECT period FROM cash_out_payroll
 			UNION SELECT period FROM cash_out_expenses
 		)
 		SELECT 
 			ap.period,
-			COALESCE(ci.amount, 0) as cash_in,
-			COALESCE(cop.amount, 0) + COALESCE(cpr.amount, 0) + COALESCE(cpe.amount, 0) as cash_out
+			COALESCE(ci.amount, 0) - COALESCE(cr.amount, 0) + COALESCE(crp.amount, 0) + COALESCE(pc.amount_in, 0) as cash_in,
+			COALESCE(cop.amount, 0) + COALESCE(cpr.amount, 0) + COALESCE(cpe.amount, 0) + COALESCE(pc.amount_out, 0) as cash_out
 		FROM all_periods ap
 		LEFT JOIN cash_in ci ON ap.period = ci.period
+		LEFT JOIN cash_refunds cr ON ap.period = cr.period
+		LEFT JOIN credit_repayments crp ON ap.period = crp.period
+		LEFT JOIN petty_cash pc ON ap.period = pc.period
 		LEFT JOIN cash_out_purchases cop ON ap.period = cop.period
 		LEFT JOIN cash_out_payroll cpr ON ap.period = cpr.period
 		LEFT JOIN cash_out_expenses cpe ON ap.period = cpe.period
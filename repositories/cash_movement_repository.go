@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+)
+
+// CashMovementRepository handles database operations for drawer cash movements
+type CashMovementRepository struct {
+	db *sql.DB
+}
+
+// NewCashMovementRepository creates a new CashMovementRepository
+func NewCashMovementRepository(db *sql.DB) *CashMovementRepository {
+	return &CashMovementRepository{db: db}
+}
+
+// Create mencatat pergerakan kas laci pada shift user yang sedang terbuka
+func (r *CashMovementRepository) Create(userID int, req *models.CashMovementRequest) (*models.CashMovement, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Shift di-lock FOR SHARE agar tidak ditutup di tengah pencatatan
+	shiftID, err := getOpenShiftID(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if shiftID == nil {
+		err = models.ErrNoOpenShift
+		return nil, err
+	}
+
+	m := models.CashMovement{
+		ShiftID: *shiftID,
+		UserID:  userID,
+		Type:    req.Type,
+		Amount:  req.Amount,
+		Note:    req.Note,
+	}
+	err = tx.QueryRow(`
+		INSERT INTO cash_movements (shift_id, user_id, type, amount, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, m.ShiftID, userID, req.Type, req.Amount, req.Note).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan pergerakan kas: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("💵 Kas laci %s %.0f pada shift ID %d oleh user ID %d", req.Type, req.Amount, m.ShiftID, userID)
+	return &m, nil
+}
+
+// GetAll mengambil riwayat pergerakan kas (filter opsional per shift / per user)
+func (r *CashMovementRepository) GetAll(shiftID *int, userID *int) ([]models.CashMovement, error) {
+	query := `
+		SELECT cm.id, cm.shift_id, cm.user_id, u.username, cm.type, cm.amount, cm.note, cm.created_at
+		FROM cash_movements cm
+		LEFT JOIN users u ON cm.user_id = u.id
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	if shiftID != nil {
+		args = append(args, *shiftID)
+		query += fmt.Sprintf(" AND cm.shift_id = $%d", len(args))
+	}
+	if userID != nil {
+		args = append(args, *userID)
+		query += fmt.Sprintf(" AND cm.user_id = $%d", len(args))
+	}
+	query += " ORDER BY cm.created_at DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil pergerakan kas: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCashMovements(rows)
+}
+
+func getCashMovementsByShift(q queryer, shiftID int) ([]models.CashMovement, error) {
+	rows, err := q.Query(`
+		SELECT cm.id, cm.shift_id, cm.user_id, u.username, cm.type, cm.amount, cm.note, cm.created_at
+		FROM cash_movements cm
+		LEFT JOIN users u ON cm.user_id = u.id
+		WHERE cm.shift_id = $1
+		ORDER BY cm.created_at
+	`, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil pergerakan kas: %w", err)
+	}
+	defer rows.Close()
+
+	return scanCashMovements(rows)
+}
+
+func scanCashMovements(rows *sql.Rows) ([]models.CashMovement, error) {
+	movements := []models.CashMovement{}
+	for rows.Next() {
+		var m models.CashMovement
+		var username sql.NullString
+		if err := rows.Scan(&m.ID, &m.ShiftID, &m.UserID, &username, &m.Type, &m.Amount, &m.Note, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("gagal membaca pergerakan kas: %w", err)
+		}
+		if username.Valid {
+			m.Username = username.String
+		}
+		movements = append(movements, m)
+	}
+	return movements, nil
+}
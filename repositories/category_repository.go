@@ -21,7 +21,7 @@ func NewCategoryRepository(db *sql.DB) *CategoryRepository {
 // Fungsi ini mengambil semua kategori dari table categories
 func (r *CategoryRepository) GetAll() ([]models.Category, error) {
 	// SQL query untuk select semua kolom dari table categories
-	query := "SELECT id, nama, description, COALESCE(discount_type, '') as discount_type, COALESCE(discount_value, 0) as discount_value FROM categories"
+	query := "SELECT id, nama, description, COALESCE(discount_type, '') as discount_type, COALESCE(discount_value, 0) as discount_value, tax_class_id FROM categories"
 
 	// Execute query dan dapatkan rows (banyak baris)
 	rows, err := r.db.Query(query)
@@ -39,7 +39,7 @@ func (r *CategoryRepository) GetAll() ([]models.Category, error) {
 		var discType string
 
 		// Scan data dari row ke struct category
-		err := rows.Scan(&category.ID, &category.Nama, &category.Description, &discType, &category.DiscountValue)
+		err := rows.Scan(&category.ID, &category.Nama, &category.Description, &discType, &category.DiscountValue, &category.TaxClassID)
 		if err != nil {
 			return nil, err // Kalau scan error, return error
 		}
@@ -60,12 +60,12 @@ func (r *CategoryRepository) GetAll() ([]models.Category, error) {
 // Fungsi ini mengambil 1 kategori berdasarkan ID beserta semua products dalam category tersebut
 func (r *CategoryRepository) GetByID(id int) (*models.Category, error) {
 	// 1. Ambil category data
-	query := "SELECT id, nama, description, COALESCE(discount_type, '') as discount_type, COALESCE(discount_value, 0) as discount_value FROM categories WHERE id = $1"
+	query := "SELECT id, nama, description, COALESCE(discount_type, '') as discount_type, COALESCE(discount_value, 0) as discount_value, tax_class_id FROM categories WHERE id = $1"
 	row := r.db.QueryRow(query, id)
 
 	var category models.Category
 	var discType string
-	err := row.Scan(&category.ID, &category.Nama, &category.Description, &discType, &category.DiscountValue)
+	err := row.Scan(&category.ID, &category.Nama, &category.Description, &discType, &category.DiscountValue, &category.TaxClassID)
 	if err != nil {
 		return nil, err // Kalau tidak ketemu atau error, return nil
 	}
@@ -106,10 +106,10 @@ func (r *CategoryRepository) GetByID(id int) (*models.Category, error) {
 func (r *CategoryRepository) Create(category *models.Category) error {
 	// SQL query untuk INSERT
 	// RETURNING id = return ID yang baru dibuat (auto-increment)
-	query := "INSERT INTO categories (nama, description, discount_type, discount_value) VALUES ($1, $2, $3, $4) RETURNING id"
+	query := "INSERT INTO categories (nama, description, discount_type, discount_value, tax_class_id) VALUES ($1, $2, $3, $4, $5) RETURNING id"
 
 	// Execute query dan langsung scan ID yang di-return
-	err := r.db.QueryRow(query, category.Nama, category.Description, category.DiscountType, category.DiscountValue).Scan(&category.ID)
+	err := r.db.QueryRow(query, category.Nama, category.Description, category.DiscountType, category.DiscountValue, category.TaxClassID).Scan(&category.ID)
 
 	return err // Return error (nil kalau sukses)
 }
@@ -118,12 +118,12 @@ func (r *CategoryRepository) Create(category *models.Category) error {
 // Fungsi ini mengupdate kategori yang sudah ada
 func (r *CategoryRepository) Update(category *models.Category) error {
 	// SQL query untuk UPDATE
-	// SET untuk set nilai baru termasuk discount
+	// SET untuk set nilai baru termasuk discount & kelas pajak
 	// WHERE untuk kondisi (update kategori dengan id tertentu)
-	query := "UPDATE categories SET nama = $1, description = $2, discount_type = $3, discount_value = $4 WHERE id = $5"
+	query := "UPDATE categories SET nama = $1, description = $2, discount_type = $3, discount_value = $4, tax_class_id = $5 WHERE id = $6"
 
 	// Execute query
-	_, err := r.db.Exec(query, category.Nama, category.Description, category.DiscountType, category.DiscountValue, category.ID)
+	_, err := r.db.Exec(query, category.Nama, category.Description, category.DiscountType, category.DiscountValue, category.TaxClassID, category.ID)
 
 	return err // Return error (nil kalau sukses)
 }
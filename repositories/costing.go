@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+// receiveCost mencatat harga pokok barang masuk setelah stok bertambah (m = pergerakan yang sudah
+// diterapkan, StockAfter terisi). Selalu menambah lapisan biaya (cost_layers), lalu memperbarui
+// products.harga_beli sesuai metode:
+//   - average: rata-rata tertimbang stok lama (harga_beli) dan barang masuk (unitCost)
+//   - fifo: rata-rata sisa lapisan biaya (nilai persediaan / qty)
+//   - last: unitCost, kecuali retur & void (barang kembali dengan harga pokok penjualannya)
+func receiveCost(tx *sql.Tx, method string, m models.StockMovement, unitCost float64) error {
+	if m.Quantity > 0 {
+		_, err := tx.Exec(`
+			INSERT INTO cost_layers (product_id, source_type, reference_id, unit_cost, quantity, remaining)
+			VALUES ($1, $2, $3, $4, $5, $5)`,
+			m.ProductID, m.Type, m.ReferenceID, unitCost, m.Quantity)
+		if err != nil {
+			return fmt.Errorf("gagal mencatat lapisan biaya: %w", err)
+		}
+	}
+
+	switch method {
+	case models.CostingFIFO:
+		if m.Quantity > 0 {
+			return refreshLayerCost(tx, m.ProductID)
+		}
+	case models.CostingLast:
+		if m.Type != models.StockMovementReturn && m.Type != models.StockMovementVoid {
+			_, err := tx.Exec("UPDATE products SET harga_beli = $1 WHERE id = $2", unitCost, m.ProductID)
+			return err
+		}
+	default:
+		if m.Quantity <= 0 {
+			return nil
+		}
+		var current sql.NullFloat64
+		if err := tx.QueryRow("SELECT harga_beli FROM products WHERE id = $1", m.ProductID).Scan(&current); err != nil {
+			return err
+		}
+		// Stok sebelum barang masuk yang negatif / harga lama kosong tidak ikut dirata-rata
+		prevQty := max(m.StockAfter-m.Quantity, 0)
+		cost := unitCost
+		if current.Valid && prevQty > 0 {
+			cost = roundMoney((current.Float64*float64(prevQty) + unitCost*float64(m.Quantity)) / float64(prevQty+m.Quantity))
+		}
+		_, err := tx.Exec("UPDATE products SET harga_beli = $1 WHERE id = $2", cost, m.ProductID)
+		return err
+	}
+	return nil
+}
+
+// consumeCost mengambil qty unit dari lapisan biaya terlama (FIFO) dan mengembalikan harga pokok
+// per unit barang keluar: fifo = rata-rata lapisan yang terpakai, average / last = products.harga_beli.
+// Unit yang melebihi sisa lapisan (stok lama sebelum pencatatan lapisan) dihargai products.harga_beli.
+// Dipanggil dalam tx yang sudah me-lock baris produk.
+func consumeCost(tx *sql.Tx, method string, productID, qty int) (float64, error) {
+	var current sql.NullFloat64
+	if err := tx.QueryRow("SELECT harga_beli FROM products WHERE id = $1", productID).Scan(&current); err != nil {
+		return 0, err
+	}
+	if qty <= 0 {
+		return current.Float64, nil
+	}
+
+	type layer struct {
+		id        int
+		remaining int
+		unitCost  float64
+	}
+	rows, err := tx.Query(`
+		SELECT id, remaining, unit_cost FROM cost_layers
+		WHERE product_id = $1 AND remaining > 0
+		ORDER BY id
+		FOR UPDATE`, productID)
+	if err != nil {
+		return 0, err
+	}
+	var layers []layer
+	for rows.Next() {
+		var l layer
+		if err := rows.Scan(&l.id, &l.remaining, &l.unitCost); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		layers = append(layers, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	left := qty
+	var layerCost float64
+	for _, l := range layers {
+		if left == 0 {
+			break
+		}
+		take := min(l.remaining, left)
+		if _, err := tx.Exec("UPDATE cost_layers SET remaining = remaining - $1 WHERE id = $2", take, l.id); err != nil {
+			return 0, err
+		}
+		layerCost += float64(take) * l.unitCost
+		left -= take
+	}
+
+	if method != models.CostingFIFO {
+		return current.Float64, nil
+	}
+	layerCost += float64(left) * current.Float64
+	if err := refreshLayerCost(tx, productID); err != nil {
+		return 0, err
+	}
+	return roundMoney(layerCost / float64(qty)), nil
+}
+
+// refreshLayerCost mengisi products.harga_beli dengan rata-rata sisa lapisan biaya (metode fifo)
+// sehingga stok × harga_beli = nilai persediaan. Tidak berubah jika semua lapisan habis.
+func refreshLayerCost(tx *sql.Tx, productID int) error {
+	_, err := tx.Exec(`
+		UPDATE products SET harga_beli = l.avg_cost
+		FROM (
+			SELECT ROUND(SUM(remaining * unit_cost) / SUM(remaining), 2) as avg_cost
+			FROM cost_layers
+			WHERE product_id = $1 AND remaining > 0
+			HAVING SUM(remaining) > 0
+		) l
+		WHERE products.id = $1`, productID)
+	return err
+}
@@ -0,0 +1,331 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+	"time"
+)
+
+// CustomerCreditRepository handles database operations for customer credit (kasbon)
+// Buku besar piutang: customer_credit_ledger (charge menambah, repayment/return/void mengurangi)
+type CustomerCreditRepository struct {
+	db *sql.DB
+}
+
+// NewCustomerCreditRepository creates a new CustomerCreditRepository
+func NewCustomerCreditRepository(db *sql.DB) *CustomerCreditRepository {
+	return &CustomerCreditRepository{db: db}
+}
+
+const creditLedgerSelectQuery = `
+	SELECT l.id, l.customer_id, l.type, l.amount, l.transaction_id, l.sales_return_id,
+		l.method, l.reference, l.note, l.created_by, COALESCE(u.username, ''), l.shift_id, l.created_at
+	FROM customer_credit_ledger l
+	LEFT JOIN users u ON l.created_by = u.id
+`
+
+func scanCreditEntry(row rowScanner) (*models.CreditLedgerEntry, error) {
+	var e models.CreditLedgerEntry
+	err := row.Scan(&e.ID, &e.CustomerID, &e.Type, &e.Amount, &e.TransactionID, &e.SalesReturnID,
+		&e.Method, &e.Reference, &e.Note, &e.CreatedBy, &e.Username, &e.ShiftID, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// GetSummary mengambil saldo kasbon, aging, dan riwayat buku besar 1 pelanggan
+func (r *CustomerCreditRepository) GetSummary(customerID int) (*models.CustomerCreditSummary, error) {
+	summary := &models.CustomerCreditSummary{CustomerID: customerID}
+	err := r.db.QueryRow("SELECT name, phone, credit_limit FROM customers WHERE id = $1", customerID).
+		Scan(&summary.CustomerName, &summary.Phone, &summary.CreditLimit)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pelanggan dengan ID %d tidak ditemukan", customerID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(creditLedgerSelectQuery+" WHERE l.customer_id = $1 ORDER BY l.created_at, l.id", customerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil kasbon pelanggan: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.CreditLedgerEntry
+	for rows.Next() {
+		e, err := scanCreditEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca kasbon pelanggan: %w", err)
+		}
+		entries = append(entries, *e)
+	}
+
+	applyCreditAging(summary, entries, time.Now())
+
+	// Riwayat ditampilkan terbaru dulu
+	summary.Entries = make([]models.CreditLedgerEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		summary.Entries = append(summary.Entries, entries[i])
+	}
+	return summary, nil
+}
+
+// Repay mencatat pembayaran / cicilan kasbon pelanggan
+// Baris pelanggan di-lock agar 2 cicilan bersamaan tidak melebihi sisa kasbon.
+// Cicilan dihubungkan ke shift kasir yang terbuka (cicilan tunai masuk ke laci).
+func (r *CustomerCreditRepository) Repay(customerID int, req *models.CreditRepaymentRequest) (*models.CreditLedgerEntry, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var lockedID int
+	err = tx.QueryRow("SELECT id FROM customers WHERE id = $1 FOR UPDATE", customerID).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		err = fmt.Errorf("pelanggan dengan ID %d tidak ditemukan", customerID)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := getCreditBalance(tx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Amount > balance {
+		err = fmt.Errorf("%w (sisa kasbon: %.0f, dibayar: %.0f)", models.ErrRepaymentExceedsBalance, balance, req.Amount)
+		return nil, err
+	}
+
+	shiftID, err := getOpenShiftID(tx, req.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.CreditLedgerEntry{
+		CustomerID: customerID,
+		Type:       models.CreditEntryRepayment,
+		Amount:     req.Amount,
+		Method:     &req.Method,
+		CreatedBy:  &req.CreatedBy,
+		ShiftID:    shiftID,
+	}
+	if req.Reference != "" {
+		entry.Reference = &req.Reference
+	}
+	if req.Note != "" {
+		entry.Note = &req.Note
+	}
+	err = insertCreditEntry(tx, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("💳 Cicilan kasbon pelanggan ID %d: %.0f (%s), sisa %.0f", customerID, req.Amount, req.Method, balance-req.Amount)
+	return entry, nil
+}
+
+// GetReceivables merangkum piutang semua pelanggan yang masih punya sisa kasbon (per asOf)
+func (r *CustomerCreditRepository) GetReceivables(asOf time.Time) (*models.ReceivablesReport, error) {
+	rows, err := r.db.Query(`
+		SELECT c.id, c.name, c.phone, c.credit_limit,
+			l.id, l.customer_id, l.type, l.amount, l.transaction_id, l.sales_return_id, l.created_at
+		FROM customer_credit_ledger l
+		JOIN customers c ON l.customer_id = c.id
+		WHERE l.created_at <= $1
+		ORDER BY c.id, l.created_at, l.id
+	`, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data piutang: %w", err)
+	}
+	defer rows.Close()
+
+	report := &models.ReceivablesReport{AsOf: asOf, Customers: []models.CustomerCreditSummary{}}
+
+	var current *models.CustomerCreditSummary
+	var entries []models.CreditLedgerEntry
+	flush := func() {
+		if current == nil {
+			return
+		}
+		applyCreditAging(current, entries, asOf)
+		if current.Outstanding > 0 {
+			report.TotalOutstanding += current.Outstanding
+			report.Aging.Days0To30 += current.Aging.Days0To30
+			report.Aging.Days31To60 += current.Aging.Days31To60
+			report.Aging.DaysOver60 += current.Aging.DaysOver60
+			report.Customers = append(report.Customers, *current)
+		}
+	}
+
+	for rows.Next() {
+		var s models.CustomerCreditSummary
+		var e models.CreditLedgerEntry
+		err := rows.Scan(&s.CustomerID, &s.CustomerName, &s.Phone, &s.CreditLimit,
+			&e.ID, &e.CustomerID, &e.Type, &e.Amount, &e.TransactionID, &e.SalesReturnID, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca data piutang: %w", err)
+		}
+		if current == nil || current.CustomerID != s.CustomerID {
+			flush()
+			current = &s
+			entries = entries[:0]
+		}
+		entries = append(entries, e)
+	}
+	flush()
+
+	report.TotalOutstanding = roundMoney(report.TotalOutstanding)
+	return report, nil
+}
+
+// applyCreditAging menghitung sisa kasbon, available credit, dan aging dari buku besar
+// entries harus urut dari yang paling lama. Retur / void dipotongkan ke kasbon transaksinya
+// sendiri; cicilan (dan sisa potongan) dialokasikan FIFO ke kasbon paling lama.
+func applyCreditAging(s *models.CustomerCreditSummary, entries []models.CreditLedgerEntry, asOf time.Time) {
+	type openCharge struct {
+		remaining float64
+		createdAt time.Time
+	}
+	charges := []*openCharge{}
+	chargeByTransaction := make(map[int]*openCharge)
+	var pool float64
+
+	for _, e := range entries {
+		if e.Type == models.CreditEntryCharge {
+			c := &openCharge{remaining: e.Amount, createdAt: e.CreatedAt}
+			charges = append(charges, c)
+			if e.TransactionID != nil {
+				chargeByTransaction[*e.TransactionID] = c
+			}
+			continue
+		}
+
+		amount := e.Amount
+		if e.TransactionID != nil && e.Type != models.CreditEntryRepayment {
+			if c, ok := chargeByTransaction[*e.TransactionID]; ok {
+				applied := amount
+				if applied > c.remaining {
+					applied = c.remaining
+				}
+				c.remaining -= applied
+				amount -= applied
+			}
+		}
+		pool += amount
+	}
+
+	s.Aging = models.CreditAging{}
+	s.Outstanding = 0
+	s.OldestChargeAt = nil
+	for _, c := range charges {
+		if pool > 0 {
+			applied := pool
+			if applied > c.remaining {
+				applied = c.remaining
+			}
+			c.remaining -= applied
+			pool -= applied
+		}
+		if c.remaining <= 0.005 {
+			continue
+		}
+		if s.OldestChargeAt == nil {
+			createdAt := c.createdAt
+			s.OldestChargeAt = &createdAt
+		}
+		s.Outstanding += c.remaining
+		s.Aging.Add(int(asOf.Sub(c.createdAt).Hours()/24), c.remaining)
+	}
+
+	s.Outstanding = roundMoney(s.Outstanding)
+	s.Aging.Days0To30 = roundMoney(s.Aging.Days0To30)
+	s.Aging.Days31To60 = roundMoney(s.Aging.Days31To60)
+	s.Aging.DaysOver60 = roundMoney(s.Aging.DaysOver60)
+	s.AvailableCredit = roundMoney(s.CreditLimit - s.Outstanding)
+	if s.AvailableCredit < 0 {
+		s.AvailableCredit = 0
+	}
+}
+
+// getCreditBalance menghitung sisa kasbon pelanggan (charge - repayment - return - void)
+func getCreditBalance(q queryer, customerID int) (float64, error) {
+	var balance float64
+	err := q.QueryRow(`
+		SELECT COALESCE(SUM(CASE WHEN type = 'charge' THEN amount ELSE -amount END), 0)
+		FROM customer_credit_ledger
+		WHERE customer_id = $1
+	`, customerID).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("gagal menghitung sisa kasbon: %w", err)
+	}
+	return roundMoney(balance), nil
+}
+
+// checkCreditLimit memastikan kasbon baru tidak membuat saldo melebihi credit_limit
+// Baris pelanggan di-lock (FOR UPDATE) sampai commit agar 2 checkout kasbon
+// bersamaan tidak sama-sama lolos validasi limit.
+func checkCreditLimit(q queryer, customerID int, amount float64) error {
+	var creditLimit float64
+	err := q.QueryRow("SELECT credit_limit FROM customers WHERE id = $1 FOR UPDATE", customerID).Scan(&creditLimit)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("pelanggan dengan ID %d tidak ditemukan", customerID)
+	}
+	if err != nil {
+		return err
+	}
+
+	balance, err := getCreditBalance(q, customerID)
+	if err != nil {
+		return err
+	}
+	if roundMoney(balance+amount) > creditLimit {
+		return &models.CreditLimitExceededError{
+			CustomerID:  customerID,
+			CreditLimit: creditLimit,
+			Outstanding: balance,
+			Requested:   amount,
+		}
+	}
+	return nil
+}
+
+// insertCreditEntry menyimpan 1 baris buku besar kasbon (ID & created_at diisi dari database)
+func insertCreditEntry(q queryer, e *models.CreditLedgerEntry) error {
+	err := q.QueryRow(`
+		INSERT INTO customer_credit_ledger
+			(customer_id, type, amount, transaction_id, sales_return_id, method, reference, note, created_by, shift_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at
+	`, e.CustomerID, e.Type, e.Amount, e.TransactionID, e.SalesReturnID, e.Method, e.Reference, e.Note, e.CreatedBy, e.ShiftID).
+		Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan kasbon: %w", err)
+	}
+	return nil
+}
+
+// sumPaymentMethod menjumlahkan nominal pembayaran untuk 1 metode
+func sumPaymentMethod(payments []models.TransactionPayment, method string) float64 {
+	var total float64
+	for _, p := range payments {
+		if p.Method == method {
+			total += p.Amount
+		}
+	}
+	return roundMoney(total)
+}
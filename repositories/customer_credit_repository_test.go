@@ -0,0 +1,128 @@
+package repositories
+
+import (
+	"errors"
+	"kasir-api/models"
+	"sync"
+	"testing"
+)
+
+// creditCheckout membuat request checkout 1 item yang dibayar dengan tender yang diberikan
+func creditCheckout(productID, customerID, cashierID, quantity int, payments ...models.CheckoutPayment) *models.CheckoutRequest {
+	return &models.CheckoutRequest{
+		Items:      []models.CheckoutItem{{ProductID: productID, Quantity: quantity}},
+		Payments:   payments,
+		CustomerID: &customerID,
+		CreatedBy:  cashierID,
+	}
+}
+
+// getTestCreditBalance mengambil sisa kasbon pelanggan dari buku besar
+func getTestCreditBalance(t *testing.T, repo *TransactionRepository, customerID int) float64 {
+	t.Helper()
+	balance, err := getCreditBalance(repo.db, customerID)
+	if err != nil {
+		t.Fatalf("gagal mengambil sisa kasbon pelanggan %d: %v", customerID, err)
+	}
+	return balance
+}
+
+// TestCreateTransactionCreditLimit: kasbon tersimpan di buku besar, split tender cash + kasbon,
+// dan kasbon di atas limit ditolak ErrCreditLimitExceeded tanpa mengubah saldo maupun stok.
+func TestCreateTransactionCreditLimit(t *testing.T) {
+	db := openTestDB(t)
+	repo := newTestTransactionRepository(db)
+
+	const (
+		price = 10000
+		limit = 50000
+	)
+	productID := createTestProduct(t, db, price, 20)
+	customerID := createTestCustomer(t, db, limit)
+	cashierID := createTestUser(t, db, "kasir")
+
+	// 1. 2 item dibayar penuh dengan kasbon
+	_, err := repo.CreateTransaction(creditCheckout(productID, customerID, cashierID, 2,
+		models.CheckoutPayment{Method: models.PaymentMethodCredit, Amount: 2 * price}))
+	if err != nil {
+		t.Fatalf("checkout kasbon: error = %v, want nil", err)
+	}
+	if balance := getTestCreditBalance(t, repo, customerID); balance != 2*price {
+		t.Fatalf("sisa kasbon = %.0f, want %d", balance, 2*price)
+	}
+
+	// 2. Split tender cash + kasbon, masih di bawah limit
+	_, err = repo.CreateTransaction(creditCheckout(productID, customerID, cashierID, 2,
+		models.CheckoutPayment{Method: models.PaymentMethodCash, Amount: price},
+		models.CheckoutPayment{Method: models.PaymentMethodCredit, Amount: price}))
+	if err != nil {
+		t.Fatalf("checkout cash + kasbon: error = %v, want nil", err)
+	}
+	if balance := getTestCreditBalance(t, repo, customerID); balance != 3*price {
+		t.Fatalf("sisa kasbon = %.0f, want %d", balance, 3*price)
+	}
+
+	// 3. Kasbon yang membuat saldo melebihi limit ditolak
+	stokBefore := getTestStock(t, db, productID)
+	_, err = repo.CreateTransaction(creditCheckout(productID, customerID, cashierID, 3,
+		models.CheckoutPayment{Method: models.PaymentMethodCredit, Amount: 3 * price}))
+	if !errors.Is(err, models.ErrCreditLimitExceeded) {
+		t.Fatalf("checkout di atas limit: error = %v, want ErrCreditLimitExceeded", err)
+	}
+	if balance := getTestCreditBalance(t, repo, customerID); balance != 3*price {
+		t.Errorf("sisa kasbon setelah ditolak = %.0f, want %d", balance, 3*price)
+	}
+	if stok := getTestStock(t, db, productID); stok != stokBefore {
+		t.Errorf("stok setelah ditolak = %d, want %d", stok, stokBefore)
+	}
+}
+
+// TestCreateTransactionCreditLimitConcurrent memastikan lock baris pelanggan:
+// checkout kasbon paralel yang jumlahnya melebihi limit → hanya yang muat di limit sukses,
+// sisanya ErrCreditLimitExceeded, dan sisa kasbon akhir tidak pernah melebihi limit.
+func TestCreateTransactionCreditLimitConcurrent(t *testing.T) {
+	db := openTestDB(t)
+	repo := newTestTransactionRepository(db)
+
+	const (
+		price   = 10000
+		limit   = 50000
+		buyers  = 10
+		allowed = limit / price
+	)
+	productID := createTestProduct(t, db, price, buyers)
+	customerID := createTestCustomer(t, db, limit)
+	cashierID := createTestUser(t, db, "kasir")
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make([]error, buyers)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = repo.CreateTransaction(creditCheckout(productID, customerID, cashierID, 1,
+				models.CheckoutPayment{Method: models.PaymentMethodCredit, Amount: price}))
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for i, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		if !errors.Is(err, models.ErrCreditLimitExceeded) {
+			t.Errorf("checkout #%d: error = %v, want ErrCreditLimitExceeded", i+1, err)
+		}
+	}
+	if succeeded != allowed {
+		t.Errorf("checkout kasbon sukses = %d, want %d", succeeded, allowed)
+	}
+	if balance := getTestCreditBalance(t, repo, customerID); balance != limit {
+		t.Errorf("sisa kasbon akhir = %.0f, want %d (tidak boleh melebihi limit)", balance, limit)
+	}
+}
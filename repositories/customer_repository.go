@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+	"strings"
+)
+
+// CustomerRepository handles database operations for customers
+type CustomerRepository struct {
+	db *sql.DB
+}
+
+// NewCustomerRepository creates a new CustomerRepository
+func NewCustomerRepository(db *sql.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+// customerSelectQuery mengambil data pelanggan + total belanja seumur hidup
+// lifetime_spend = total transaksi tidak dibatalkan - refund retur
+// visit_count / last_visit = jumlah & waktu transaksi terakhir (tidak dibatalkan)
+// credit_balance = sisa kasbon dari customer_credit_ledger
+const customerSelectQuery = `
+	SELECT
+		c.id, c.name, c.phone, c.email, c.address, c.notes, c.is_active, c.created_at, c.updated_at,
+		COALESCE(stats.lifetime_spend, 0) as lifetime_spend,
+		COALESCE(stats.visit_count, 0) as visit_count,
+		stats.last_visit,
+		c.credit_limit,
+		COALESCE(credit.balance, 0) as credit_balance
+	FROM customers c
+	LEFT JOIN (
+		SELECT
+			t.customer_id,
+			SUM(t.total_amount - COALESCE(sr.total_refund, 0)) as lifetime_spend,
+			COUNT(*) as visit_count,
+			MAX(t.created_at) as last_visit
+		FROM transactions t
+		LEFT JOIN (
+			SELECT transaction_id, SUM(total_refund) as total_refund
+			FROM sales_returns
+			GROUP BY transaction_id
+		) sr ON sr.transaction_id = t.id
+		WHERE t.customer_id IS NOT NULL AND t.voided_at IS NULL
+		GROUP BY t.customer_id
+	) stats ON stats.customer_id = c.id
+	LEFT JOIN (
+		SELECT customer_id, SUM(CASE WHEN type = 'charge' THEN amount ELSE -amount END) as balance
+		FROM customer_credit_ledger
+		GROUP BY customer_id
+	) credit ON credit.customer_id = c.id
+`
+
+// customerSortColumns = urutan yang boleh dipakai di GET /api/customers?sort=
+var customerSortColumns = map[string]string{
+	"name":   "c.name ASC, c.id ASC",
+	"spend":  "lifetime_spend DESC, c.id ASC",
+	"visits": "visit_count DESC, c.id ASC",
+	"recent": "stats.last_visit DESC NULLS LAST, c.id ASC",
+}
+
+func scanCustomer(row rowScanner) (*models.Customer, error) {
+	var c models.Customer
+	err := row.Scan(
+		&c.ID, &c.Name, &c.Phone, &c.Email, &c.Address, &c.Notes, &c.IsActive, &c.CreatedAt, &c.UpdatedAt,
+		&c.LifetimeSpend, &c.VisitCount, &c.LastVisit,
+		&c.CreditLimit, &c.CreditBalance,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetAll mengambil pelanggan dengan pencarian nama / no. HP dan pagination
+// search dicocokkan ke nama (ILIKE) atau awalan no. HP (sudah dinormalisasi)
+// Return: customers, total count, error
+func (r *CustomerRepository) GetAll(search string, sort string, pagination *models.PaginationParams) ([]models.Customer, int, error) {
+	where := ""
+	var args []interface{}
+	if search != "" {
+		where = " WHERE c.name ILIKE $1"
+		args = append(args, "%"+search+"%")
+		if phone := models.NormalizePhone(search); phone != "" {
+			where += " OR c.phone LIKE $2"
+			args = append(args, phone+"%")
+		}
+	}
+
+	var totalItems int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM customers c"+where, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung pelanggan: %w", err)
+	}
+
+	orderBy, ok := customerSortColumns[sort]
+	if !ok {
+		orderBy = "c.id DESC"
+	}
+	query := customerSelectQuery + where + " ORDER BY " + orderBy
+	if pagination != nil {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+		args = append(args, pagination.Limit, pagination.GetOffset())
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal mengambil pelanggan: %w", err)
+	}
+	defer rows.Close()
+
+	customers := []models.Customer{}
+	for rows.Next() {
+		c, err := scanCustomer(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("gagal membaca pelanggan: %w", err)
+		}
+		customers = append(customers, *c)
+	}
+	return customers, totalItems, nil
+}
+
+// GetByID mengambil pelanggan by ID (beserta total belanja)
+func (r *CustomerRepository) GetByID(id int) (*models.Customer, error) {
+	c, err := scanCustomer(r.db.QueryRow(customerSelectQuery+" WHERE c.id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pelanggan dengan ID %d tidak ditemukan", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetByPhone mengambil pelanggan by no. HP (natural key, sudah dinormalisasi)
+func (r *CustomerRepository) GetByPhone(phone string) (*models.Customer, error) {
+	c, err := scanCustomer(r.db.QueryRow(customerSelectQuery+" WHERE c.phone = $1", phone))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pelanggan dengan no. HP %s tidak ditemukan", phone)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Create menambahkan pelanggan baru
+// No. HP yang sudah terdaftar → models.ErrCustomerPhoneExists
+func (r *CustomerRepository) Create(c *models.Customer) error {
+	err := r.db.QueryRow(`
+		INSERT INTO customers (name, phone, email, address, notes, is_active, credit_limit)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`, c.Name, c.Phone, c.Email, c.Address, c.Notes, c.IsActive, c.CreditLimit).Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return models.ErrCustomerPhoneExists
+		}
+		return fmt.Errorf("gagal menyimpan pelanggan: %w", err)
+	}
+
+	log.Printf("👤 Pelanggan dibuat: ID=%d, %s (%s)", c.ID, c.Name, c.Phone)
+	return nil
+}
+
+// Update mengubah data pelanggan
+func (r *CustomerRepository) Update(c *models.Customer) error {
+	err := r.db.QueryRow(`
+		UPDATE customers
+		SET name = $1, phone = $2, email = $3, address = $4, notes = $5, is_active = $6, credit_limit = $7, updated_at = NOW()
+		WHERE id = $8
+		RETURNING created_at, updated_at
+	`, c.Name, c.Phone, c.Email, c.Address, c.Notes, c.IsActive, c.CreditLimit, c.ID).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("pelanggan dengan ID %d tidak ditemukan", c.ID)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return models.ErrCustomerPhoneExists
+		}
+		return fmt.Errorf("gagal mengupdate pelanggan: %w", err)
+	}
+	return nil
+}
+
+// SoftDelete menonaktifkan pelanggan
+// Data tidak dihapus agar riwayat transaksi pelanggan tetap utuh
+func (r *CustomerRepository) SoftDelete(id int) error {
+	result, err := r.db.Exec("UPDATE customers SET is_active = FALSE, updated_at = NOW() WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("pelanggan dengan ID %d tidak ditemukan", id)
+	}
+	return nil
+}
+
+// GetTransactions mengambil riwayat transaksi pelanggan (terbaru dulu, termasuk yang dibatalkan)
+// Return: transactions, total count, error
+func (r *CustomerRepository) GetTransactions(customerID int, pagination *models.PaginationParams) ([]models.Transaction, int, error) {
+	var totalItems int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM transactions WHERE customer_id = $1", customerID).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung transaksi pelanggan: %w", err)
+	}
+
+	query := `
+		SELECT
+			t.id,
+			t.total_amount,
+			t.discount_id,
+			t.discount_amount,
+			COALESCE(t.payment_amount, 0) as payment_amount,
+			COALESCE(t.change_amount, 0) as change_amount,
+			t.created_at,
+			COALESCE(hpp.total_qty, 0) as total_items,
+			t.created_by,
+			COALESCE(u.username, '') as username,
+			t.voided_at,
+			t.voided_by,
+			t.void_reason,
+			COALESCE(t.tax_amount, 0) as tax_amount,
+			COALESCE(t.service_charge_amount, 0) as service_charge_amount,
+			COALESCE(t.rounding_amount, 0) as rounding_amount,
+			t.customer_id
+		FROM transactions t
+		LEFT JOIN (
+			SELECT td.transaction_id, SUM(td.quantity) as total_qty
+			FROM transaction_details td
+			GROUP BY td.transaction_id
+		) hpp ON hpp.transaction_id = t.id
+		LEFT JOIN users u ON t.created_by = u.id
+		WHERE t.customer_id = $1
+		ORDER BY t.created_at DESC
+	`
+	args := []interface{}{customerID}
+	if pagination != nil {
+		query += " LIMIT $2 OFFSET $3"
+		args = append(args, pagination.Limit, pagination.GetOffset())
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal mengambil transaksi pelanggan: %w", err)
+	}
+	defer rows.Close()
+
+	transactions := []models.Transaction{}
+	for rows.Next() {
+		var t models.Transaction
+		err := rows.Scan(&t.ID, &t.TotalAmount, &t.DiscountID, &t.DiscountAmount, &t.PaymentAmount, &t.ChangeAmount, &t.CreatedAt,
+			&t.TotalItems, &t.CreatedBy, &t.Username, &t.VoidedAt, &t.VoidedBy, &t.VoidReason,
+			&t.TaxAmount, &t.ServiceChargeAmount, &t.RoundingAmount, &t.CustomerID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("gagal membaca transaksi pelanggan: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, totalItems, nil
+}
+
+// checkCustomerActive memastikan pelanggan ada dan aktif sebelum dihubungkan ke transaksi
+func checkCustomerActive(q queryer, customerID int) error {
+	var isActive bool
+	err := q.QueryRow("SELECT is_active FROM customers WHERE id = $1", customerID).Scan(&isActive)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("pelanggan dengan ID %d tidak ditemukan", customerID)
+	}
+	if err != nil {
+		return err
+	}
+	if !isActive {
+		return models.ErrCustomerInactive
+	}
+	return nil
+}
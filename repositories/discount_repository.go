@@ -2,33 +2,120 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
 	"kasir-api/models"
+	"time"
 )
 
 // DiscountRepository handles database operations for discounts
 type DiscountRepository struct {
-	db *sql.DB
+	db       *sql.DB
+	storeLoc *time.Location
 }
 
 // NewDiscountRepository creates a new DiscountRepository
-func NewDiscountRepository(db *sql.DB) *DiscountRepository {
-	return &DiscountRepository{db: db}
+// storeLoc = timezone toko untuk jadwal hari / jam diskon (STORE_TIMEZONE)
+func NewDiscountRepository(db *sql.DB, storeLoc *time.Location) *DiscountRepository {
+	return &DiscountRepository{db: db, storeLoc: storeLoc}
+}
+
+// daysOfWeekColumn membaca kolom days_of_week ("1,5" / NULL) ke []int
+type daysOfWeekColumn struct {
+	days *[]int
+}
+
+func (c daysOfWeekColumn) Scan(src interface{}) error {
+	var s sql.NullString
+	if err := s.Scan(src); err != nil {
+		return err
+	}
+	*c.days = nil
+	if s.Valid && s.String != "" {
+		*c.days = models.ParseDaysOfWeek(s.String)
+	}
+	return nil
 }
 
 // Create inserts a new discount into the database
+// Isi paket (BUNDLE) & tier harga (QTY_TIER) disimpan dalam 1 database transaction
 func (r *DiscountRepository) Create(d *models.Discount) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
 	query := `
-		INSERT INTO discounts (name, type, value, min_order_amount, start_date, end_date, is_active, product_id, category_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO discounts (name, type, value, min_order_amount, start_date, end_date, is_active, product_id, category_id, buy_qty, get_qty,
+			stacking_mode, priority, max_total_percent, days_of_week, time_start, time_end)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		RETURNING id
 	`
-	return r.db.QueryRow(query, d.Name, d.Type, d.Value, d.MinOrderAmount, d.StartDate, d.EndDate, d.IsActive, d.ProductID, d.CategoryID).Scan(&d.ID)
+	err = tx.QueryRow(query, d.Name, d.Type, d.Value, d.MinOrderAmount, d.StartDate, d.EndDate, d.IsActive, d.ProductID, d.CategoryID, d.BuyQty, d.GetQty,
+		d.StackingMode, d.Priority, d.MaxTotalPercent, models.FormatDaysOfWeek(d.DaysOfWeek), d.TimeStart, d.TimeEnd).Scan(&d.ID)
+	if err != nil {
+		return err
+	}
+	err = savePromotionRules(tx, d.ID, d)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	return err
+}
+
+// savePromotionRules mengganti isi paket & tier harga 1 diskon
+func savePromotionRules(tx *sql.Tx, discountID int, d *models.Discount) error {
+	if _, err := tx.Exec("DELETE FROM discount_bundle_items WHERE discount_id = $1", discountID); err != nil {
+		return fmt.Errorf("gagal menghapus isi paket: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM discount_qty_tiers WHERE discount_id = $1", discountID); err != nil {
+		return fmt.Errorf("gagal menghapus tier harga: %w", err)
+	}
+	if d.Type == models.DiscountBundle {
+		for _, item := range d.BundleItems {
+			_, err := tx.Exec("INSERT INTO discount_bundle_items (discount_id, product_id, quantity) VALUES ($1, $2, $3)",
+				discountID, item.ProductID, item.Quantity)
+			if err != nil {
+				return fmt.Errorf("gagal menyimpan isi paket (produk ID %d): %w", item.ProductID, err)
+			}
+		}
+	}
+	if d.Type == models.DiscountQtyTier {
+		for _, tier := range d.Tiers {
+			_, err := tx.Exec("INSERT INTO discount_qty_tiers (discount_id, min_qty, unit_price) VALUES ($1, $2, $3)",
+				discountID, tier.MinQty, tier.UnitPrice)
+			if err != nil {
+				return fmt.Errorf("gagal menyimpan tier harga: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// attachPromotionRules mengisi BundleItems & Tiers untuk list diskon
+func (r *DiscountRepository) attachPromotionRules(discounts []models.Discount) error {
+	byID := make(map[int]*models.Discount)
+	for i := range discounts {
+		if discounts[i].Type == models.DiscountBundle || discounts[i].Type == models.DiscountQtyTier {
+			byID[discounts[i].ID] = &discounts[i]
+		}
+	}
+	if len(byID) == 0 {
+		return nil
+	}
+	return loadPromotionRules(r.db, byID)
 }
 
 // GetAll returns all discounts (for admin management)
 func (r *DiscountRepository) GetAll() ([]models.Discount, error) {
 	query := `
-		SELECT id, name, type, value, min_order_amount, start_date, end_date, is_active, product_id, category_id 
+		SELECT id, name, type, value, min_order_amount, start_date, end_date, is_active, product_id, category_id, buy_qty, get_qty,
+			stacking_mode, priority, max_total_percent, days_of_week, time_start, time_end
 		FROM discounts 
 		ORDER BY start_date DESC`
 	rows, err := r.db.Query(query)
@@ -40,24 +127,31 @@ func (r *DiscountRepository) GetAll() ([]models.Discount, error) {
 	var discounts []models.Discount
 	for rows.Next() {
 		var d models.Discount
-		if err := rows.Scan(&d.ID, &d.Name, &d.Type, &d.Value, &d.MinOrderAmount, &d.StartDate, &d.EndDate, &d.IsActive, &d.ProductID, &d.CategoryID); err != nil {
+		if err := rows.Scan(&d.ID, &d.Name, &d.Type, &d.Value, &d.MinOrderAmount, &d.StartDate, &d.EndDate, &d.IsActive, &d.ProductID, &d.CategoryID, &d.BuyQty, &d.GetQty,
+			&d.StackingMode, &d.Priority, &d.MaxTotalPercent, daysOfWeekColumn{&d.DaysOfWeek}, &d.TimeStart, &d.TimeEnd); err != nil {
 			return nil, err
 		}
 		discounts = append(discounts, d)
 	}
+	if err := r.attachPromotionRules(discounts); err != nil {
+		return nil, err
+	}
 	return discounts, nil
 }
 
 // GetActive returns only active and valid GLOBAL discounts (for cashier selection)
 // Product and Category discounts are applied automatically, not selected manually.
 // So this should return ONLY Global discounts (product_id IS NULL AND category_id IS NULL)
+// Diskon terjadwal (hari / jam) hanya muncul di dalam jadwalnya menurut timezone toko.
 func (r *DiscountRepository) GetActive() ([]models.Discount, error) {
 	query := `
-		SELECT id, name, type, value, min_order_amount, start_date, end_date, is_active, product_id, category_id
+		SELECT id, name, type, value, min_order_amount, start_date, end_date, is_active, product_id, category_id, buy_qty, get_qty,
+			stacking_mode, priority, max_total_percent, days_of_week, time_start, time_end
 		FROM discounts 
 		WHERE is_active = TRUE 
 		AND product_id IS NULL
 		AND category_id IS NULL
+		AND type IN ('PERCENTAGE', 'FIXED')
 		AND NOW() BETWEEN start_date AND end_date
 		ORDER BY min_order_amount ASC
 	`
@@ -67,12 +161,17 @@ func (r *DiscountRepository) GetActive() ([]models.Discount, error) {
 	}
 	defer rows.Close()
 
+	now := time.Now()
 	var discounts []models.Discount
 	for rows.Next() {
 		var d models.Discount
-		if err := rows.Scan(&d.ID, &d.Name, &d.Type, &d.Value, &d.MinOrderAmount, &d.StartDate, &d.EndDate, &d.IsActive, &d.ProductID, &d.CategoryID); err != nil {
+		if err := rows.Scan(&d.ID, &d.Name, &d.Type, &d.Value, &d.MinOrderAmount, &d.StartDate, &d.EndDate, &d.IsActive, &d.ProductID, &d.CategoryID, &d.BuyQty, &d.GetQty,
+			&d.StackingMode, &d.Priority, &d.MaxTotalPercent, daysOfWeekColumn{&d.DaysOfWeek}, &d.TimeStart, &d.TimeEnd); err != nil {
 			return nil, err
 		}
+		if !d.IsScheduledAt(now, r.storeLoc) {
+			continue
+		}
 		discounts = append(discounts, d)
 	}
 	return discounts, nil
@@ -80,23 +179,50 @@ func (r *DiscountRepository) GetActive() ([]models.Discount, error) {
 
 // GetByID returns a discount by ID
 func (r *DiscountRepository) GetByID(id int) (*models.Discount, error) {
-	query := `SELECT id, name, type, value, min_order_amount, start_date, end_date, is_active, product_id, category_id FROM discounts WHERE id = $1`
+	query := `SELECT id, name, type, value, min_order_amount, start_date, end_date, is_active, product_id, category_id, buy_qty, get_qty,
+			stacking_mode, priority, max_total_percent, days_of_week, time_start, time_end FROM discounts WHERE id = $1`
 	var d models.Discount
-	err := r.db.QueryRow(query, id).Scan(&d.ID, &d.Name, &d.Type, &d.Value, &d.MinOrderAmount, &d.StartDate, &d.EndDate, &d.IsActive, &d.ProductID, &d.CategoryID)
+	err := r.db.QueryRow(query, id).Scan(&d.ID, &d.Name, &d.Type, &d.Value, &d.MinOrderAmount, &d.StartDate, &d.EndDate, &d.IsActive, &d.ProductID, &d.CategoryID, &d.BuyQty, &d.GetQty,
+		&d.StackingMode, &d.Priority, &d.MaxTotalPercent, daysOfWeekColumn{&d.DaysOfWeek}, &d.TimeStart, &d.TimeEnd)
 	if err != nil {
 		return nil, err
 	}
-	return &d, nil
+	list := []models.Discount{d}
+	if err := r.attachPromotionRules(list); err != nil {
+		return nil, err
+	}
+	return &list[0], nil
 }
 
-// Update updates an existing discount
+// Update updates an existing discount (isi paket & tier harga diganti seluruhnya)
 func (r *DiscountRepository) Update(id int, d *models.Discount) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
 	query := `
 		UPDATE discounts 
-		SET name=$1, type=$2, value=$3, min_order_amount=$4, start_date=$5, end_date=$6, is_active=$7, product_id=$8, category_id=$9
-		WHERE id=$10
+		SET name=$1, type=$2, value=$3, min_order_amount=$4, start_date=$5, end_date=$6, is_active=$7, product_id=$8, category_id=$9,
+			buy_qty=$10, get_qty=$11, stacking_mode=$12, priority=$13, max_total_percent=$14,
+			days_of_week=$15, time_start=$16, time_end=$17
+		WHERE id=$18
 	`
-	_, err := r.db.Exec(query, d.Name, d.Type, d.Value, d.MinOrderAmount, d.StartDate, d.EndDate, d.IsActive, d.ProductID, d.CategoryID, id)
+	_, err = tx.Exec(query, d.Name, d.Type, d.Value, d.MinOrderAmount, d.StartDate, d.EndDate, d.IsActive, d.ProductID, d.CategoryID, d.BuyQty, d.GetQty,
+		d.StackingMode, d.Priority, d.MaxTotalPercent, models.FormatDaysOfWeek(d.DaysOfWeek), d.TimeStart, d.TimeEnd, id)
+	if err != nil {
+		return err
+	}
+	err = savePromotionRules(tx, id, d)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
 	return err
 }
 
@@ -0,0 +1,410 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+	"sort"
+	"time"
+)
+
+// discountColumns adalah kolom diskon yang dibutuhkan pricing engine (termasuk aturan stacking)
+// dan jadwal hari / jam
+const discountColumns = "id, name, type, value, min_order_amount, product_id, category_id, stacking_mode, priority, max_total_percent, " +
+	"days_of_week, time_start, time_end"
+
+func scanPricingDiscount(row rowScanner) (*models.Discount, error) {
+	var d models.Discount
+	err := row.Scan(&d.ID, &d.Name, &d.Type, &d.Value, &d.MinOrderAmount, &d.ProductID, &d.CategoryID,
+		&d.StackingMode, &d.Priority, &d.MaxTotalPercent, daysOfWeekColumn{&d.DaysOfWeek}, &d.TimeStart, &d.TimeEnd)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// loadItemDiscounts mengambil semua diskon produk & kategori (PERCENTAGE / FIXED) yang aktif saat ini (1 query)
+// Diskon terjadwal di luar hari / jamnya (timezone toko loc) tidak ikut.
+func loadItemDiscounts(q queryer, now time.Time, loc *time.Location) ([]*models.Discount, error) {
+	rows, err := q.Query(`
+		SELECT ` + discountColumns + ` FROM discounts
+		WHERE is_active = TRUE AND NOW() BETWEEN start_date AND end_date
+		AND (product_id IS NOT NULL OR category_id IS NOT NULL)
+		AND type IN ('PERCENTAGE', 'FIXED')
+		ORDER BY value DESC, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data diskon: %w", err)
+	}
+	defer rows.Close()
+
+	var discounts []*models.Discount
+	for rows.Next() {
+		d, err := scanPricingDiscount(rows)
+		if err != nil {
+			return nil, err
+		}
+		// Jika diskon punya KEDUA product_id DAN category_id → data tidak valid, skip
+		if d.ProductID != nil && d.CategoryID != nil {
+			log.Printf("⚠️ Diskon ID %d punya product_id DAN category_id sekaligus — diskip (data tidak valid)", d.ID)
+			continue
+		}
+		if !d.IsScheduledAt(now, loc) {
+			continue
+		}
+		discounts = append(discounts, d)
+	}
+	return discounts, nil
+}
+
+// loadOrderDiscount mengambil diskon transaksi (discount_id) yang dipilih kasir
+// Diskon terjadwal ditolak di luar hari / jamnya (timezone toko loc).
+func loadOrderDiscount(q queryer, id int, now time.Time, loc *time.Location) (*models.Discount, error) {
+	var isValid bool
+	var d models.Discount
+	err := q.QueryRow(`
+		SELECT `+discountColumns+`, (NOW() BETWEEN start_date AND end_date) as is_valid
+		FROM discounts WHERE id = $1 AND is_active = TRUE
+		AND product_id IS NULL AND category_id IS NULL AND type IN ('PERCENTAGE', 'FIXED')`, id).Scan(
+		&d.ID, &d.Name, &d.Type, &d.Value, &d.MinOrderAmount, &d.ProductID, &d.CategoryID,
+		&d.StackingMode, &d.Priority, &d.MaxTotalPercent, daysOfWeekColumn{&d.DaysOfWeek}, &d.TimeStart, &d.TimeEnd, &isValid,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("diskon global ID %d tidak valid atau (mungkin diskon produk/kategori)", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !isValid {
+		return nil, fmt.Errorf("diskon global sudah kedaluwarsa")
+	}
+	if !d.IsScheduledAt(now, loc) {
+		return nil, fmt.Errorf("%w: %s", models.ErrDiscountOutsideSchedule, d.Name)
+	}
+	return &d, nil
+}
+
+// discountRule adalah 1 diskon yang ikut dievaluasi aturan stacking
+type discountRule struct {
+	discount *models.Discount
+	level    string
+	voucher  *models.Voucher // Diisi untuk diskon transaksi dari voucher_code
+}
+
+// levelRank: dengan priority yang sama, diskon produk/kategori → promosi → diskon transaksi
+var levelRank = map[string]int{
+	models.DiscountLevelItem:      0,
+	models.DiscountLevelPromotion: 1,
+	models.DiscountLevelOrder:     2,
+}
+
+// sortDiscountRules mengurutkan rule: priority terbesar dulu, lalu level, lalu
+// diskon produk sebelum kategori (nilai terbesar dulu) dan urutan tipe promosi
+func sortDiscountRules(rules []*discountRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.discount.Priority != b.discount.Priority {
+			return a.discount.Priority > b.discount.Priority
+		}
+		if levelRank[a.level] != levelRank[b.level] {
+			return levelRank[a.level] < levelRank[b.level]
+		}
+		switch a.level {
+		case models.DiscountLevelItem:
+			if (a.discount.ProductID != nil) != (b.discount.ProductID != nil) {
+				return a.discount.ProductID != nil
+			}
+			if a.discount.Value != b.discount.Value {
+				return a.discount.Value > b.discount.Value
+			}
+		case models.DiscountLevelPromotion:
+			if promotionRank[a.discount.Type] != promotionRank[b.discount.Type] {
+				return promotionRank[a.discount.Type] < promotionRank[b.discount.Type]
+			}
+		}
+		return a.discount.ID < b.discount.ID
+	})
+}
+
+// minOrderAmount mengembalikan minimal belanja diskon transaksi (diskon / voucher)
+func (r *discountRule) minOrderAmount() float64 {
+	if r.voucher != nil {
+		return r.voucher.MinOrderAmount
+	}
+	return r.discount.MinOrderAmount
+}
+
+// lineStacking adalah status stacking 1 baris keranjang
+type lineStacking struct {
+	skipAuto      bool     // Diskon manual / override harga: tanpa diskon otomatis & promosi
+	locked        bool     // Sudah dapat diskon EXCLUSIVE
+	applied       int      // Jumlah diskon yang sudah dipakai
+	itemDone      bool     // Maksimal 1 diskon produk/kategori per baris
+	promoDone     bool     // Maksimal 1 promosi per baris
+	orderEligible bool     // Baris ikut dihitung diskon transaksi
+	capPercent    *float64 // max_total_percent terkecil dari diskon yang dipakai
+	capped        bool     // Potongan terakhir dikurangi oleh capPercent
+}
+
+// appliedDiscount adalah 1 baris audit beserta index baris keranjangnya
+type appliedDiscount struct {
+	line int
+	models.TransactionDiscount
+}
+
+// discountStacker menerapkan aturan stacking ke baris keranjang
+type discountStacker struct {
+	details  []models.TransactionDetail
+	products map[int]*pricingProduct
+	lines    []lineStacking
+	applied  []appliedDiscount
+}
+
+// canApply mengecek aturan stacking: baris eksklusif tertutup, diskon EXCLUSIVE hanya di baris tanpa diskon
+func (s *discountStacker) canApply(i int, d *models.Discount) bool {
+	line := &s.lines[i]
+	if line.locked {
+		return false
+	}
+	return d.StackingMode != models.StackingExclusive || line.applied == 0
+}
+
+// matchesScope mengecek apakah baris i termasuk produk / kategori diskon
+func (s *discountStacker) matchesScope(i int, d *models.Discount) bool {
+	pid := s.details[i].ProductID
+	if d.ProductID != nil {
+		return pid == *d.ProductID
+	}
+	if d.CategoryID != nil {
+		p := s.products[pid]
+		return p != nil && p.CategoryID.Valid && int(p.CategoryID.Int64) == *d.CategoryID
+	}
+	return true
+}
+
+// limit memotong amount agar total diskon baris tidak melebihi max_total_percent
+// (batas terkecil dari diskon yang sudah dipakai dan diskon d)
+func (s *discountStacker) limit(i int, d *models.Discount, amount float64) float64 {
+	line := &s.lines[i]
+	line.capped = false
+	capPercent := line.capPercent
+	if d.MaxTotalPercent != nil && (capPercent == nil || *d.MaxTotalPercent < *capPercent) {
+		capPercent = d.MaxTotalPercent
+	}
+	if capPercent == nil {
+		return amount
+	}
+	detail := &s.details[i]
+	maxAmount := detail.Price*float64(detail.Quantity)*(*capPercent/100) - detail.DiscountAmount - detail.OrderDiscountAmount
+	if amount > maxAmount {
+		line.capped = true
+		if maxAmount < 0 {
+			return 0
+		}
+		return maxAmount
+	}
+	return amount
+}
+
+// record mencatat diskon yang dipakai di baris i (audit) dan memperbarui status stacking
+func (s *discountStacker) record(i int, rule *discountRule, name string, amount float64) {
+	line := &s.lines[i]
+	d := rule.discount
+	line.applied++
+	if d.StackingMode == models.StackingExclusive {
+		line.locked = true
+	}
+	if d.MaxTotalPercent != nil && (line.capPercent == nil || *d.MaxTotalPercent < *line.capPercent) {
+		line.capPercent = d.MaxTotalPercent
+	}
+
+	entry := models.TransactionDiscount{
+		ProductID:    s.details[i].ProductID,
+		Level:        rule.level,
+		Name:         name,
+		StackingMode: d.StackingMode,
+		Priority:     d.Priority,
+		Amount:       amount,
+		Capped:       line.capped,
+	}
+	if rule.voucher != nil {
+		entry.VoucherID = &rule.voucher.ID
+	} else if d.ID > 0 {
+		id := d.ID
+		entry.DiscountID = &id
+	}
+	s.applied = append(s.applied, appliedDiscount{line: i, TransactionDiscount: entry})
+}
+
+// applyItemDiscount: diskon produk/kategori dihitung dari harga bersih baris saat ini
+func (s *discountStacker) applyItemDiscount(rule *discountRule) {
+	d := rule.discount
+	for i := range s.details {
+		line := &s.lines[i]
+		if line.skipAuto || line.itemDone || !s.matchesScope(i, d) || !s.canApply(i, d) {
+			continue
+		}
+		detail := &s.details[i]
+		qty := float64(detail.Quantity)
+		netUnit := (detail.Price*qty - detail.DiscountAmount) / qty
+
+		var perUnit float64
+		discountType := "fixed"
+		if d.Type == models.DiscountPercentage {
+			perUnit = netUnit * (d.Value / 100)
+			discountType = "percentage"
+		} else {
+			perUnit = d.Value
+		}
+		if perUnit > netUnit {
+			perUnit = netUnit
+		}
+		amount := roundMoney(s.limit(i, d, perUnit*qty))
+		if amount <= 0 {
+			continue
+		}
+
+		detail.DiscountAmount += amount
+		detail.DiscountType = discountType
+		detail.DiscountValue = d.Value
+		line.itemDone = true
+		s.record(i, rule, d.Name, amount)
+	}
+}
+
+// applyPromotion menghitung 1 promosi di baris yang lolos aturan stacking
+func (s *discountStacker) applyPromotion(rule *discountRule) float64 {
+	d := rule.discount
+	eligible := make([]bool, len(s.details))
+	for i := range s.details {
+		eligible[i] = !s.lines[i].skipAuto && !s.lines[i].promoDone && s.canApply(i, d)
+	}
+	cart := &promotionCart{
+		details:  s.details,
+		products: s.products,
+		eligible: eligible,
+		limit:    func(i int, amount float64) float64 { return s.limit(i, d, amount) },
+	}
+	before := make([]float64, len(s.details))
+	for i := range s.details {
+		before[i] = s.details[i].PromotionAmount
+	}
+
+	used, total := cart.apply(d)
+	if total <= 0 {
+		return 0
+	}
+	for _, i := range used {
+		s.lines[i].promoDone = true
+		if s.details[i].PromotionID != nil && *s.details[i].PromotionID == d.ID && s.details[i].PromotionAmount != before[i] {
+			s.record(i, rule, d.Name, s.details[i].PromotionAmount)
+		}
+	}
+	return total
+}
+
+// reserveOrderDiscount menandai baris yang ikut diskon transaksi (sesuai urutan priority).
+// Nominalnya dihitung belakangan dari subtotal akhir (applyOrderDiscount).
+// Return false jika tidak ada baris yang boleh memakai diskon transaksi.
+func (s *discountStacker) reserveOrderDiscount(rule *discountRule) bool {
+	d := rule.discount
+	reserved := false
+	for i := range s.details {
+		if !s.canApply(i, d) {
+			continue
+		}
+		// Reservasi dihitung sebagai diskon terpakai: diskon EXCLUSIVE berikutnya tidak masuk baris ini
+		s.lines[i].orderEligible = true
+		s.lines[i].applied++
+		if d.StackingMode == models.StackingExclusive {
+			s.lines[i].locked = true
+		}
+		reserved = true
+	}
+	return reserved
+}
+
+// applyOrderDiscount menghitung diskon transaksi dari subtotal baris yang ikut,
+// lalu membaginya proporsional per baris (OrderDiscountAmount) dengan batas max_total_percent.
+// Return: total diskon transaksi
+func (s *discountStacker) applyOrderDiscount(rule *discountRule, name string) float64 {
+	d := rule.discount
+	var base float64
+	lastLine := -1
+	for i := range s.details {
+		if s.lines[i].orderEligible {
+			base += s.details[i].Subtotal
+			lastLine = i
+		}
+	}
+	if base <= 0 {
+		return 0
+	}
+
+	var total float64
+	if d.Type == models.DiscountPercentage {
+		total = roundMoney(base * (d.Value / 100))
+	} else {
+		total = d.Value
+	}
+	if total > base {
+		total = base
+	}
+
+	var sum, distributed float64
+	for i := range s.details {
+		if !s.lines[i].orderEligible {
+			continue
+		}
+		share := roundMoney(total * s.details[i].Subtotal / base)
+		if i == lastLine {
+			share = roundMoney(total - distributed) // sisa pembulatan di baris terakhir
+		}
+		distributed += share
+		share = roundMoney(s.limit(i, d, share))
+		if share <= 0 {
+			continue
+		}
+		s.details[i].OrderDiscountAmount = share
+		s.record(i, rule, name, share)
+		sum += share
+	}
+	return roundMoney(sum)
+}
+
+// recordManualDiscount mencatat diskon manual (admin / persetujuan supervisor) untuk audit
+func (s *discountStacker) recordManualDiscount(i int) {
+	s.lines[i].applied++
+	s.applied = append(s.applied, appliedDiscount{line: i, TransactionDiscount: models.TransactionDiscount{
+		ProductID:    s.details[i].ProductID,
+		Level:        models.DiscountLevelManual,
+		Name:         "Diskon manual",
+		StackingMode: models.StackingStackable,
+		Amount:       s.details[i].DiscountAmount,
+	}})
+}
+
+// insertTransactionDiscounts menyimpan audit kombinasi diskon (batch insert)
+// details harus sudah berisi ID baris transaction_details
+func insertTransactionDiscounts(tx *sql.Tx, transactionID int, details []models.TransactionDetail, applied []appliedDiscount) error {
+	if len(applied) == 0 {
+		return nil
+	}
+	query := `INSERT INTO transaction_discounts (transaction_id, transaction_detail_id, discount_id, voucher_id,
+		level, name, stacking_mode, priority, amount, capped) VALUES `
+	values := make([]interface{}, 0, len(applied)*10)
+	for i, a := range applied {
+		if i > 0 {
+			query += ", "
+		}
+		query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			i*10+1, i*10+2, i*10+3, i*10+4, i*10+5, i*10+6, i*10+7, i*10+8, i*10+9, i*10+10)
+		values = append(values, transactionID, details[a.line].ID, a.DiscountID, a.VoucherID,
+			a.Level, a.Name, a.StackingMode, a.Priority, a.Amount, a.Capped)
+	}
+	if _, err := tx.Exec(query, values...); err != nil {
+		return fmt.Errorf("gagal menyimpan audit diskon: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"kasir-api/models"
+	"log"
+)
+
+// HeldCartRepository handles database operations for held (parked) carts
+type HeldCartRepository struct {
+	db *sql.DB
+}
+
+// NewHeldCartRepository creates a new HeldCartRepository
+func NewHeldCartRepository(db *sql.DB) *HeldCartRepository {
+	return &HeldCartRepository{db: db}
+}
+
+// Create menyimpan keranjang yang di-park
+// Jika reserveStock = true, stok item di-reservasi selama reservationMinutes menit:
+// produk di-lock (FOR UPDATE) lalu dicek stok tersedia = stok - reservasi cart lain yang masih aktif.
+func (r *HeldCartRepository) Create(userID int, label *string, cart *models.CheckoutRequest, reserveStock bool, reservationMinutes int) (*models.HeldCart, error) {
+	payload, err := json.Marshal(cart)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan keranjang: %w", err)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// ─── STEP 1: Insert header cart ───
+	var heldCart models.HeldCart
+	var expiresAt sql.NullTime
+	err = tx.QueryRow(`
+		INSERT INTO held_carts (user_id, label, payload, reserve_stock, expires_at)
+		VALUES ($1, $2, $3, $4, CASE WHEN $4 THEN NOW() + make_interval(mins => $5) ELSE NULL END)
+		RETURNING id, expires_at, created_at, updated_at
+	`, userID, label, string(payload), reserveStock, reservationMinutes).Scan(
+		&heldCart.ID, &expiresAt, &heldCart.CreatedAt, &heldCart.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan keranjang: %w", err)
+	}
+
+	// ─── STEP 2: Reservasi stok (opsional) ───
+	if reserveStock {
+		requestedQty := make(map[int]int)
+		productIDArgs := make([]interface{}, 0, len(cart.Items))
+		placeholders := ""
+		for _, item := range cart.Items {
+			if _, seen := requestedQty[item.ProductID]; !seen {
+				productIDArgs = append(productIDArgs, item.ProductID)
+				if len(productIDArgs) > 1 {
+					placeholders += ", "
+				}
+				placeholders += fmt.Sprintf("$%d", len(productIDArgs))
+			}
+			requestedQty[item.ProductID] += item.Quantity
+		}
+
+		// Lock produk (urutan id) agar reservasi & checkout paralel tidak saling menimpa
+		var rows *sql.Rows
+		rows, err = tx.Query(
+			fmt.Sprintf("SELECT id, nama, stok FROM products WHERE id IN (%s) ORDER BY id FOR UPDATE", placeholders),
+			productIDArgs...,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("gagal mengambil data produk: %w", err)
+		}
+		type productInfo struct {
+			Name string
+			Stok int
+		}
+		productMap := make(map[int]productInfo)
+		for rows.Next() {
+			var id int
+			var p productInfo
+			if err = rows.Scan(&id, &p.Name, &p.Stok); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			productMap[id] = p
+		}
+		rows.Close()
+
+		var reserved map[int]int
+		reserved, err = getReservedStock(tx, productIDArgs, &heldCart.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, arg := range productIDArgs {
+			productID := arg.(int)
+			p, exists := productMap[productID]
+			if !exists {
+				err = fmt.Errorf("produk dengan ID %d tidak ditemukan", productID)
+				return nil, err
+			}
+			available := p.Stok - reserved[productID]
+			if available < requestedQty[productID] {
+				err = &models.InsufficientStockError{
+					ProductID:   productID,
+					ProductName: p.Name,
+					Available:   available,
+					Requested:   requestedQty[productID],
+				}
+				return nil, err
+			}
+			_, err = tx.Exec(
+				"INSERT INTO stock_reservations (cart_id, product_id, quantity, expires_at) VALUES ($1, $2, $3, $4)",
+				heldCart.ID, productID, requestedQty[productID], expiresAt.Time,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("gagal menyimpan reservasi stok: %w", err)
+			}
+		}
+	}
+
+	// ─── STEP 3: Commit ───
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🛒 Keranjang ID %d di-park oleh user ID %d (reservasi stok: %v)", heldCart.ID, userID, reserveStock)
+
+	heldCart.UserID = userID
+	heldCart.Label = label
+	heldCart.Cart = *cart
+	heldCart.ReserveStock = reserveStock
+	if expiresAt.Valid {
+		heldCart.ExpiresAt = &expiresAt.Time
+	}
+	for _, item := range cart.Items {
+		heldCart.TotalItems += item.Quantity
+	}
+
+	return &heldCart, nil
+}
+
+// GetAll mengambil daftar keranjang yang di-park
+// userID nil → semua keranjang (Admin), selain itu hanya milik user tersebut
+func (r *HeldCartRepository) GetAll(userID *int) ([]models.HeldCart, error) {
+	query := `
+		SELECT hc.id, hc.user_id, u.username, hc.label, hc.payload, hc.reserve_stock, hc.expires_at, hc.created_at, hc.updated_at
+		FROM held_carts hc
+		LEFT JOIN users u ON hc.user_id = u.id
+	`
+	args := []interface{}{}
+	if userID != nil {
+		query += " WHERE hc.user_id = $1 "
+		args = append(args, *userID)
+	}
+	query += " ORDER BY hc.created_at DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data keranjang: %w", err)
+	}
+	defer rows.Close()
+
+	carts := []models.HeldCart{}
+	for rows.Next() {
+		cart, err := scanHeldCart(rows)
+		if err != nil {
+			return nil, err
+		}
+		carts = append(carts, *cart)
+	}
+
+	return carts, nil
+}
+
+// GetByID mengambil 1 keranjang
+func (r *HeldCartRepository) GetByID(id int) (*models.HeldCart, error) {
+	row := r.db.QueryRow(`
+		SELECT hc.id, hc.user_id, u.username, hc.label, hc.payload, hc.reserve_stock, hc.expires_at, hc.created_at, hc.updated_at
+		FROM held_carts hc
+		LEFT JOIN users u ON hc.user_id = u.id
+		WHERE hc.id = $1
+	`, id)
+
+	cart, err := scanHeldCart(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("keranjang dengan ID %d tidak ditemukan", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// Delete menghapus keranjang (reservasi stok ikut terhapus via ON DELETE CASCADE)
+func (r *HeldCartRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM held_carts WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus keranjang: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("keranjang dengan ID %d tidak ditemukan", id)
+	}
+	return nil
+}
+
+// rowScanner agar scanHeldCart bisa dipakai untuk *sql.Row dan *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanHeldCart(row rowScanner) (*models.HeldCart, error) {
+	var c models.HeldCart
+	var username sql.NullString
+	var payload []byte
+	err := row.Scan(&c.ID, &c.UserID, &username, &c.Label, &payload, &c.ReserveStock, &c.ExpiresAt, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if username.Valid {
+		c.Username = username.String
+	}
+	if err := json.Unmarshal(payload, &c.Cart); err != nil {
+		return nil, fmt.Errorf("gagal membaca isi keranjang ID %d: %w", c.ID, err)
+	}
+	for _, item := range c.Cart.Items {
+		c.TotalItems += item.Quantity
+	}
+	return &c, nil
+}
+
+// getReservedStock menghitung stok yang sedang di-reservasi held cart (yang belum expired) per produk
+// excludeCartID: reservasi milik cart ini tidak dihitung (dipakai saat cart itu sendiri di-checkout)
+func getReservedStock(tx *sql.Tx, productIDArgs []interface{}, excludeCartID *int) (map[int]int, error) {
+	reserved := make(map[int]int)
+	if len(productIDArgs) == 0 {
+		return reserved, nil
+	}
+
+	placeholders := ""
+	for i := range productIDArgs {
+		if i > 0 {
+			placeholders += ", "
+		}
+		placeholders += fmt.Sprintf("$%d", i+2)
+	}
+
+	exclude := 0
+	if excludeCartID != nil {
+		exclude = *excludeCartID
+	}
+	args := append([]interface{}{exclude}, productIDArgs...)
+
+	rows, err := tx.Query(fmt.Sprintf(`
+		SELECT product_id, SUM(quantity)
+		FROM stock_reservations
+		WHERE expires_at > NOW() AND cart_id <> $1 AND product_id IN (%s)
+		GROUP BY product_id
+	`, placeholders), args...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil reservasi stok: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID, qty int
+		if err := rows.Scan(&productID, &qty); err != nil {
+			return nil, err
+		}
+		reserved[productID] = qty
+	}
+	return reserved, nil
+}
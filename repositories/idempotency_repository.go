@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+)
+
+// IdempotencyRepository handles database operations for idempotency keys
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyRepository creates a new IdempotencyRepository
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// idempotencyReserveAttempts adalah batas percobaan Reserve saat key terhapus
+// di antara INSERT dan SELECT (request lain dengan key yang sama baru saja gagal)
+const idempotencyReserveAttempts = 3
+
+const idempotencyKeyColumns = `id, scope, idempotency_key, user_id, request_hash, status,
+	response_status, response_body, resource_id, created_at, COALESCE(locked_at, created_at), completed_at`
+
+func scanIdempotencyKey(row rowScanner) (*models.IdempotencyKey, error) {
+	var k models.IdempotencyKey
+	err := row.Scan(&k.ID, &k.Scope, &k.Key, &k.UserID, &k.RequestHash, &k.Status,
+		&k.ResponseStatus, &k.ResponseBody, &k.ResourceID, &k.CreatedAt, &k.LockedAt, &k.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Reserve mencoba mendaftarkan key baru dengan status processing
+// Return created = true jika key baru berhasil didaftarkan (request boleh diproses).
+// Jika key sudah ada, return data key yang tersimpan (created = false) untuk dicek oleh caller.
+// INSERT ... ON CONFLICT DO NOTHING → aman jika 2 retry datang bersamaan (hanya 1 yang menang).
+// Key processing yang macet lebih lama dari models.IdempotencyProcessingTimeout (server crash / panic)
+// diambil alih oleh retry dengan request yang sama (created = true).
+func (r *IdempotencyRepository) Reserve(scope, key string, userID int, requestHash string) (*models.IdempotencyKey, bool, error) {
+	for attempt := 0; attempt < idempotencyReserveAttempts; attempt++ {
+		k, err := scanIdempotencyKey(r.db.QueryRow(`
+			INSERT INTO idempotency_keys (scope, idempotency_key, user_id, request_hash, status, locked_at)
+			VALUES ($1, $2, $3, $4, $5, NOW())
+			ON CONFLICT (scope, user_id, idempotency_key) DO NOTHING
+			RETURNING `+idempotencyKeyColumns,
+			scope, key, userID, requestHash, models.IdempotencyStatusProcessing))
+		if err == nil {
+			return k, true, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, false, fmt.Errorf("gagal menyimpan idempotency key: %w", err)
+		}
+
+		// Key sudah ada → ambil alih jika masih processing tapi sudah macet (request sama saja)
+		// UPDATE dengan kondisi locked_at → hanya 1 retry yang menang jika datang bersamaan
+		k, err = scanIdempotencyKey(r.db.QueryRow(`
+			UPDATE idempotency_keys SET locked_at = NOW()
+			WHERE scope = $1 AND user_id = $2 AND idempotency_key = $3
+			  AND request_hash = $4 AND status = $5
+			  AND COALESCE(locked_at, created_at) < NOW() - make_interval(secs => $6)
+			RETURNING `+idempotencyKeyColumns,
+			scope, userID, key, requestHash, models.IdempotencyStatusProcessing,
+			models.IdempotencyProcessingTimeout.Seconds()))
+		if err == nil {
+			log.Printf("♻️ Idempotency: key processing macet diambil alih (scope=%s key=%s user=%d)", scope, key, userID)
+			return k, true, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, false, fmt.Errorf("gagal mengambil alih idempotency key: %w", err)
+		}
+
+		// Ambil data yang tersimpan untuk dicek caller (replay / 409 / 422)
+		k, err = scanIdempotencyKey(r.db.QueryRow(`
+			SELECT `+idempotencyKeyColumns+`
+			FROM idempotency_keys
+			WHERE scope = $1 AND user_id = $2 AND idempotency_key = $3
+		`, scope, userID, key))
+		if err == sql.ErrNoRows {
+			// Key sempat dihapus (request sebelumnya gagal) di antara INSERT dan SELECT → coba lagi
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("gagal mengambil idempotency key: %w", err)
+		}
+		return k, false, nil
+	}
+
+	return nil, false, fmt.Errorf("gagal mendaftarkan idempotency key setelah %d percobaan", idempotencyReserveAttempts)
+}
+
+// Complete menyimpan response sukses untuk dikirim ulang saat replay
+func (r *IdempotencyRepository) Complete(id int, responseStatus int, responseBody string, resourceID *int) error {
+	_, err := r.db.Exec(`
+		UPDATE idempotency_keys
+		SET status = $1, response_status = $2, response_body = $3, resource_id = $4, completed_at = NOW()
+		WHERE id = $5
+	`, models.IdempotencyStatusCompleted, responseStatus, responseBody, resourceID, id)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan response idempotency: %w", err)
+	}
+	return nil
+}
+
+// Release menghapus key yang request-nya gagal, agar client bisa retry dengan key yang sama
+func (r *IdempotencyRepository) Release(id int) error {
+	_, err := r.db.Exec("DELETE FROM idempotency_keys WHERE id = $1 AND status = $2", id, models.IdempotencyStatusProcessing)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+	"time"
+)
+
+// LoyaltyRepository handles database operations for loyalty points
+// Buku besar poin: loyalty_points_ledger (saldo = SUM(points))
+type LoyaltyRepository struct {
+	db       *sql.DB
+	settings models.LoyaltySettings
+}
+
+// NewLoyaltyRepository creates a new LoyaltyRepository
+func NewLoyaltyRepository(db *sql.DB, settings models.LoyaltySettings) *LoyaltyRepository {
+	return &LoyaltyRepository{db: db, settings: settings}
+}
+
+// GetSummary mengambil saldo poin & riwayat 1 pelanggan
+// Poin yang sudah lewat masa berlaku dicatat sebagai baris expire terlebih dahulu.
+func (r *LoyaltyRepository) GetSummary(customerID int) (*models.LoyaltySummary, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	summary := &models.LoyaltySummary{
+		CustomerID: customerID,
+		PointValue: r.settings.PointValue,
+		EarnAmount: r.settings.EarnAmount,
+	}
+	err = tx.QueryRow("SELECT name FROM customers WHERE id = $1 FOR UPDATE", customerID).Scan(&summary.CustomerName)
+	if err == sql.ErrNoRows {
+		err = fmt.Errorf("pelanggan dengan ID %d tidak ditemukan", customerID)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	summary.Balance, err = expireLoyaltyPoints(tx, customerID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := loadPointEntries(tx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	state := computePointBuckets(entries, now)
+	summary.NextExpiry = state.nextExpiry
+	summary.NextExpiryPoints = state.nextExpiryPoints
+	summary.BalanceValue = roundMoney(float64(summary.Balance) * r.settings.PointValue)
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	// Riwayat ditampilkan terbaru dulu
+	summary.Entries = make([]models.PointLedgerEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		summary.Entries = append(summary.Entries, entries[i])
+	}
+	return summary, nil
+}
+
+// loadPointEntries mengambil semua baris poin pelanggan (urut dari yang paling lama)
+func loadPointEntries(q queryer, customerID int) ([]models.PointLedgerEntry, error) {
+	rows, err := q.Query(`
+		SELECT id, customer_id, type, points, transaction_id, sales_return_id, expires_at, note, created_by, created_at
+		FROM loyalty_points_ledger
+		WHERE customer_id = $1
+		ORDER BY created_at, id
+	`, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil poin pelanggan: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.PointLedgerEntry{}
+	for rows.Next() {
+		var e models.PointLedgerEntry
+		err := rows.Scan(&e.ID, &e.CustomerID, &e.Type, &e.Points, &e.TransactionID, &e.SalesReturnID,
+			&e.ExpiresAt, &e.Note, &e.CreatedBy, &e.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca poin pelanggan: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// pointBucketState adalah hasil alokasi FIFO buku besar poin
+type pointBucketState struct {
+	balance          int // SUM(points) dikurangi poin kedaluwarsa yang belum dicatat
+	toExpire         int // Poin yang sudah lewat expires_at tapi belum dicatat sebagai expire
+	nextExpiry       *time.Time
+	nextExpiryPoints int
+}
+
+// computePointBuckets mengalokasikan pemakaian poin ke poin earn paling lama (FIFO)
+// - reverse dipotongkan ke poin earn transaksinya sendiri
+// - redeem & expire memakai poin earn paling lama; refund mengembalikan pemakaian
+// Sisa poin earn yang sudah lewat expires_at = toExpire.
+func computePointBuckets(entries []models.PointLedgerEntry, now time.Time) pointBucketState {
+	type bucket struct {
+		remaining int
+		expiresAt *time.Time
+	}
+	buckets := []*bucket{}
+	bucketByTransaction := make(map[int]*bucket)
+	var total, used int
+
+	for _, e := range entries {
+		total += e.Points
+		switch e.Type {
+		case models.PointEntryEarn:
+			b := &bucket{remaining: e.Points, expiresAt: e.ExpiresAt}
+			buckets = append(buckets, b)
+			if e.TransactionID != nil {
+				bucketByTransaction[*e.TransactionID] = b
+			}
+		case models.PointEntryReverse:
+			amount := -e.Points
+			if e.TransactionID != nil {
+				if b, ok := bucketByTransaction[*e.TransactionID]; ok {
+					applied := amount
+					if applied > b.remaining {
+						applied = b.remaining
+					}
+					b.remaining -= applied
+					amount -= applied
+				}
+			}
+			used += amount
+		default:
+			// redeem & expire (negatif) menambah pemakaian, refund (positif) mengembalikannya
+			used -= e.Points
+		}
+	}
+
+	var state pointBucketState
+	for _, b := range buckets {
+		if used > 0 {
+			applied := used
+			if applied > b.remaining {
+				applied = b.remaining
+			}
+			b.remaining -= applied
+			used -= applied
+		}
+		if b.remaining <= 0 || b.expiresAt == nil {
+			continue
+		}
+		if !b.expiresAt.After(now) {
+			state.toExpire += b.remaining
+			continue
+		}
+		if state.nextExpiry == nil || b.expiresAt.Before(*state.nextExpiry) {
+			expiresAt := *b.expiresAt
+			state.nextExpiry = &expiresAt
+			state.nextExpiryPoints = b.remaining
+		} else if b.expiresAt.Equal(*state.nextExpiry) {
+			state.nextExpiryPoints += b.remaining
+		}
+	}
+	state.balance = total - state.toExpire
+	return state
+}
+
+// expireLoyaltyPoints mencatat poin yang sudah kedaluwarsa dan mengembalikan saldo poin terbaru
+// Dipanggil di dalam transaksi database setelah baris pelanggan di-lock.
+func expireLoyaltyPoints(q queryer, customerID int, now time.Time) (int, error) {
+	entries, err := loadPointEntries(q, customerID)
+	if err != nil {
+		return 0, err
+	}
+	state := computePointBuckets(entries, now)
+	if state.toExpire > 0 {
+		err = insertPointEntry(q, &models.PointLedgerEntry{
+			CustomerID: customerID,
+			Type:       models.PointEntryExpire,
+			Points:     -state.toExpire,
+		})
+		if err != nil {
+			return 0, err
+		}
+		log.Printf("⌛ %d poin pelanggan ID %d kedaluwarsa", state.toExpire, customerID)
+	}
+	return state.balance, nil
+}
+
+// insertPointEntry menyimpan 1 baris buku besar poin (ID & created_at diisi dari database)
+func insertPointEntry(q queryer, e *models.PointLedgerEntry) error {
+	err := q.QueryRow(`
+		INSERT INTO loyalty_points_ledger
+			(customer_id, type, points, transaction_id, sales_return_id, expires_at, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, e.CustomerID, e.Type, e.Points, e.TransactionID, e.SalesReturnID, e.ExpiresAt, e.Note, e.CreatedBy).
+		Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan poin: %w", err)
+	}
+	return nil
+}
+
+// transactionPoints adalah poin bersih 1 transaksi (setelah void / retur sebelumnya)
+type transactionPoints struct {
+	CustomerID     int
+	Earned         int     // Poin earn - poin yang sudah ditarik
+	EarnedPoints   int     // Poin earn awal (untuk menarik poin secara proporsional saat retur)
+	Redeemed       int     // Poin redeem - poin yang sudah dikembalikan
+	RedeemedPoints int     // Poin redeem awal (untuk menghitung nilai per poin)
+	TenderAmount   float64 // Nominal tender points di transaksi (rupiah)
+}
+
+// getTransactionPoints mengambil poin earn & redeem bersih untuk 1 transaksi
+func getTransactionPoints(q queryer, transactionID int) (*transactionPoints, error) {
+	var customerID sql.NullInt64
+	var tp transactionPoints
+	err := q.QueryRow(`
+		SELECT MAX(customer_id),
+			COALESCE(SUM(points) FILTER (WHERE type IN ('earn', 'reverse')), 0),
+			COALESCE(SUM(points) FILTER (WHERE type = 'earn'), 0),
+			COALESCE(-SUM(points) FILTER (WHERE type IN ('redeem', 'refund')), 0),
+			COALESCE(-SUM(points) FILTER (WHERE type = 'redeem'), 0)
+		FROM loyalty_points_ledger
+		WHERE transaction_id = $1
+	`, transactionID).Scan(&customerID, &tp.Earned, &tp.EarnedPoints, &tp.Redeemed, &tp.RedeemedPoints)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil poin transaksi: %w", err)
+	}
+	if !customerID.Valid {
+		return &tp, nil
+	}
+	tp.CustomerID = int(customerID.Int64)
+
+	err = q.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0) FROM transaction_payments
+		WHERE transaction_id = $1 AND method = 'points'
+	`, transactionID).Scan(&tp.TenderAmount)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil pembayaran poin: %w", err)
+	}
+	return &tp, nil
+}
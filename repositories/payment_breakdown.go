@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"database/sql"
+	"kasir-api/models"
+	"time"
+)
+
+// getPaymentBreakdown merangkum pembayaran per metode untuk transaksi (non-void) dalam periode
+// Transaksi lama tanpa baris transaction_payments dihitung sebagai cash sebesar total_amount.
+// userID opsional: filter berdasarkan kasir pembuat transaksi.
+func getPaymentBreakdown(db *sql.DB, startDate, endDate time.Time, userID *int) ([]models.PaymentMethodTotal, error) {
+	args := []interface{}{startDate, endDate}
+	userFilterStr := ""
+	if userID != nil {
+		userFilterStr = " AND t.created_by = $3 "
+		args = append(args, *userID)
+	}
+
+	query := `
+		SELECT method, COALESCE(SUM(amount), 0) as amount, COUNT(DISTINCT transaction_id) as transaction_count
+		FROM (
+			SELECT tp.method, tp.amount, t.id as transaction_id
+			FROM transaction_payments tp
+			JOIN transactions t ON tp.transaction_id = t.id
+			WHERE t.created_at BETWEEN $1 AND $2 AND t.voided_at IS NULL ` + userFilterStr + `
+
+			UNION ALL
+
+			SELECT 'cash' as method, t.total_amount as amount, t.id as transaction_id
+			FROM transactions t
+			WHERE t.created_at BETWEEN $1 AND $2 AND t.voided_at IS NULL ` + userFilterStr + `
+			  AND NOT EXISTS (SELECT 1 FROM transaction_payments tp WHERE tp.transaction_id = t.id)
+		) p
+		GROUP BY method
+		ORDER BY amount DESC
+	`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breakdown := []models.PaymentMethodTotal{}
+	for rows.Next() {
+		var m models.PaymentMethodTotal
+		if err := rows.Scan(&m.Method, &m.Amount, &m.TransactionCount); err != nil {
+			return nil, err
+		}
+		breakdown = append(breakdown, m)
+	}
+
+	return breakdown, nil
+}
+
+// subtractCashRefund mengurangi refund retur tunai dari baris cash pada breakdown
+// agar total breakdown sama dengan revenue / cash in yang sudah net retur
+func subtractCashRefund(breakdown []models.PaymentMethodTotal, refund float64) []models.PaymentMethodTotal {
+	return addMethodAmount(breakdown, models.PaymentMethodCash, -refund)
+}
+
+// addMethodAmount menambahkan nominal (boleh negatif) ke baris metode pada breakdown
+// Baris baru ditambahkan jika metode belum ada
+func addMethodAmount(breakdown []models.PaymentMethodTotal, method string, amount float64) []models.PaymentMethodTotal {
+	if amount == 0 {
+		return breakdown
+	}
+	for i := range breakdown {
+		if breakdown[i].Method == method {
+			breakdown[i].Amount += amount
+			return breakdown
+		}
+	}
+	return append(breakdown, models.PaymentMethodTotal{Method: method, Amount: amount})
+}
+
+// getCreditRepaymentBreakdown merangkum cicilan kasbon pelanggan per metode dalam periode
+// Cicilan adalah uang masuk (cash in), sedangkan penjualan kasbon belum.
+func getCreditRepaymentBreakdown(db *sql.DB, startDate, endDate time.Time) ([]models.PaymentMethodTotal, error) {
+	rows, err := db.Query(`
+		SELECT COALESCE(method, 'cash'), COALESCE(SUM(amount), 0), COUNT(*)
+		FROM customer_credit_ledger
+		WHERE type = 'repayment' AND created_at BETWEEN $1 AND $2
+		GROUP BY COALESCE(method, 'cash')
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	repayments := []models.PaymentMethodTotal{}
+	for rows.Next() {
+		var m models.PaymentMethodTotal
+		if err := rows.Scan(&m.Method, &m.Amount, &m.TransactionCount); err != nil {
+			return nil, err
+		}
+		repayments = append(repayments, m)
+	}
+	return repayments, nil
+}
@@ -0,0 +1,328 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+	"math"
+	"time"
+)
+
+// discountMatchTolerance adalah selisih maksimal (rupiah) antara diskon dari frontend
+// dan hasil pricing engine yang masih dianggap sama (selisih pembulatan di frontend)
+const discountMatchTolerance = 1.0
+
+// pricingProduct adalah data produk yang dibutuhkan pricing engine
+type pricingProduct struct {
+	Name       string
+	Price      float64
+	Stok       int
+	CategoryID sql.NullInt64
+	HargaBeli  sql.NullFloat64
+	TaxClassID sql.NullInt64 // Kelas pajak produk
+	CatTaxID   sql.NullInt64 // Kelas pajak kategori (fallback)
+}
+
+// loadPricingProducts mengambil data produk untuk pricing dalam 1 query
+// forUpdate = true → baris produk di-lock sampai commit (dipakai checkout, bukan preview).
+// ORDER BY id → urutan lock konsisten untuk menghindari deadlock antar checkout.
+// FOR UPDATE OF p → hanya baris produk yang di-lock (kategori cuma dibaca untuk kelas pajak)
+func loadPricingProducts(q queryer, productIDArgs []interface{}, forUpdate bool) (map[int]*pricingProduct, error) {
+	products := make(map[int]*pricingProduct)
+	if len(productIDArgs) == 0 {
+		return products, nil
+	}
+
+	placeholders := ""
+	for i := range productIDArgs {
+		if i > 0 {
+			placeholders += ", "
+		}
+		placeholders += fmt.Sprintf("$%d", i+1)
+	}
+	lockClause := ""
+	if forUpdate {
+		lockClause = " FOR UPDATE OF p"
+	}
+
+	rows, err := q.Query(
+		fmt.Sprintf(`SELECT p.id, p.nama, p.harga, p.stok, p.category_id, p.harga_beli, p.tax_class_id, c.tax_class_id
+			FROM products p LEFT JOIN categories c ON p.category_id = c.id
+			WHERE p.id IN (%s) ORDER BY p.id%s`, placeholders, lockClause),
+		productIDArgs...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data produk: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var p pricingProduct
+		if err := rows.Scan(&id, &p.Name, &p.Price, &p.Stok, &p.CategoryID, &p.HargaBeli, &p.TaxClassID, &p.CatTaxID); err != nil {
+			return nil, err
+		}
+		products[id] = &p
+	}
+	return products, nil
+}
+
+// cartPricing adalah hasil pricing engine untuk 1 keranjang
+type cartPricing struct {
+	Details             []models.TransactionDetail // Baris dengan harga, diskon & snapshot pajak
+	GrossAmount         float64                    // Harga × qty sebelum diskon
+	ItemDiscount        float64                    // Total diskon per item (termasuk promosi)
+	PromotionDiscount   float64                    // Bagian ItemDiscount dari promosi (BUY_X_GET_Y / BUNDLE / QTY_TIER)
+	NetAmount           float64                    // Total setelah diskon item, sebelum diskon global
+	DiscountID          *int                       // Diskon global yang dipakai
+	Voucher             *models.Voucher            // Voucher yang dipakai (diskon global dari voucher_code)
+	GlobalDiscount      float64                    // Total diskon transaksi (= jumlah OrderDiscountAmount per baris)
+	Applied             []appliedDiscount          // Kombinasi diskon per baris (audit transaction_discounts)
+	TaxAmount           float64
+	TaxIncludedAmount   float64
+	ServiceChargeAmount float64
+	Total               float64 // Total akhir (setelah diskon, + PPN exclusive & service charge)
+}
+
+// TotalDiscount mengembalikan diskon item + diskon global
+func (c *cartPricing) TotalDiscount() float64 {
+	return c.ItemDiscount + c.GlobalDiscount
+}
+
+// priceCart adalah pricing engine yang dipakai checkout & preview
+// Urutan: harga DB → diskon produk/kategori, promosi (paket, beli X gratis Y, harga grosir) &
+// diskon global (DiscountID / voucher_code + min order) sesuai aturan stacking → pajak & service charge.
+// Diskon dari frontend (item.discount_amount / discount_amount) hanya diterima jika sama dengan
+// hasil engine, kecuali req.DiscountOverride atau baris yang punya persetujuan supervisor.
+// Baris dengan override_price (sudah disetujui) memakai harga tersebut tanpa diskon otomatis & promosi.
+// Jadwal hari / jam diskon dicek pada waktu sekarang di timezone toko storeLoc.
+func priceCart(q queryer, req *models.CheckoutRequest, products map[int]*pricingProduct, serviceCharge models.ServiceChargeSettings, storeLoc *time.Location) (*cartPricing, error) {
+	now := time.Now()
+	itemDiscounts, err := loadItemDiscounts(q, now, storeLoc)
+	if err != nil {
+		return nil, err
+	}
+	promos, err := loadPromotions(q, now, storeLoc)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &cartPricing{Details: make([]models.TransactionDetail, 0, len(req.Items))}
+	stacker := &discountStacker{products: products, lines: make([]lineStacking, len(req.Items))}
+	// manualDiscount = diskon baris dari frontend dipakai apa adanya (admin / persetujuan supervisor)
+	manualDiscount := make([]bool, len(req.Items))
+
+	// ─── Baris keranjang (harga DB / override supervisor) ───
+	for idx, item := range req.Items {
+		p, exists := products[item.ProductID]
+		if !exists {
+			return nil, fmt.Errorf("produk dengan ID %d tidak ditemukan", item.ProductID)
+		}
+
+		// Harga satuan dari database — frontend tidak bisa mengubah harga lewat item.price.
+		// Harga lain hanya lewat override_price yang sudah disetujui supervisor.
+		unitPrice := p.Price
+		if item.Price > 0 && item.Price != p.Price {
+			log.Printf("⚠️ Produk ID %d: frontend kirim harga %.0f, DB harga %.0f — gunakan harga DB",
+				item.ProductID, item.Price, p.Price)
+		}
+
+		// Override supervisor: harga khusus menggantikan harga DB & diskon otomatis
+		approved := item.OverrideApprovedBy != nil
+		var originalPrice *float64
+		if approved {
+			dbPrice := p.Price
+			originalPrice = &dbPrice
+			if item.OverridePrice != nil {
+				unitPrice = *item.OverridePrice
+			}
+		}
+
+		var hargaBeliSnapshot float64
+		if p.HargaBeli.Valid {
+			hargaBeliSnapshot = p.HargaBeli.Float64
+		} else {
+			hargaBeliSnapshot = unitPrice
+		}
+
+		detail := models.TransactionDetail{
+			ProductID:   item.ProductID,
+			ProductName: p.Name,
+			Quantity:    item.Quantity,
+			Price:       unitPrice,
+			HargaBeli:   hargaBeliSnapshot,
+		}
+		if approved {
+			reason := item.OverrideReason
+			detail.OriginalPrice = originalPrice
+			detail.OverrideApprovedBy = item.OverrideApprovedBy
+			detail.OverrideReason = &reason
+		}
+
+		// Diskon manual dari frontend menggantikan diskon otomatis & promosi
+		if item.DiscountAmount > 0 && (req.DiscountOverride || approved) {
+			detail.DiscountAmount = math.Min(item.DiscountAmount, unitPrice*float64(item.Quantity))
+			detail.DiscountType = item.DiscountType
+			detail.DiscountValue = item.DiscountValue
+			manualDiscount[idx] = true
+		}
+		stacker.lines[idx].skipAuto = manualDiscount[idx] || (approved && item.OverridePrice != nil) || item.Quantity <= 0
+		result.Details = append(result.Details, detail)
+	}
+	stacker.details = result.Details
+	for idx := range result.Details {
+		if manualDiscount[idx] {
+			stacker.recordManualDiscount(idx)
+		}
+	}
+
+	// ─── Diskon transaksi (DiscountID / voucher_code) ───
+	// Voucher pengganti DiscountID (tidak bisa digabung). Kuota di sini hanya dicek (preview);
+	// saat checkout kuota dipakai secara atomik oleh redeemVoucher.
+	var orderRule *discountRule
+	orderName := ""
+	if req.DiscountID != nil {
+		d, err := loadOrderDiscount(q, *req.DiscountID, now, storeLoc)
+		if err != nil {
+			return nil, err
+		}
+		orderRule = &discountRule{discount: d, level: models.DiscountLevelOrder}
+		orderName = d.Name
+	}
+	if req.VoucherCode != "" {
+		if req.DiscountID != nil {
+			return nil, models.ErrVoucherWithDiscount
+		}
+		voucher, err := loadVoucherByCode(q, models.NormalizeVoucherCode(req.VoucherCode))
+		if err != nil {
+			return nil, err
+		}
+		if err := checkVoucherUsable(q, voucher, req.CustomerID, now); err != nil {
+			return nil, err
+		}
+		// Voucher selalu STACKABLE dengan priority 0
+		orderRule = &discountRule{
+			discount: &models.Discount{Type: voucher.Type, Value: voucher.Value, StackingMode: models.StackingStackable},
+			level:    models.DiscountLevelOrder,
+			voucher:  voucher,
+		}
+		orderName = "Voucher " + voucher.Code
+	}
+
+	// ─── Aturan stacking ───
+	// Semua diskon diurutkan (priority terbesar dulu) lalu dipakai satu per satu:
+	// maksimal 1 diskon produk/kategori & 1 promosi per baris, EXCLUSIVE tidak digabung,
+	// max_total_percent membatasi total diskon baris. Diskon transaksi hanya menandai baris
+	// yang ikut; nominalnya dihitung setelah subtotal akhir diketahui.
+	rules := make([]*discountRule, 0, len(itemDiscounts)+len(promos)+1)
+	for _, d := range itemDiscounts {
+		rules = append(rules, &discountRule{discount: d, level: models.DiscountLevelItem})
+	}
+	for _, d := range promos {
+		rules = append(rules, &discountRule{discount: d, level: models.DiscountLevelPromotion})
+	}
+	if orderRule != nil {
+		rules = append(rules, orderRule)
+	}
+	sortDiscountRules(rules)
+
+	for _, rule := range rules {
+		switch rule.level {
+		case models.DiscountLevelItem:
+			stacker.applyItemDiscount(rule)
+		case models.DiscountLevelPromotion:
+			result.PromotionDiscount += stacker.applyPromotion(rule)
+		case models.DiscountLevelOrder:
+			if !stacker.reserveOrderDiscount(rule) {
+				return nil, models.ErrDiscountNotCombinable
+			}
+		}
+	}
+	result.PromotionDiscount = roundMoney(result.PromotionDiscount)
+
+	for idx, item := range req.Items {
+		detail := &result.Details[idx]
+
+		// Diskon yang dikirim frontend harus sama dengan hasil engine (diskon item + promosi)
+		if item.DiscountAmount > 0 && !manualDiscount[idx] &&
+			math.Abs(item.DiscountAmount-detail.DiscountAmount) > discountMatchTolerance {
+			return nil, &models.DiscountMismatchError{
+				ProductID:    item.ProductID,
+				ClientAmount: item.DiscountAmount,
+				ServerAmount: detail.DiscountAmount,
+			}
+		}
+
+		// Subtotal = (harga × qty) - total diskon item
+		gross := detail.Price * float64(detail.Quantity)
+		detail.Subtotal = gross - detail.DiscountAmount
+		result.GrossAmount += gross
+		result.NetAmount += detail.Subtotal
+		result.ItemDiscount += detail.DiscountAmount
+	}
+
+	// Diskon transaksi dihitung dari total setelah diskon item, hanya di baris yang lolos aturan stacking
+	if orderRule != nil {
+		if result.NetAmount < orderRule.minOrderAmount() {
+			if orderRule.voucher != nil {
+				return nil, fmt.Errorf("%w (min order %.0f)", models.ErrVoucherMinOrder, orderRule.voucher.MinOrderAmount)
+			}
+			return nil, fmt.Errorf("min order %.0f tidak terpenuhi", orderRule.discount.MinOrderAmount)
+		}
+		result.GlobalDiscount = stacker.applyOrderDiscount(orderRule, orderName)
+		if orderRule.voucher != nil {
+			result.Voucher = orderRule.voucher
+		} else {
+			result.DiscountID = req.DiscountID
+		}
+	}
+	result.Applied = stacker.applied
+
+	// discount_amount di header (total diskon dari frontend) juga harus sama dengan engine
+	if req.DiscountAmount > 0 && !req.DiscountOverride &&
+		math.Abs(req.DiscountAmount-result.TotalDiscount()) > discountMatchTolerance {
+		return nil, &models.DiscountMismatchError{
+			ClientAmount: req.DiscountAmount,
+			ServerAmount: result.TotalDiscount(),
+		}
+	}
+
+	// ─── Pajak & service charge per baris ───
+	// Pajak dihitung dari nilai jual bersih baris (subtotal - porsi diskon transaksi).
+	// PPN exclusive & service charge menambah total.
+	finalTotal := result.NetAmount - result.GlobalDiscount
+	taxRules, defaultTaxClassID, err := loadTaxRules(q)
+	if err != nil {
+		return nil, err
+	}
+	var addedAmount float64
+	for i := range result.Details {
+		p := products[result.Details[i].ProductID]
+		rule := resolveTaxRule(taxRules, p.TaxClassID, p.CatTaxID, defaultTaxClassID)
+		lt := calculateLineTax(result.Details[i].Subtotal-result.Details[i].OrderDiscountAmount, rule, serviceCharge)
+
+		if rule != nil {
+			id := rule.ID
+			result.Details[i].TaxClassID = &id
+			result.Details[i].TaxInclusive = rule.IsInclusive
+			if !rule.IsExempt {
+				result.Details[i].TaxRate = rule.Rate
+			}
+		}
+		result.Details[i].TaxableAmount = lt.TaxableAmount
+		result.Details[i].TaxAmount = lt.TaxAmount
+		result.Details[i].ServiceChargeAmount = lt.ServiceChargeAmount
+
+		result.TaxAmount += lt.TaxAmount
+		result.TaxIncludedAmount += lt.TaxIncluded
+		result.ServiceChargeAmount += lt.ServiceChargeAmount
+		addedAmount += lt.Added()
+	}
+	result.TaxAmount = roundMoney(result.TaxAmount)
+	result.TaxIncludedAmount = roundMoney(result.TaxIncludedAmount)
+	result.ServiceChargeAmount = roundMoney(result.ServiceChargeAmount)
+	result.Total = roundMoney(finalTotal + addedAmount)
+
+	return result, nil
+}
@@ -9,13 +9,14 @@ import (
 // ProductRepository handles database operations for products
 // Struct ini menyimpan koneksi database
 type ProductRepository struct {
-	db *sql.DB // Pointer ke database connection
+	db      *sql.DB // Pointer ke database connection
+	costing string  // Metode harga pokok (average / fifo / last)
 }
 
 // NewProductRepository creates a new ProductRepository
 // Fungsi ini adalah "constructor" untuk membuat instance ProductRepository
-func NewProductRepository(db *sql.DB) *ProductRepository {
-	return &ProductRepository{db: db} // Return struct dengan db yang sudah di-inject
+func NewProductRepository(db *sql.DB, costing string) *ProductRepository {
+	return &ProductRepository{db: db, costing: costing} // Return struct dengan db yang sudah di-inject
 }
 
 // GetAll retrieves all products from database with pagination
@@ -38,6 +39,7 @@ func (r *ProductRepository) GetAll(searchName string, searchBarcode string, pagi
 			p.default_discount_type,
 			p.default_discount_value,
 			p.is_featured,
+			p.tax_class_id,
 			p.created_by,
 			c.id as category_id_full,
 			c.nama as category_name,
@@ -135,6 +137,7 @@ func (r *ProductRepository) GetAll(searchName string, searchBarcode string, pagi
 			&defaultDiscType,
 			&defaultDiscValue,
 			&product.IsFeatured,
+			&product.TaxClassID,
 			&createdBy,
 			&categoryID,
 			&categoryName,
@@ -208,6 +211,7 @@ func (r *ProductRepository) GetByID(id int) (*models.Product, error) {
 			p.default_discount_type,
 			p.default_discount_value,
 			p.is_featured,
+			p.tax_class_id,
 			p.created_by,
 			c.id as category_id_full,
 			c.nama as category_name,
@@ -246,6 +250,7 @@ func (r *ProductRepository) GetByID(id int) (*models.Product, error) {
 		&defaultDiscType,
 		&defaultDiscValue,
 		&product.IsFeatured,
+		&product.TaxClassID,
 		&createdBy,
 		&categoryID,
 		&categoryName,
@@ -306,27 +311,76 @@ func (r *ProductRepository) Create(product *models.Product) error {
 	// - Stok akan ditambahkan (stok lama + stok baru)
 	// - Harga akan diupdate dengan harga terbaru
 	// - CategoryID akan diupdate jika diberikan
-	// - HargaBeli akan diupdate (EXCLUDED.harga_beli)
+	// - HargaBeli dicatat sebagai harga pokok stok tambahan (sesuai COSTING_METHOD, lihat receiveCost)
 	// Jika belum ada, akan insert produk baru
 	query := `
-		INSERT INTO products (nama, harga, stok, category_id, harga_beli, created_by, barcode, default_discount_type, default_discount_value, is_featured) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO products (nama, harga, stok, category_id, harga_beli, created_by, barcode, default_discount_type, default_discount_value, is_featured, tax_class_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (nama) 
 		DO UPDATE SET 
 			harga = EXCLUDED.harga,
 			stok = products.stok + EXCLUDED.stok,
 			category_id = EXCLUDED.category_id,
-			harga_beli = EXCLUDED.harga_beli,
 			barcode = EXCLUDED.barcode,
 			default_discount_type = EXCLUDED.default_discount_type,
 			default_discount_value = EXCLUDED.default_discount_value,
-			is_featured = EXCLUDED.is_featured
+			is_featured = EXCLUDED.is_featured,
+			tax_class_id = EXCLUDED.tax_class_id
 		RETURNING id, stok
 	`
 
+	// Stok yang ditambahkan dicatat di ledger stock_movements dalam database transaction yang sama
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
 	// Execute query dan scan ID + stok terbaru yang di-return
-	err := r.db.QueryRow(query, product.Nama, product.Harga, product.Stok, product.CategoryID, product.HargaBeli, product.CreatedBy, product.Barcode, product.DefaultDiscountType, product.DefaultDiscountValue, product.IsFeatured).Scan(&product.ID, &product.Stok)
+	added := product.Stok
+	err = tx.QueryRow(query, product.Nama, product.Harga, product.Stok, product.CategoryID, product.HargaBeli, product.CreatedBy, product.Barcode, product.DefaultDiscountType, product.DefaultDiscountValue, product.IsFeatured, product.TaxClassID).Scan(&product.ID, &product.Stok)
+	if err != nil {
+		return err
+	}
+
+	note := "Stok awal / tambah stok dari data produk"
+	movement := models.StockMovement{
+		ProductID:   product.ID,
+		Type:        models.StockMovementAdjustment,
+		Quantity:    added,
+		StockBefore: product.Stok - added,
+		StockAfter:  product.Stok,
+		Note:        &note,
+		CreatedBy:   product.CreatedBy,
+	}
+	err = insertStockMovements(tx, []models.StockMovement{movement})
+	if err != nil {
+		return err
+	}
+
+	// Harga pokok stok tambahan: harga_beli dari request, atau harga pokok produk saat ini
+	// (produk tanpa harga beli sama sekali tidak dicatat di lapisan biaya)
+	unitCost := sql.NullFloat64{}
+	if product.HargaBeli != nil {
+		unitCost = sql.NullFloat64{Float64: *product.HargaBeli, Valid: true}
+	} else if added > 0 {
+		err = tx.QueryRow("SELECT harga_beli FROM products WHERE id = $1", product.ID).Scan(&unitCost)
+		if err != nil {
+			return err
+		}
+	}
+	if unitCost.Valid {
+		err = receiveCost(tx, r.costing, movement, unitCost.Float64)
+		if err != nil {
+			return err
+		}
+	}
 
+	err = tx.Commit()
 	return err // Return error (nil kalau sukses)
 }
 
@@ -334,11 +388,11 @@ func (r *ProductRepository) Create(product *models.Product) error {
 // Stok dikelola lewat pembelian (POST /api/purchases) dan penjualan (POST /api/checkout)
 // Harga beli dikelola lewat pembelian (POST /api/purchases)
 func (r *ProductRepository) Update(product *models.Product) error {
-	// SQL query untuk UPDATE — nama, harga jual, kategori, barcode, diskon default, dan is_featured
+	// SQL query untuk UPDATE — nama, harga jual, kategori, barcode, diskon default, is_featured, dan kelas pajak
 	// Stok dan harga_beli TIDAK bisa diubah dari sini
-	query := "UPDATE products SET nama = $1, harga = $2, category_id = $3, barcode = $4, default_discount_type = $5, default_discount_value = $6, is_featured = $7 WHERE id = $8"
+	query := "UPDATE products SET nama = $1, harga = $2, category_id = $3, barcode = $4, default_discount_type = $5, default_discount_value = $6, is_featured = $7, tax_class_id = $8 WHERE id = $9"
 
-	_, err := r.db.Exec(query, product.Nama, product.Harga, product.CategoryID, product.Barcode, product.DefaultDiscountType, product.DefaultDiscountValue, product.IsFeatured, product.ID)
+	_, err := r.db.Exec(query, product.Nama, product.Harga, product.CategoryID, product.Barcode, product.DefaultDiscountType, product.DefaultDiscountValue, product.IsFeatured, product.TaxClassID, product.ID)
 
 	return err
 }
@@ -358,6 +412,7 @@ func (r *ProductRepository) GetByBarcode(barcode string) (*models.Product, error
 			p.default_discount_type,
 			p.default_discount_value,
 			p.is_featured,
+			p.tax_class_id,
 			p.created_by,
 			c.id as category_id_full,
 			c.nama as category_name,
@@ -394,6 +449,7 @@ func (r *ProductRepository) GetByBarcode(barcode string) (*models.Product, error
 		&defaultDiscType,
 		&defaultDiscValue,
 		&product.IsFeatured,
+		&product.TaxClassID,
 		&createdBy,
 		&categoryID,
 		&categoryName,
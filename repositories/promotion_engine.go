@@ -0,0 +1,302 @@
+package repositories
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"math"
+	"sort"
+	"time"
+)
+
+// promotionRank menentukan urutan evaluasi promosi dengan priority yang sama: paket dulu
+// (paling spesifik), lalu beli X gratis Y, lalu harga grosir. 1 baris hanya mendapat 1 promosi.
+var promotionRank = map[models.DiscountType]int{
+	models.DiscountBundle:   0,
+	models.DiscountBuyXGetY: 1,
+	models.DiscountQtyTier:  2,
+}
+
+// loadPromotions mengambil semua promosi yang aktif saat ini (termasuk jadwal hari / jam
+// di timezone toko loc) beserta isi paket & tier
+func loadPromotions(q queryer, now time.Time, loc *time.Location) ([]*models.Discount, error) {
+	rows, err := q.Query(`
+		SELECT id, name, type, value, product_id, category_id, buy_qty, get_qty,
+			stacking_mode, priority, max_total_percent, days_of_week, time_start, time_end
+		FROM discounts
+		WHERE is_active = TRUE AND NOW() BETWEEN start_date AND end_date
+		AND type IN ('BUY_X_GET_Y', 'BUNDLE', 'QTY_TIER')
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data promosi: %w", err)
+	}
+	var promos []*models.Discount
+	byID := make(map[int]*models.Discount)
+	for rows.Next() {
+		var d models.Discount
+		if err := rows.Scan(&d.ID, &d.Name, &d.Type, &d.Value, &d.ProductID, &d.CategoryID, &d.BuyQty, &d.GetQty,
+			&d.StackingMode, &d.Priority, &d.MaxTotalPercent, daysOfWeekColumn{&d.DaysOfWeek}, &d.TimeStart, &d.TimeEnd); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if !d.IsScheduledAt(now, loc) {
+			continue
+		}
+		promos = append(promos, &d)
+		byID[d.ID] = &d
+	}
+	rows.Close()
+	if len(promos) == 0 {
+		return nil, nil
+	}
+
+	if err := loadPromotionRules(q, byID); err != nil {
+		return nil, err
+	}
+	return promos, nil
+}
+
+// loadPromotionRules mengisi BundleItems & Tiers untuk diskon di byID
+func loadPromotionRules(q queryer, byID map[int]*models.Discount) error {
+	rows, err := q.Query(`
+		SELECT bi.discount_id, bi.product_id, COALESCE(p.nama, ''), bi.quantity
+		FROM discount_bundle_items bi LEFT JOIN products p ON bi.product_id = p.id
+		ORDER BY bi.discount_id, bi.id
+	`)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil isi paket promosi: %w", err)
+	}
+	for rows.Next() {
+		var discountID int
+		var item models.DiscountBundleItem
+		if err := rows.Scan(&discountID, &item.ProductID, &item.ProductName, &item.Quantity); err != nil {
+			rows.Close()
+			return err
+		}
+		if d, ok := byID[discountID]; ok {
+			d.BundleItems = append(d.BundleItems, item)
+		}
+	}
+	rows.Close()
+
+	rows, err = q.Query("SELECT discount_id, min_qty, unit_price FROM discount_qty_tiers ORDER BY discount_id, min_qty")
+	if err != nil {
+		return fmt.Errorf("gagal mengambil tier harga promosi: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var discountID int
+		var tier models.DiscountTier
+		if err := rows.Scan(&discountID, &tier.MinQty, &tier.UnitPrice); err != nil {
+			return err
+		}
+		if d, ok := byID[discountID]; ok {
+			d.Tiers = append(d.Tiers, tier)
+		}
+	}
+	return nil
+}
+
+// promotionCart adalah status baris keranjang selama evaluasi promosi
+type promotionCart struct {
+	details  []models.TransactionDetail
+	products map[int]*pricingProduct
+	eligible []bool // false = baris override / sudah dapat promosi / ditutup diskon eksklusif
+	// limit membatasi potongan baris sesuai max_total_percent (nil = tanpa batas)
+	limit func(i int, amount float64) float64
+}
+
+// netUnit = harga satuan setelah diskon produk/kategori
+func (c *promotionCart) netUnit(i int) float64 {
+	d := &c.details[i]
+	return (d.Price*float64(d.Quantity) - d.DiscountAmount) / float64(d.Quantity)
+}
+
+// candidates mengembalikan index baris yang masih bisa dapat promosi p (sesuai produk / kategori)
+func (c *promotionCart) candidates(p *models.Discount) []int {
+	var idx []int
+	for i := range c.details {
+		if !c.eligible[i] {
+			continue
+		}
+		pid := c.details[i].ProductID
+		if p.ProductID != nil && pid != *p.ProductID {
+			continue
+		}
+		if p.CategoryID != nil {
+			prod := c.products[pid]
+			if prod == nil || !prod.CategoryID.Valid || int(prod.CategoryID.Int64) != *p.CategoryID {
+				continue
+			}
+		}
+		idx = append(idx, i)
+	}
+	return idx
+}
+
+// attribute mencatat potongan promosi ke baris i (tidak melebihi sisa nilai baris)
+func (c *promotionCart) attribute(i int, p *models.Discount, amount float64) float64 {
+	d := &c.details[i]
+	remaining := d.Price*float64(d.Quantity) - d.DiscountAmount
+	amount = math.Min(amount, remaining)
+	if c.limit != nil {
+		amount = c.limit(i, amount)
+	}
+	amount = roundMoney(amount)
+	if amount <= 0 {
+		return 0
+	}
+	id := p.ID
+	d.PromotionID = &id
+	d.PromotionType = string(p.Type)
+	d.PromotionName = p.Name
+	d.PromotionAmount = amount
+	d.DiscountAmount += amount
+	return amount
+}
+
+// apply menghitung 1 promosi untuk baris yang eligible (setelah diskon produk/kategori).
+// Potongan dicatat per baris (PromotionID / PromotionAmount) dan ditambahkan ke DiscountAmount.
+// Return: index baris yang ikut promosi (termasuk unit "beli" pada BUY_X_GET_Y) & total potongan
+func (c *promotionCart) apply(p *models.Discount) ([]int, float64) {
+	switch p.Type {
+	case models.DiscountBundle:
+		return c.applyBundle(p)
+	case models.DiscountBuyXGetY:
+		return c.applyBuyXGetY(p)
+	case models.DiscountQtyTier:
+		return c.applyQtyTier(p)
+	}
+	return nil, 0
+}
+
+// applyBuyXGetY: unit produk/kategori dikumpulkan, tiap (buy+get) unit → get unit termurah
+// mendapat potongan value% (0 / 100 = gratis)
+func (c *promotionCart) applyBuyXGetY(p *models.Discount) ([]int, float64) {
+	if p.BuyQty == nil || p.GetQty == nil || *p.BuyQty <= 0 || *p.GetQty <= 0 {
+		return nil, 0
+	}
+	lines := c.candidates(p)
+	units := 0
+	for _, i := range lines {
+		units += c.details[i].Quantity
+	}
+	free := (units / (*p.BuyQty + *p.GetQty)) * *p.GetQty
+	if free == 0 {
+		return nil, 0
+	}
+
+	percent := p.Value
+	if percent <= 0 || percent > 100 {
+		percent = 100
+	}
+	sort.SliceStable(lines, func(a, b int) bool { return c.netUnit(lines[a]) < c.netUnit(lines[b]) })
+
+	var total float64
+	for _, i := range lines {
+		if free == 0 {
+			break
+		}
+		take := min(free, c.details[i].Quantity)
+		total += c.attribute(i, p, float64(take)*c.netUnit(i)*percent/100)
+		free -= take
+	}
+	return lines, total
+}
+
+// applyBundle: jumlah paket = min(qty produk / qty per paket); potongan = nilai normal paket - harga paket,
+// dibagi proporsional ke baris isi paket
+func (c *promotionCart) applyBundle(p *models.Discount) ([]int, float64) {
+	if len(p.BundleItems) == 0 || p.Value <= 0 {
+		return nil, 0
+	}
+	type productPool struct {
+		lines []int
+		units int
+		value float64 // nilai bersih semua unit produk ini
+	}
+	pools := make(map[int]*productPool)
+	for i := range c.details {
+		if c.eligible[i] {
+			pid := c.details[i].ProductID
+			if pools[pid] == nil {
+				pools[pid] = &productPool{}
+			}
+			pools[pid].lines = append(pools[pid].lines, i)
+			pools[pid].units += c.details[i].Quantity
+			pools[pid].value += c.netUnit(i) * float64(c.details[i].Quantity)
+		}
+	}
+
+	bundles := math.MaxInt
+	for _, item := range p.BundleItems {
+		pool := pools[item.ProductID]
+		if pool == nil || item.Quantity <= 0 {
+			return nil, 0
+		}
+		bundles = min(bundles, pool.units/item.Quantity)
+	}
+	if bundles == 0 {
+		return nil, 0
+	}
+
+	// Nilai normal (harga bersih rata-rata per produk) dari unit yang masuk paket
+	shares := make([]float64, len(p.BundleItems))
+	var normal float64
+	for k, item := range p.BundleItems {
+		pool := pools[item.ProductID]
+		shares[k] = pool.value / float64(pool.units) * float64(bundles*item.Quantity)
+		normal += shares[k]
+	}
+	discount := normal - float64(bundles)*p.Value
+	if discount <= 0 || normal <= 0 {
+		return nil, 0
+	}
+
+	var used []int
+	var total float64
+	for k, item := range p.BundleItems {
+		pool := pools[item.ProductID]
+		productDiscount := discount * shares[k] / normal
+		for _, i := range pool.lines {
+			total += c.attribute(i, p, productDiscount*float64(c.details[i].Quantity)/float64(pool.units))
+		}
+		used = append(used, pool.lines...)
+	}
+	return used, total
+}
+
+// applyQtyTier: per produk, qty total >= min_qty tier → harga satuan tier (tier tertinggi yang terpenuhi).
+// Tidak pernah menaikkan harga: potongan per unit = max(0, harga bersih - harga tier)
+func (c *promotionCart) applyQtyTier(p *models.Discount) ([]int, float64) {
+	if len(p.Tiers) == 0 {
+		return nil, 0
+	}
+	units := make(map[int]int)
+	lines := c.candidates(p)
+	for _, i := range lines {
+		units[c.details[i].ProductID] += c.details[i].Quantity
+	}
+
+	var used []int
+	var total float64
+	for _, i := range lines {
+		var tier *models.DiscountTier
+		for k := range p.Tiers {
+			if units[c.details[i].ProductID] >= p.Tiers[k].MinQty &&
+				(tier == nil || p.Tiers[k].MinQty > tier.MinQty) {
+				tier = &p.Tiers[k]
+			}
+		}
+		if tier == nil {
+			continue
+		}
+		perUnit := c.netUnit(i) - tier.UnitPrice
+		if perUnit <= 0 {
+			continue
+		}
+		total += c.attribute(i, p, perUnit*float64(c.details[i].Quantity))
+		used = append(used, i)
+	}
+	return used, total
+}
@@ -11,18 +11,20 @@ import (
 // PurchaseRepository handles database operations for purchases
 // Repository untuk operasi pembelian/pengadaan barang
 type PurchaseRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	costing string // Metode harga pokok (average / fifo / last)
 }
 
 // NewPurchaseRepository creates a new PurchaseRepository
-func NewPurchaseRepository(db *sql.DB) *PurchaseRepository {
-	return &PurchaseRepository{db: db}
+func NewPurchaseRepository(db *sql.DB, costing string) *PurchaseRepository {
+	return &PurchaseRepository{db: db, costing: costing}
 }
 
 // Create creates a new purchase with items
 // Fungsi ini mencatat pembelian baru:
 // - Jika product_id NULL → buat produk baru di tabel products
-// - Jika product_id ada → update stok dan harga_beli produk yang sudah ada
+// - Jika product_id ada → update stok produk yang sudah ada
+// - Harga beli dicatat sebagai lapisan biaya, products.harga_beli diperbarui sesuai COSTING_METHOD
 // Semua dalam 1 database transaction (atomic)
 func (r *PurchaseRepository) Create(req *models.PurchaseRequest, createdBy int) (*models.Purchase, error) {
 	// Begin database transaction
@@ -40,6 +42,8 @@ func (r *PurchaseRepository) Create(req *models.PurchaseRequest, createdBy int)
 
 	var totalAmount float64
 	processedItems := make([]models.PurchaseItem, 0, len(req.Items))
+	// Ledger pergerakan stok (purchase), reference_id diisi setelah header pembelian tersimpan
+	movements := make([]models.StockMovement, 0, len(req.Items))
 
 	// ─── PROSES SETIAP ITEM ───
 	for i, item := range req.Items {
@@ -60,14 +64,16 @@ func (r *PurchaseRepository) Create(req *models.PurchaseRequest, createdBy int)
 				return nil, fmt.Errorf("item #%d: gagal mengambil data produk: %w", i+1, err)
 			}
 
-			// 2. Update stok (tambah) dan harga_beli
-			_, err = tx.Exec(
-				"UPDATE products SET stok = stok + $1, harga_beli = $2 WHERE id = $3",
-				item.Quantity, item.BuyPrice, productID,
-			)
+			// 2. Update stok (tambah), harga_beli diperbarui saat lapisan biaya dicatat
+			var stockAfter int
+			err = tx.QueryRow(
+				"UPDATE products SET stok = stok + $1 WHERE id = $2 RETURNING stok",
+				item.Quantity, productID,
+			).Scan(&stockAfter)
 			if err != nil {
 				return nil, fmt.Errorf("item #%d: gagal update stok produk '%s': %w", i+1, productName, err)
 			}
+			movements = append(movements, purchaseMovement(productID, item.Quantity, stockAfter, createdBy))
 
 			log.Printf("📦 Restok: %s +%d unit (harga beli: %.0f)", productName, item.Quantity, item.BuyPrice)
 
@@ -88,13 +94,15 @@ func (r *PurchaseRepository) Create(req *models.PurchaseRequest, createdBy int)
 			if errCheck == nil {
 				// Produk dengan nama yang sama sudah ada → restok saja
 				productID = existingID
-				_, err = tx.Exec(
-					"UPDATE products SET stok = stok + $1, harga_beli = $2 WHERE id = $3",
-					item.Quantity, item.BuyPrice, productID,
-				)
+				var stockAfter int
+				err = tx.QueryRow(
+					"UPDATE products SET stok = stok + $1 WHERE id = $2 RETURNING stok",
+					item.Quantity, productID,
+				).Scan(&stockAfter)
 				if err != nil {
 					return nil, fmt.Errorf("item #%d: gagal update stok produk '%s': %w", i+1, productName, err)
 				}
+				movements = append(movements, purchaseMovement(productID, item.Quantity, stockAfter, createdBy))
 				log.Printf("📦 Produk '%s' sudah ada, restok +%d unit", productName, item.Quantity)
 			} else {
 				// Produk benar-benar baru → insert ke tabel products
@@ -106,6 +114,7 @@ func (r *PurchaseRepository) Create(req *models.PurchaseRequest, createdBy int)
 				if err != nil {
 					return nil, fmt.Errorf("item #%d: gagal membuat produk baru '%s': %w", i+1, productName, err)
 				}
+				movements = append(movements, purchaseMovement(productID, item.Quantity, item.Quantity, createdBy))
 				log.Printf("✅ Produk baru: '%s' (ID: %d, stok: %d, beli: %.0f, jual: %.0f)",
 					productName, productID, item.Quantity, item.BuyPrice, *item.SellPrice)
 			}
@@ -159,6 +168,20 @@ func (r *PurchaseRepository) Create(req *models.PurchaseRequest, createdBy int)
 		}
 	}
 
+	// ─── LEDGER PERGERAKAN STOK & HARGA POKOK ───
+	// movements sejajar dengan processedItems (1 pergerakan per item)
+	for i := range movements {
+		movements[i].ReferenceID = &purchaseID
+		err = receiveCost(tx, r.costing, movements[i], processedItems[i].BuyPrice)
+		if err != nil {
+			return nil, fmt.Errorf("item #%d: gagal memperbarui harga pokok: %w", i+1, err)
+		}
+	}
+	err = insertStockMovements(tx, movements)
+	if err != nil {
+		return nil, err
+	}
+
 	// ─── COMMIT TRANSACTION ───
 	err = tx.Commit()
 	if err != nil {
@@ -181,6 +204,21 @@ func (r *PurchaseRepository) Create(req *models.PurchaseRequest, createdBy int)
 	return purchase, nil
 }
 
+// purchaseMovement membuat baris ledger stok untuk 1 item pembelian
+func purchaseMovement(productID, quantity, stockAfter, createdBy int) models.StockMovement {
+	m := models.StockMovement{
+		ProductID:   productID,
+		Type:        models.StockMovementPurchase,
+		Quantity:    quantity,
+		StockBefore: stockAfter - quantity,
+		StockAfter:  stockAfter,
+	}
+	if createdBy > 0 {
+		m.CreatedBy = &createdBy
+	}
+	return m
+}
+
 // GetAll retrieves all purchases ordered by date descending
 // Fungsi ini mengambil riwayat semua pembelian
 func (r *PurchaseRepository) GetAll() ([]models.Purchase, error) {
@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
 	"kasir-api/models"
 	"log"
+	"math"
 	"time"
 )
 
@@ -69,28 +71,34 @@ func (r *ReportRepository) getSalesReportByDateRange(startDate, endDate time.Tim
 
 	// Query 1A: Total revenue (nett) dan total transaksi
 	// Revenue nett = total_amount - discount_amount (tx-level discount)
+	// Transaksi yang sudah di-void (voided_at IS NOT NULL) tidak dihitung di semua query penjualan
 	queryRevenue := `
 		SELECT 
 			COALESCE(SUM(total_amount), 0) as total_revenue,
-			COUNT(*) as total_transaksi
+			COUNT(*) as total_transaksi,
+			COALESCE(SUM(tax_amount), 0) as total_tax,
+			COALESCE(SUM(rounding_amount), 0) as total_rounding
 		FROM transactions
-		WHERE created_at BETWEEN $1 AND $2 ` + userFilterStr + `
+		WHERE created_at BETWEEN $1 AND $2 AND voided_at IS NULL ` + userFilterStr + `
 	`
 	err := r.db.QueryRow(queryRevenue, argsBase...).Scan(
 		&report.TotalRevenue,
 		&report.TotalTransaksi,
+		&report.TotalTax,
+		&report.TotalRounding,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	// Query 1B: Total items terjual dan profit
-	// Profit = (total_amount - discount_amount) - HPP
+	// Profit = (total_amount - discount_amount) - PPN - HPP
 	// total_amount = setelah diskon item, discount_amount = diskon tx terpisah
+	// PPN adalah titipan pajak (bukan pendapatan toko) sehingga tidak dihitung sebagai profit
 	queryItems := `
 		SELECT 
 			COALESCE(SUM(hpp.total_qty), 0) as total_items_sold,
-			COALESCE(SUM(t.total_amount) - SUM(hpp.total_hpp), 0) as total_profit
+			COALESCE(SUM(t.total_amount) - SUM(t.tax_amount) - SUM(hpp.total_hpp), 0) as total_profit
 		FROM transactions t
 		JOIN (
 			SELECT 
@@ -100,7 +108,7 @@ func (r *ReportRepository) getSalesReportByDateRange(startDate, endDate time.Tim
 			FROM transaction_details td
 			GROUP BY td.transaction_id
 		) hpp ON hpp.transaction_id = t.id
-		WHERE t.created_at BETWEEN $1 AND $2 ` + userJoinFilterStr + `
+		WHERE t.created_at BETWEEN $1 AND $2 AND t.voided_at IS NULL ` + userJoinFilterStr + `
 	`
 	err = r.db.QueryRow(queryItems, argsBase...).Scan(
 		&report.TotalItemsSold,
@@ -110,6 +118,39 @@ func (r *ReportRepository) getSalesReportByDateRange(startDate, endDate time.Tim
 		return nil, err
 	}
 
+	// Query 1C: Retur penjualan dalam periode (berdasarkan tanggal retur, bukan tanggal transaksi)
+	// Revenue dikurangi total refund. Profit dikurangi refund - PPN refund - HPP barang yang kembali ke stok
+	// (barang yang di-write off tidak restock, jadi HPP-nya tetap hilang).
+	returnUserFilterStr := ""
+	if userID != nil {
+		returnUserFilterStr = " AND sr.created_by = $3 "
+	}
+	queryReturns := `
+		SELECT 
+			COALESCE(SUM(sri.refund_amount), 0) as total_returns,
+			COALESCE(SUM(sri.quantity), 0) as total_qty_returned,
+			COALESCE(SUM(sri.refund_amount - sri.tax_amount - CASE WHEN sri.restock THEN sri.harga_beli * sri.quantity ELSE 0 END), 0) as profit_lost,
+			COALESCE(SUM(sri.tax_amount), 0) as tax_returned
+		FROM sales_return_items sri
+		JOIN sales_returns sr ON sri.return_id = sr.id
+		WHERE sr.created_at BETWEEN $1 AND $2 ` + returnUserFilterStr + `
+	`
+	var qtyReturned int
+	var profitLost, taxReturned float64
+	err = r.db.QueryRow(queryReturns, argsBase...).Scan(
+		&report.TotalReturns,
+		&qtyReturned,
+		&profitLost,
+		&taxReturned,
+	)
+	if err != nil {
+		return nil, err
+	}
+	report.TotalRevenue -= report.TotalReturns
+	report.TotalItemsSold -= qtyReturned
+	report.TotalProfit -= profitLost
+	report.TotalTax -= taxReturned
+
 	// Note: Pembelian (Pengeluaran Barang), Gaji (Payroll), dan Operasional (Expenses)
 	// merupakan variabel bisnis tingkat toko bukan kasir. Jadi query ini tidak
 	// dikenakan user_id filter.
@@ -158,14 +199,30 @@ func (r *ReportRepository) getSalesReportByDateRange(startDate, endDate time.Tim
 		return nil, err
 	}
 
-	// Hitung laba bersih = laba kotor (total_profit) - pengeluaran_gaji - pengeluaran_operasional
+	// Query 4B: Write-off stok (rusak, kedaluwarsa, hilang, pemakaian internal, sampel) dikurangi
+	// barang ditemukan, dinilai dengan harga beli. Berdasarkan tanggal disetujui.
+	queryWriteOff := `
+		SELECT 
+			COALESCE(SUM(write_off_value - found_value), 0) as total_write_off
+		FROM stock_adjustments
+		WHERE status = 'approved' AND approved_at BETWEEN $1 AND $2
+	`
+	err = r.db.QueryRow(queryWriteOff, startDate, endDate).Scan(
+		&report.TotalWriteOff,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hitung laba bersih = laba kotor (total_profit) - write-off stok - pengeluaran_gaji - pengeluaran_operasional
 	// Pembelian stok tidak dikurangi karena itu adalah konversi aset Kas ke Inventory (bukan Opex).
-	report.LabaBersih = report.TotalProfit - report.TotalPayroll - report.TotalExpenses
+	// Write-off dikurangi karena persediaan yang rusak / hilang tidak akan pernah menjadi penjualan.
+	report.LabaBersih = report.TotalProfit - report.TotalWriteOff - report.TotalPayroll - report.TotalExpenses
 
 	// Query 5: Semua produk terjual (sorted by total_sales DESC)
 	// Profit per produk dihitung dengan distribusi proporsional tx-level discount:
 	//   item_share = (td.subtotal / SUM(subtotal per transaksi)) × tx.discount_amount
-	//   item_profit = td.subtotal - (harga_beli × qty) - item_share
+	//   item_profit = td.subtotal - (harga_beli × qty) - item_share - PPN inclusive
 	queryProducts := `
 		SELECT 
 			p.nama as nama_produk,
@@ -174,6 +231,7 @@ func (r *ReportRepository) getSalesReportByDateRange(startDate, endDate time.Tim
 			COALESCE(SUM(
 				td.subtotal
 				- (COALESCE(td.harga_beli, 0) * td.quantity)
+				- CASE WHEN td.tax_inclusive THEN td.tax_amount ELSE 0 END
 				- (
 					td.subtotal
 					/ NULLIF((SELECT SUM(s.subtotal) FROM transaction_details s WHERE s.transaction_id = td.transaction_id), 0)
@@ -183,7 +241,7 @@ func (r *ReportRepository) getSalesReportByDateRange(startDate, endDate time.Tim
 		FROM transaction_details td
 		JOIN products p ON td.product_id = p.id
 		JOIN transactions t ON td.transaction_id = t.id
-		WHERE t.created_at BETWEEN $1 AND $2 ` + userJoinFilterStr + `
+		WHERE t.created_at BETWEEN $1 AND $2 AND t.voided_at IS NULL ` + userJoinFilterStr + `
 		GROUP BY p.id, p.nama
 		ORDER BY total_sales DESC
 	`
@@ -208,11 +266,32 @@ func (r *ReportRepository) getSalesReportByDateRange(startDate, endDate time.Tim
 	}
 	report.ProdukTerlaris = produkTerlaris
 
+	// Query 6: Rincian penjualan per metode pembayaran
+	// Refund retur dianggap tunai, kecuali bagian yang memotong kasbon (dikurangkan dari baris credit)
+	// dan bagian yang dikembalikan sebagai poin (dikurangkan dari baris points)
+	var creditRefunds, pointsRefunds float64
+	err = r.db.QueryRow(`
+		SELECT COALESCE(SUM(sr.credit_refund), 0), COALESCE(SUM(sr.points_refund), 0)
+		FROM sales_returns sr
+		WHERE sr.created_at BETWEEN $1 AND $2 `+returnUserFilterStr, argsBase...).Scan(&creditRefunds, &pointsRefunds)
+	if err != nil {
+		return nil, err
+	}
+	breakdown, err := getPaymentBreakdown(r.db, startDate, endDate, userID)
+	if err != nil {
+		return nil, err
+	}
+	breakdown = addMethodAmount(breakdown, models.PaymentMethodCredit, -creditRefunds)
+	breakdown = addMethodAmount(breakdown, models.PaymentMethodPoints, -pointsRefunds)
+	report.PaymentBreakdown = subtractCashRefund(breakdown, report.TotalReturns-creditRefunds-pointsRefunds)
+
 	return &report, nil
 }
 
 // GetDashboardAssets retrieves total asset cost and total asset retail from products table
 // where stock > 0
+// harga_beli adalah harga pokok yang dikelola sesuai COSTING_METHOD (rata-rata tertimbang, atau
+// rata-rata sisa lapisan biaya untuk fifo), sehingga stok × harga_beli = nilai persediaan
 func (r *ReportRepository) GetDashboardAssets() (*models.AssetReport, error) {
 	query := `
 		SELECT 
@@ -267,24 +346,47 @@ func (r *ReportRepository) GetSalesTrend(startDate, endDate time.Time, interval
 	// - AT TIME ZONE 'UTC' → memberitahu Postgres ini UTC, mengubahnya jadi timestamptz
 	// - AT TIME ZONE $1 → mengkonversi timestamptz ke timestamp tanpa zona waktu lokal
 	// Lakukan pada WHERE dan GROUP BY agar 100% konsisten.
+	// Retur penjualan dikurangkan pada periode tanggal retur (UNION ALL dengan nilai negatif)
 	query := `
 		SELECT 
-			TO_CHAR((t.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1), $2) as period,
-			COALESCE(SUM(t.total_amount), 0) as total_sales,
-			COALESCE(SUM(t.total_amount) - SUM(hpp.total_hpp), 0) as total_profit,
-			COUNT(DISTINCT t.id) as transaction_count
-		FROM transactions t
-		JOIN (
+			period,
+			COALESCE(SUM(total_sales), 0) as total_sales,
+			COALESCE(SUM(total_profit), 0) as total_profit,
+			COALESCE(SUM(transaction_count), 0) as transaction_count
+		FROM (
 			SELECT 
-				td.transaction_id,
-				SUM(COALESCE(td.harga_beli, 0) * td.quantity) as total_hpp
-			FROM transaction_details td
-			GROUP BY td.transaction_id
-		) hpp ON hpp.transaction_id = t.id
-		WHERE DATE(t.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1) >= $3
-		  AND DATE(t.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1) <= $4
-		GROUP BY TO_CHAR((t.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1), $2)
-		ORDER BY TO_CHAR((t.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1), $2) ASC
+				TO_CHAR((t.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1), $2) as period,
+				SUM(t.total_amount) as total_sales,
+				SUM(t.total_amount) - SUM(t.tax_amount) - SUM(hpp.total_hpp) as total_profit,
+				COUNT(DISTINCT t.id) as transaction_count
+			FROM transactions t
+			JOIN (
+				SELECT 
+					td.transaction_id,
+					SUM(COALESCE(td.harga_beli, 0) * td.quantity) as total_hpp
+				FROM transaction_details td
+				GROUP BY td.transaction_id
+			) hpp ON hpp.transaction_id = t.id
+			WHERE DATE(t.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1) >= $3
+			  AND DATE(t.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1) <= $4
+			  AND t.voided_at IS NULL
+			GROUP BY 1
+
+			UNION ALL
+
+			SELECT 
+				TO_CHAR((sr.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1), $2) as period,
+				-SUM(sri.refund_amount) as total_sales,
+				-SUM(sri.refund_amount - sri.tax_amount - CASE WHEN sri.restock THEN sri.harga_beli * sri.quantity ELSE 0 END) as total_profit,
+				0 as transaction_count
+			FROM sales_return_items sri
+			JOIN sales_returns sr ON sri.return_id = sr.id
+			WHERE DATE(sr.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1) >= $3
+			  AND DATE(sr.created_at AT TIME ZONE 'UTC' AT TIME ZONE $1) <= $4
+			GROUP BY 1
+		) trend
+		GROUP BY period
+		ORDER BY period ASC
 	`
 
 	rows, err := r.db.Query(query, tzName, dateFormat, startStr, endStr)
@@ -309,7 +411,7 @@ func (r *ReportRepository) GetSalesTrend(startDate, endDate time.Time, interval
 func (r *ReportRepository) GetTopProducts(startDate, endDate time.Time, limit int) ([]models.TopProduct, []models.TopProduct, error) {
 	// 1. Top by Quantity
 	// Profit per produk dihitung dengan distribusi proporsional tx-level discount:
-	//   item_profit = td.subtotal - (harga_beli × qty) - bagian_proporsional_tx_discount
+	//   item_profit = td.subtotal - (harga_beli × qty) - bagian_proporsional_tx_discount - PPN inclusive
 	queryQty := `
 		SELECT 
 			p.nama,
@@ -318,6 +420,7 @@ func (r *ReportRepository) GetTopProducts(startDate, endDate time.Time, limit in
 			COALESCE(SUM(
 				td.subtotal
 				- (COALESCE(td.harga_beli, 0) * td.quantity)
+				- CASE WHEN td.tax_inclusive THEN td.tax_amount ELSE 0 END
 				- (
 					td.subtotal
 					/ NULLIF((SELECT SUM(s.subtotal) FROM transaction_details s WHERE s.transaction_id = td.transaction_id), 0)
@@ -327,7 +430,7 @@ func (r *ReportRepository) GetTopProducts(startDate, endDate time.Time, limit in
 		FROM transaction_details td
 		JOIN products p ON td.product_id = p.id
 		JOIN transactions t ON td.transaction_id = t.id
-		WHERE t.created_at BETWEEN $1 AND $2
+		WHERE t.created_at BETWEEN $1 AND $2 AND t.voided_at IS NULL
 		GROUP BY p.id, p.nama
 		ORDER BY jumlah DESC
 		LIMIT $3
@@ -357,6 +460,7 @@ func (r *ReportRepository) GetTopProducts(startDate, endDate time.Time, limit in
 			COALESCE(SUM(
 				td.subtotal
 				- (COALESCE(td.harga_beli, 0) * td.quantity)
+				- CASE WHEN td.tax_inclusive THEN td.tax_amount ELSE 0 END
 				- (
 					td.subtotal
 					/ NULLIF((SELECT SUM(s.subtotal) FROM transaction_details s WHERE s.transaction_id = td.transaction_id), 0)
@@ -366,7 +470,7 @@ func (r *ReportRepository) GetTopProducts(startDate, endDate time.Time, limit in
 		FROM transaction_details td
 		JOIN products p ON td.product_id = p.id
 		JOIN transactions t ON td.transaction_id = t.id
-		WHERE t.created_at BETWEEN $1 AND $2
+		WHERE t.created_at BETWEEN $1 AND $2 AND t.voided_at IS NULL
 		GROUP BY p.id, p.nama
 		ORDER BY total_profit DESC
 		LIMIT $3
@@ -401,3 +505,622 @@ func (r *ReportRepository) CountLowStockProducts(threshold int) (int, error) {
 	}
 	return count, nil
 }
+
+// GetTaxReport menghitung rekap PPN per kelas pajak untuk pelaporan bulanan
+// - Penjualan: dari snapshot pajak di transaction_details (transaksi void tidak dihitung)
+// - Retur: PPN yang dikembalikan mengurangi PPN pada periode tanggal retur
+// Baris dikelompokkan per kelas pajak + tarif snapshot (tarif bisa berubah di tengah periode)
+func (r *ReportRepository) GetTaxReport(startDate, endDate time.Time) (*models.TaxReport, error) {
+	report := &models.TaxReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Lines:     []models.TaxSummaryLine{},
+	}
+
+	rows, err := r.db.Query(`
+		SELECT
+			td.tax_class_id,
+			COALESCE(tc.name, CASE WHEN td.tax_class_id IS NULL THEN 'Tanpa Pajak' ELSE 'Kelas Pajak Dihapus' END) as tax_class_name,
+			COALESCE(td.tax_rate, 0) as tax_rate,
+			BOOL_OR(COALESCE(td.tax_inclusive, FALSE)) as is_inclusive,
+			COALESCE(BOOL_OR(tc.is_exempt), FALSE) as is_exempt,
+			COALESCE(SUM(td.taxable_amount), 0) as taxable_amount,
+			COALESCE(SUM(td.tax_amount), 0) as tax_amount,
+			COUNT(DISTINCT t.id) as transaction_count
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		LEFT JOIN tax_classes tc ON td.tax_class_id = tc.id
+		WHERE t.created_at BETWEEN $1 AND $2 AND t.voided_at IS NULL
+		GROUP BY td.tax_class_id, tc.name, COALESCE(td.tax_rate, 0)
+		ORDER BY td.tax_class_id NULLS LAST, tax_rate
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type lineKey struct {
+		ClassID int // 0 = tanpa kelas pajak
+		Rate    float64
+	}
+	indexByKey := make(map[lineKey]int)
+	for rows.Next() {
+		var line models.TaxSummaryLine
+		if err := rows.Scan(
+			&line.TaxClassID, &line.TaxClassName, &line.Rate, &line.IsInclusive, &line.IsExempt,
+			&line.TaxableAmount, &line.TaxAmount, &line.TransactionCount,
+		); err != nil {
+			return nil, err
+		}
+		key := lineKey{Rate: line.Rate}
+		if line.TaxClassID != nil {
+			key.ClassID = *line.TaxClassID
+		}
+		indexByKey[key] = len(report.Lines)
+		report.Lines = append(report.Lines, line)
+	}
+
+	// PPN atas retur dalam periode (berdasarkan tanggal retur)
+	returnRows, err := r.db.Query(`
+		SELECT
+			td.tax_class_id,
+			COALESCE(td.tax_rate, 0) as tax_rate,
+			COALESCE(SUM(sri.tax_amount), 0) as returned_tax
+		FROM sales_return_items sri
+		JOIN sales_returns sr ON sri.return_id = sr.id
+		JOIN transaction_details td ON sri.transaction_detail_id = td.id
+		WHERE sr.created_at BETWEEN $1 AND $2
+		GROUP BY td.tax_class_id, COALESCE(td.tax_rate, 0)
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer returnRows.Close()
+
+	for returnRows.Next() {
+		var classID *int
+		var rate, returnedTax float64
+		if err := returnRows.Scan(&classID, &rate, &returnedTax); err != nil {
+			return nil, err
+		}
+		key := lineKey{Rate: rate}
+		if classID != nil {
+			key.ClassID = *classID
+		}
+		idx, ok := indexByKey[key]
+		if !ok {
+			// Retur atas penjualan periode sebelumnya → buat baris tersendiri
+			line := models.TaxSummaryLine{TaxClassID: classID, Rate: rate, TaxClassName: "Tanpa Pajak"}
+			if classID != nil {
+				err = r.db.QueryRow("SELECT name, is_exempt FROM tax_classes WHERE id = $1", *classID).Scan(&line.TaxClassName, &line.IsExempt)
+				if err == sql.ErrNoRows {
+					line.TaxClassName = "Kelas Pajak Dihapus"
+				} else if err != nil {
+					return nil, err
+				}
+			}
+			idx = len(report.Lines)
+			indexByKey[key] = idx
+			report.Lines = append(report.Lines, line)
+		}
+		report.Lines[idx].ReturnedTax += returnedTax
+	}
+
+	for i := range report.Lines {
+		line := &report.Lines[i]
+		line.NetTax = line.TaxAmount - line.ReturnedTax
+		switch {
+		case line.IsExempt:
+			report.TotalExemptSales += line.TaxableAmount
+		case line.TaxClassID != nil:
+			report.TotalTaxableAmount += line.TaxableAmount
+		}
+		report.TotalTax += line.TaxAmount
+		report.TotalReturnedTax += line.ReturnedTax
+	}
+	report.NetTax = report.TotalTax - report.TotalReturnedTax
+
+	err = r.db.QueryRow(`
+		SELECT COALESCE(SUM(service_charge_amount), 0)
+		FROM transactions
+		WHERE created_at BETWEEN $1 AND $2 AND voided_at IS NULL
+	`, startDate, endDate).Scan(&report.TotalServiceCharge)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetOverrideReport mengambil semua baris transaksi yang harganya / diskonnya di-override
+// dengan persetujuan supervisor, dikelompokkan per kasir.
+// Baris dari transaksi void tetap ditampilkan (voided = true) tapi tidak dihitung di total.
+// userID opsional: filter kasir tertentu.
+func (r *ReportRepository) GetOverrideReport(startDate, endDate time.Time, userID *int) (*models.OverrideReport, error) {
+	args := []interface{}{startDate, endDate}
+	userFilterStr := ""
+	if userID != nil {
+		userFilterStr = " AND t.created_by = $3 "
+		args = append(args, *userID)
+	}
+
+	rows, err := r.db.Query(`
+		SELECT
+			t.created_by,
+			COALESCE(u.username, 'Tidak diketahui') as cashier_name,
+			t.id,
+			td.id,
+			t.created_at,
+			td.product_id,
+			COALESCE(p.nama, 'Produk Dihapus') as product_name,
+			td.quantity,
+			td.original_price,
+			td.price,
+			COALESCE(td.discount_amount, 0) as discount_amount,
+			td.subtotal,
+			td.override_approved_by,
+			COALESCE(a.username, '') as approver_name,
+			COALESCE(td.override_reason, '') as reason,
+			(t.voided_at IS NOT NULL) as voided
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		LEFT JOIN users u ON t.created_by = u.id
+		LEFT JOIN users a ON td.override_approved_by = a.id
+		LEFT JOIN products p ON td.product_id = p.id
+		WHERE td.original_price IS NOT NULL
+		  AND t.created_at BETWEEN $1 AND $2 `+userFilterStr+`
+		ORDER BY cashier_name, t.created_at, td.id
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &models.OverrideReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Cashiers:  []models.OverrideCashierSummary{},
+	}
+	indexByCashier := make(map[string]int)
+	for rows.Next() {
+		var cashierID *int
+		var cashierName string
+		var line models.OverrideLine
+		if err := rows.Scan(
+			&cashierID, &cashierName,
+			&line.TransactionID, &line.TransactionDetailID, &line.CreatedAt,
+			&line.ProductID, &line.ProductName, &line.Quantity,
+			&line.OriginalPrice, &line.Price, &line.DiscountAmount, &line.Subtotal,
+			&line.ApprovedBy, &line.ApproverName, &line.Reason, &line.Voided,
+		); err != nil {
+			return nil, err
+		}
+		line.PriceReduction = line.OriginalPrice*float64(line.Quantity) - line.Subtotal
+
+		key := cashierName
+		if cashierID != nil {
+			key = fmt.Sprintf("%d", *cashierID)
+		}
+		idx, ok := indexByCashier[key]
+		if !ok {
+			idx = len(report.Cashiers)
+			indexByCashier[key] = idx
+			report.Cashiers = append(report.Cashiers, models.OverrideCashierSummary{
+				UserID:   cashierID,
+				Username: cashierName,
+				Lines:    []models.OverrideLine{},
+			})
+		}
+
+		summary := &report.Cashiers[idx]
+		summary.Lines = append(summary.Lines, line)
+		summary.OverrideCount++
+		report.OverrideCount++
+		if !line.Voided {
+			summary.TotalPriceReduction += line.PriceReduction
+			report.TotalPriceReduction += line.PriceReduction
+		}
+	}
+
+	return report, nil
+}
+
+// GetVoucherReport merangkum pemakaian voucher dalam periode (per voucher)
+// Pemakaian dari transaksi void tidak dihitung di total (hanya voided_count).
+func (r *ReportRepository) GetVoucherReport(startDate, endDate time.Time) (*models.VoucherReport, error) {
+	rows, err := r.db.Query(`
+		SELECT
+			v.id, v.code, v.name,
+			COUNT(*) FILTER (WHERE vr.voided_at IS NULL) as redemption_count,
+			COUNT(DISTINCT vr.customer_id) FILTER (WHERE vr.voided_at IS NULL) as unique_customers,
+			COALESCE(SUM(vr.discount_amount) FILTER (WHERE vr.voided_at IS NULL), 0) as total_discount,
+			COALESCE(SUM(t.total_amount) FILTER (WHERE vr.voided_at IS NULL), 0) as total_sales,
+			COUNT(*) FILTER (WHERE vr.voided_at IS NOT NULL) as voided_count,
+			v.max_redemptions,
+			v.redemption_count
+		FROM voucher_redemptions vr
+		JOIN vouchers v ON vr.voucher_id = v.id
+		JOIN transactions t ON vr.transaction_id = t.id
+		WHERE vr.created_at BETWEEN $1 AND $2
+		GROUP BY v.id, v.code, v.name, v.max_redemptions, v.redemption_count
+		ORDER BY total_discount DESC, v.code
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &models.VoucherReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Vouchers:  []models.VoucherReportLine{},
+	}
+	for rows.Next() {
+		var line models.VoucherReportLine
+		if err := rows.Scan(
+			&line.VoucherID, &line.Code, &line.Name,
+			&line.RedemptionCount, &line.UniqueCustomers, &line.TotalDiscount, &line.TotalSales, &line.VoidedCount,
+			&line.MaxRedemptions, &line.TotalRedeemed,
+		); err != nil {
+			return nil, err
+		}
+		if line.MaxRedemptions != nil {
+			remaining := *line.MaxRedemptions - line.TotalRedeemed
+			if remaining < 0 {
+				remaining = 0
+			}
+			line.Remaining = &remaining
+		}
+		report.Vouchers = append(report.Vouchers, line)
+		report.RedemptionCount += line.RedemptionCount
+		report.TotalDiscount += line.TotalDiscount
+		report.TotalSales += line.TotalSales
+	}
+
+	return report, nil
+}
+
+// discountReportLines adalah CTE penjualan bersih per baris transaksi (non-void) dalam $1 - $2.
+// revenue = subtotal - porsi diskon transaksi. Transaksi lama (sebelum audit transaction_discounts)
+// belum punya order_discount_amount, jadi porsinya dibagi proporsional subtotal.
+// profit = revenue - HPP - PPN inclusive.
+const discountReportLines = `
+	base AS (
+		SELECT
+			td.id, td.transaction_id, td.product_id, p.category_id, td.quantity, td.subtotal,
+			td.discount_type, COALESCE(td.discount_amount, 0) as discount_amount, td.promotion_id, td.promotion_amount, td.order_discount_amount,
+			COALESCE(td.harga_beli, 0) * td.quantity as hpp,
+			CASE WHEN td.tax_inclusive THEN td.tax_amount ELSE 0 END as tax_included,
+			t.discount_id as order_discount_id,
+			COALESCE((COALESCE(t.discount_amount, 0) - SUM(COALESCE(td.discount_amount, 0)) OVER w) * td.subtotal / NULLIF(SUM(td.subtotal) OVER w, 0), 0) as legacy_order_share,
+			EXISTS (SELECT 1 FROM transaction_discounts x WHERE x.transaction_id = td.transaction_id) as audited
+		FROM transaction_details td
+		JOIN transactions t ON td.transaction_id = t.id
+		LEFT JOIN products p ON td.product_id = p.id
+		WHERE t.created_at BETWEEN $1 AND $2 AND t.voided_at IS NULL
+		WINDOW w AS (PARTITION BY td.transaction_id)
+	),
+	lines AS (
+		SELECT base.*,
+			CASE WHEN audited THEN order_discount_amount ELSE legacy_order_share END as order_share,
+			subtotal - CASE WHEN audited THEN order_discount_amount ELSE legacy_order_share END as revenue,
+			subtotal - CASE WHEN audited THEN order_discount_amount ELSE legacy_order_share END - hpp - tax_included as profit
+		FROM base
+	)`
+
+// GetDiscountReport merangkum pemakaian diskon & promosi dalam periode (per diskon)
+// Sumber: transaction_discounts (audit per baris). Transaksi lama tanpa audit memakai
+// transactions.discount_id, transaction_details.promotion_id dan discount_type.
+// Voucher tidak termasuk (lihat GetVoucherReport). Transaksi void tidak dihitung.
+func (r *ReportRepository) GetDiscountReport(startDate, endDate time.Time) (*models.DiscountReport, error) {
+	rows, err := r.db.Query(`
+		WITH `+discountReportLines+`,
+		usage AS (
+			SELECT x.discount_id, x.level, x.name, x.transaction_id, l.id as line_id, x.amount
+			FROM transaction_discounts x
+			JOIN lines l ON l.id = x.transaction_detail_id
+			WHERE x.voucher_id IS NULL
+			UNION ALL
+			SELECT l.promotion_id, 'PROMOTION', COALESCE(d.name, 'Promosi'), l.transaction_id, l.id, l.promotion_amount
+			FROM lines l LEFT JOIN discounts d ON d.id = l.promotion_id
+			WHERE NOT l.audited AND l.promotion_id IS NOT NULL AND l.promotion_amount > 0
+			UNION ALL
+			SELECT NULL, 'ITEM', 'Diskon item' || COALESCE(' (' || l.discount_type || ')', ''), l.transaction_id, l.id,
+				l.discount_amount - l.promotion_amount
+			FROM lines l
+			WHERE NOT l.audited AND l.discount_amount - l.promotion_amount > 0
+			UNION ALL
+			SELECT l.order_discount_id, 'ORDER', COALESCE(d.name, 'Diskon transaksi'), l.transaction_id, l.id, l.order_share
+			FROM lines l LEFT JOIN discounts d ON d.id = l.order_discount_id
+			WHERE NOT l.audited AND l.order_discount_id IS NOT NULL AND l.order_share > 0
+		),
+		totals AS (
+			SELECT COALESCE(SUM(revenue), 0) as revenue, COALESCE(SUM(profit), 0) as profit
+			FROM lines WHERE id IN (SELECT line_id FROM usage)
+		)
+		SELECT
+			u.discount_id, u.level, MAX(u.name),
+			COUNT(DISTINCT u.transaction_id) as usage_count,
+			COUNT(DISTINCT u.line_id) as line_count,
+			COALESCE(SUM(l.quantity), 0) as quantity_sold,
+			COALESCE(SUM(u.amount), 0) as total_discount,
+			COALESCE(SUM(l.revenue), 0) as revenue,
+			COALESCE(SUM(l.profit), 0) as gross_profit,
+			MAX(totals.revenue), MAX(totals.profit)
+		FROM usage u
+		JOIN lines l ON l.id = u.line_id
+		CROSS JOIN totals
+		GROUP BY u.discount_id, u.level, CASE WHEN u.discount_id IS NULL THEN u.name END
+		ORDER BY total_discount DESC, MAX(u.name)
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.DiscountReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Discounts: []models.DiscountReportLine{},
+	}
+	for rows.Next() {
+		var line models.DiscountReportLine
+		if err := rows.Scan(
+			&line.DiscountID, &line.Level, &line.Name,
+			&line.UsageCount, &line.LineCount, &line.QuantitySold, &line.TotalDiscount, &line.Revenue, &line.GrossProfit,
+			&report.TotalRevenue, &report.TotalGrossProfit,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		line.TotalDiscount = roundMoney(line.TotalDiscount)
+		line.Revenue = roundMoney(line.Revenue)
+		line.GrossProfit = roundMoney(line.GrossProfit)
+		report.TotalDiscount += line.TotalDiscount
+		report.Discounts = append(report.Discounts, line)
+	}
+	rows.Close()
+	report.TotalDiscount = roundMoney(report.TotalDiscount)
+	report.TotalRevenue = roundMoney(report.TotalRevenue)
+	report.TotalGrossProfit = roundMoney(report.TotalGrossProfit)
+
+	// Perbandingan dengan periode sebelum promo dimulai (hanya diskon yang masih ada)
+	for i := range report.Discounts {
+		line := &report.Discounts[i]
+		if line.DiscountID == nil {
+			continue
+		}
+		if err := r.compareDiscountPeriods(line, startDate, endDate); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// compareDiscountPeriods mengisi tipe, cakupan & perbandingan penjualan cakupan diskon:
+// periode promo = irisan rentang laporan dengan start_date - end_date diskon,
+// pembanding = periode sama panjang tepat sebelum start_date diskon
+func (r *ReportRepository) compareDiscountPeriods(line *models.DiscountReportLine, startDate, endDate time.Time) error {
+	var d models.Discount
+	err := r.db.QueryRow(`SELECT id, type, product_id, category_id, start_date, end_date FROM discounts WHERE id = $1`,
+		*line.DiscountID).Scan(&d.ID, &d.Type, &d.ProductID, &d.CategoryID, &d.StartDate, &d.EndDate)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	line.Type = string(d.Type)
+	line.ProductID = d.ProductID
+	line.CategoryID = d.CategoryID
+	if d.Type == models.DiscountBundle {
+		if err := loadPromotionRules(r.db, map[int]*models.Discount{d.ID: &d}); err != nil {
+			return err
+		}
+	}
+
+	promoStart, promoEnd := startDate, endDate
+	if d.StartDate.After(promoStart) {
+		promoStart = d.StartDate
+	}
+	if d.EndDate.Before(promoEnd) {
+		promoEnd = d.EndDate
+	}
+	if !promoEnd.After(promoStart) {
+		return nil
+	}
+	length := promoEnd.Sub(promoStart)
+
+	line.PromoPeriod, err = r.discountScopeSales(&d, promoStart, promoEnd)
+	if err != nil {
+		return err
+	}
+	line.BeforePromo, err = r.discountScopeSales(&d, d.StartDate.Add(-length), d.StartDate.Add(-time.Nanosecond))
+	if err != nil {
+		return err
+	}
+	line.RevenueGrowthPercent = growthPercent(line.PromoPeriod.Revenue, line.BeforePromo.Revenue)
+	line.ProfitGrowthPercent = growthPercent(line.PromoPeriod.GrossProfit, line.BeforePromo.GrossProfit)
+	return nil
+}
+
+// discountScopeSales menghitung penjualan cakupan diskon (produk / kategori / isi paket / semua produk)
+// dalam periode, termasuk baris yang tidak mendapat potongan
+func (r *ReportRepository) discountScopeSales(d *models.Discount, from, to time.Time) (*models.DiscountSalesSummary, error) {
+	args := []interface{}{from, to}
+	scope := ""
+	switch {
+	case d.ProductID != nil:
+		args = append(args, *d.ProductID)
+		scope = " AND product_id = $3"
+	case d.CategoryID != nil:
+		args = append(args, *d.CategoryID)
+		scope = " AND category_id = $3"
+	case d.Type == models.DiscountBundle && len(d.BundleItems) > 0:
+		placeholders := ""
+		for i, item := range d.BundleItems {
+			if i > 0 {
+				placeholders += ", "
+			}
+			args = append(args, item.ProductID)
+			placeholders += fmt.Sprintf("$%d", len(args))
+		}
+		scope = " AND product_id IN (" + placeholders + ")"
+	}
+
+	summary := &models.DiscountSalesSummary{StartDate: from, EndDate: to}
+	err := r.db.QueryRow(`
+		WITH `+discountReportLines+`
+		SELECT
+			COUNT(DISTINCT transaction_id),
+			COALESCE(SUM(quantity), 0),
+			COALESCE(SUM(revenue), 0),
+			COALESCE(SUM(profit), 0)
+		FROM lines
+		WHERE TRUE`+scope, args...).Scan(
+		&summary.TransactionCount, &summary.QuantitySold, &summary.Revenue, &summary.GrossProfit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	summary.Revenue = roundMoney(summary.Revenue)
+	summary.GrossProfit = roundMoney(summary.GrossProfit)
+	return summary, nil
+}
+
+// growthPercent = (sekarang - sebelum) / |sebelum| × 100 (nil jika sebelum = 0)
+func growthPercent(current, before float64) *float64 {
+	if before == 0 {
+		return nil
+	}
+	growth := math.Round((current-before)/math.Abs(before)*10000) / 100
+	return &growth
+}
+
+// GetShrinkageReport merangkum selisih stock opname yang diposting dalam periode (per produk)
+// Nilai memakai harga beli snapshot saat posting. Urut dari nilai susut terbesar.
+func (r *ReportRepository) GetShrinkageReport(startDate, endDate time.Time) (*models.ShrinkageReport, error) {
+	report := &models.ShrinkageReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Products:  []models.ShrinkageReportLine{},
+	}
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM stock_takes
+		WHERE status = 'posted' AND posted_at BETWEEN $1 AND $2
+	`, startDate, endDate).Scan(&report.StockTakes)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT
+			sti.product_id, COALESCE(p.nama, '(produk dihapus)'), COALESCE(c.nama, ''),
+			COUNT(DISTINCT st.id) as stock_takes,
+			COALESCE(SUM(-sti.variance) FILTER (WHERE sti.variance < 0), 0) as shrinkage_qty,
+			COALESCE(SUM(-sti.variance_value) FILTER (WHERE sti.variance < 0), 0) as shrinkage_value,
+			COALESCE(SUM(sti.variance) FILTER (WHERE sti.variance > 0), 0) as surplus_qty,
+			COALESCE(SUM(sti.variance_value) FILTER (WHERE sti.variance > 0), 0) as surplus_value
+		FROM stock_take_items sti
+		JOIN stock_takes st ON sti.stock_take_id = st.id
+		LEFT JOIN products p ON sti.product_id = p.id
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE st.status = 'posted' AND st.posted_at BETWEEN $1 AND $2 AND sti.variance <> 0
+		GROUP BY sti.product_id, p.nama, c.nama
+		ORDER BY shrinkage_value DESC, surplus_value DESC, sti.product_id
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line models.ShrinkageReportLine
+		if err := rows.Scan(&line.ProductID, &line.ProductName, &line.CategoryName, &line.StockTakes,
+			&line.ShrinkageQty, &line.ShrinkageValue, &line.SurplusQty, &line.SurplusValue); err != nil {
+			return nil, err
+		}
+		line.NetValue = roundMoney(line.SurplusValue - line.ShrinkageValue)
+		report.Products = append(report.Products, line)
+		report.ShrinkageQty += line.ShrinkageQty
+		report.ShrinkageValue += line.ShrinkageValue
+		report.SurplusQty += line.SurplusQty
+		report.SurplusValue += line.SurplusValue
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	report.ShrinkageValue = roundMoney(report.ShrinkageValue)
+	report.SurplusValue = roundMoney(report.SurplusValue)
+	report.NetValue = roundMoney(report.SurplusValue - report.ShrinkageValue)
+
+	return report, nil
+}
+
+// GetWriteOffReport merangkum penyesuaian stok yang disetujui dalam periode (per reason & per produk)
+// Nilai memakai harga beli snapshot saat dokumen dibuat. Dokumen pending / rejected tidak dihitung.
+func (r *ReportRepository) GetWriteOffReport(startDate, endDate time.Time) (*models.WriteOffReport, error) {
+	report := &models.WriteOffReport{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Reasons:   []models.WriteOffReasonTotal{},
+		Products:  []models.WriteOffProductTotal{},
+	}
+	err := r.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(write_off_value), 0), COALESCE(SUM(found_value), 0)
+		FROM stock_adjustments
+		WHERE status = 'approved' AND approved_at BETWEEN $1 AND $2
+	`, startDate, endDate).Scan(&report.Adjustments, &report.WriteOffValue, &report.FoundValue)
+	if err != nil {
+		return nil, err
+	}
+	report.TotalWriteOff = roundMoney(report.WriteOffValue - report.FoundValue)
+
+	// Per reason
+	rows, err := r.db.Query(`
+		SELECT sai.reason, COUNT(*), SUM(sai.quantity), SUM(sai.value)
+		FROM stock_adjustment_items sai
+		JOIN stock_adjustments sa ON sai.adjustment_id = sa.id
+		WHERE sa.status = 'approved' AND sa.approved_at BETWEEN $1 AND $2
+		GROUP BY sai.reason
+		ORDER BY SUM(sai.value) DESC, sai.reason
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var line models.WriteOffReasonTotal
+		if err := rows.Scan(&line.Reason, &line.Lines, &line.Quantity, &line.Value); err != nil {
+			return nil, err
+		}
+		report.Reasons = append(report.Reasons, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Per produk (hanya write-off, barang ditemukan tidak termasuk)
+	productRows, err := r.db.Query(`
+		SELECT sai.product_id, COALESCE(p.nama, '(produk dihapus)'), SUM(sai.quantity), SUM(sai.value)
+		FROM stock_adjustment_items sai
+		JOIN stock_adjustments sa ON sai.adjustment_id = sa.id
+		LEFT JOIN products p ON sai.product_id = p.id
+		WHERE sa.status = 'approved' AND sa.approved_at BETWEEN $1 AND $2 AND sai.reason <> 'found'
+		GROUP BY sai.product_id, p.nama
+		ORDER BY SUM(sai.value) DESC, sai.product_id
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer productRows.Close()
+	for productRows.Next() {
+		var line models.WriteOffProductTotal
+		if err := productRows.Scan(&line.ProductID, &line.ProductName, &line.Quantity, &line.Value); err != nil {
+			return nil, err
+		}
+		report.Products = append(report.Products, line)
+	}
+
+	return report, productRows.Err()
+}
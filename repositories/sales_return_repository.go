@@ -0,0 +1,456 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+	"math"
+)
+
+// SalesReturnRepository handles database operations for sales returns
+// Repository untuk retur penjualan (pengembalian sebagian item)
+type SalesReturnRepository struct {
+	db      *sql.DB
+	costing string // Metode harga pokok (average / fifo / last)
+}
+
+// NewSalesReturnRepository creates a new SalesReturnRepository
+func NewSalesReturnRepository(db *sql.DB, costing string) *SalesReturnRepository {
+	return &SalesReturnRepository{db: db, costing: costing}
+}
+
+// Create mencatat retur penjualan terhadap 1 transaksi
+// Semua dalam 1 database transaction (atomic):
+// - Lock header transaksi (FOR UPDATE) agar retur paralel tidak melebihi qty
+// - Hitung refund proporsional (diskon per-item & diskon global yang sudah di-snapshot)
+// - Kembalikan stok untuk item yang restock = true (dicatat di stock_movements)
+// - Transaksi kasbon: refund memotong sisa kasbon dulu, sisanya baru tunai
+// - Transaksi dengan tender poin: refund dikembalikan sebagai poin, sisanya baru tunai
+// - Poin yang didapat dari transaksi ditarik sebanding nilai yang diretur
+// - Simpan dokumen retur (header + items)
+func (r *SalesReturnRepository) Create(transactionID int, req *models.SalesReturnRequest) (*models.SalesReturn, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("gagal memulai transaksi: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// ─── STEP 1: Lock header transaksi ───
+	var totalAmount float64
+	var voidedAt sql.NullTime
+	err = tx.QueryRow(
+		"SELECT total_amount, voided_at FROM transactions WHERE id = $1 FOR UPDATE",
+		transactionID,
+	).Scan(&totalAmount, &voidedAt)
+	if err == sql.ErrNoRows {
+		err = fmt.Errorf("transaksi dengan ID %d tidak ditemukan", transactionID)
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data transaksi: %w", err)
+	}
+	if voidedAt.Valid {
+		err = models.ErrTransactionVoided
+		return nil, err
+	}
+
+	// ─── STEP 2: Ambil semua baris detail + qty yang sudah pernah diretur ───
+	type detailInfo struct {
+		ProductID   int
+		Quantity    int
+		Subtotal    float64
+		NetAmount   float64 // Subtotal - porsi diskon transaksi baris ini
+		HargaBeli   float64
+		TaxAmount   float64
+		ReturnedQty int
+	}
+	rows, err := tx.Query(`
+		SELECT
+			td.id,
+			td.product_id,
+			td.quantity,
+			td.subtotal,
+			COALESCE(td.order_discount_amount, 0),
+			COALESCE(td.harga_beli, 0),
+			COALESCE(td.tax_amount, 0),
+			COALESCE((SELECT SUM(sri.quantity) FROM sales_return_items sri WHERE sri.transaction_detail_id = td.id), 0)
+		FROM transaction_details td
+		WHERE td.transaction_id = $1
+	`, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil detail transaksi: %w", err)
+	}
+
+	detailMap := make(map[int]*detailInfo)
+	var sumNet float64
+	for rows.Next() {
+		var id int
+		var d detailInfo
+		var orderDiscount float64
+		if err = rows.Scan(&id, &d.ProductID, &d.Quantity, &d.Subtotal, &orderDiscount, &d.HargaBeli, &d.TaxAmount, &d.ReturnedQty); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		detailMap[id] = &d
+		d.NetAmount = d.Subtotal - orderDiscount
+		sumNet += d.NetAmount
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Diskon transaksi sudah dibagi per baris (order_discount_amount; transaksi lama = 0 dan
+	// diskonnya masuk rasio). Rasio: total_amount = SUM(net) (+ PPN exclusive & service charge
+	// - diskon transaksi lama), sehingga setiap rupiah net baris "dibayar" sebesar ratio
+	ratio := 0.0
+	if sumNet > 0 {
+		ratio = totalAmount / sumNet
+	}
+
+	// ─── STEP 3: Validasi & hitung refund per item ───
+	var totalRefund float64
+	items := make([]models.SalesReturnItem, 0, len(req.Items))
+	// Ledger pergerakan stok (return), reference_id diisi setelah header retur tersimpan
+	var movements []models.StockMovement
+	var restockCosts []float64 // Harga pokok per unit barang yang masuk stok lagi (sejajar movements)
+	for i, item := range req.Items {
+		d, exists := detailMap[item.TransactionDetailID]
+		if !exists {
+			err = fmt.Errorf("item #%d: detail transaksi ID %d tidak ditemukan di transaksi ini", i+1, item.TransactionDetailID)
+			return nil, err
+		}
+		if item.Quantity > d.Quantity-d.ReturnedQty {
+			err = fmt.Errorf("item #%d: %w (sisa: %d, diminta: %d)", i+1, models.ErrReturnQuantityExceeded, d.Quantity-d.ReturnedQty, item.Quantity)
+			return nil, err
+		}
+		// Tandai agar baris yang sama di request tidak lolos validasi 2x
+		d.ReturnedQty += item.Quantity
+
+		// Refund = harga nett per unit (sudah dikurangi diskon item & porsi diskon transaksi) × qty × rasio
+		unitNet := d.NetAmount / float64(d.Quantity)
+		refund := math.Round(unitNet*float64(item.Quantity)*ratio*100) / 100
+		totalRefund += refund
+
+		// PPN ikut dikembalikan sebanding qty yang diretur
+		taxRefund := math.Round(d.TaxAmount*float64(item.Quantity)/float64(d.Quantity)*100) / 100
+
+		restock := true
+		if item.Restock != nil {
+			restock = *item.Restock
+		}
+
+		// Kembalikan stok jika barang layak jual lagi
+		if restock {
+			m := models.StockMovement{
+				ProductID: d.ProductID,
+				Type:      models.StockMovementReturn,
+				Quantity:  item.Quantity,
+				CreatedBy: &req.CreatedBy,
+			}
+			err = moveStock(tx, &m)
+			if err != nil {
+				return nil, fmt.Errorf("item #%d: gagal mengembalikan stok: %w", i+1, err)
+			}
+			movements = append(movements, m)
+			restockCosts = append(restockCosts, d.HargaBeli)
+		}
+
+		items = append(items, models.SalesReturnItem{
+			TransactionDetailID: item.TransactionDetailID,
+			ProductID:           d.ProductID,
+			Quantity:            item.Quantity,
+			RefundAmount:        refund,
+			TaxAmount:           taxRefund,
+			HargaBeli:           d.HargaBeli,
+			Restock:             restock,
+		})
+	}
+
+	// ─── STEP 3B: Bagian refund yang memotong kasbon ───
+	// Maksimal sisa kasbon transaksi ini (belum diretur) dan sisa kasbon pelanggan
+	creditCustomerID, creditRefund, err := getReturnCreditRefund(tx, transactionID, totalRefund)
+	if err != nil {
+		return nil, err
+	}
+
+	// ─── STEP 3C: Bagian refund yang dikembalikan sebagai poin & poin yang ditarik ───
+	points, err := getReturnPoints(tx, transactionID, totalAmount, totalRefund, totalRefund-creditRefund)
+	if err != nil {
+		return nil, err
+	}
+
+	// ─── STEP 4: Insert header retur ───
+	// Refund keluar dari laci kasir yang memproses retur → hubungkan ke shift-nya
+	shiftID, err := getOpenShiftID(tx, req.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.SalesReturn{
+		TransactionID: transactionID,
+		TotalRefund:   totalRefund,
+		CreditRefund:  creditRefund,
+		PointsRefund:  points.RefundAmount,
+		Reason:        req.Reason,
+		CreatedBy:     &req.CreatedBy,
+		ShiftID:       shiftID,
+	}
+	err = tx.QueryRow(
+		`INSERT INTO sales_returns (transaction_id, total_refund, credit_refund, points_refund, reason, created_by, shift_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`,
+		transactionID, totalRefund, creditRefund, points.RefundAmount, req.Reason, req.CreatedBy, shiftID,
+	).Scan(&result.ID, &result.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan retur: %w", err)
+	}
+
+	if creditRefund > 0 {
+		err = insertCreditEntry(tx, &models.CreditLedgerEntry{
+			CustomerID:    creditCustomerID,
+			Type:          models.CreditEntryReturn,
+			Amount:        creditRefund,
+			TransactionID: &transactionID,
+			SalesReturnID: &result.ID,
+			CreatedBy:     &req.CreatedBy,
+			ShiftID:       shiftID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if points.RefundPoints > 0 {
+		err = insertPointEntry(tx, &models.PointLedgerEntry{
+			CustomerID:    points.CustomerID,
+			Type:          models.PointEntryRefund,
+			Points:        points.RefundPoints,
+			TransactionID: &transactionID,
+			SalesReturnID: &result.ID,
+			CreatedBy:     &req.CreatedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if points.ReversePoints > 0 {
+		err = insertPointEntry(tx, &models.PointLedgerEntry{
+			CustomerID:    points.CustomerID,
+			Type:          models.PointEntryReverse,
+			Points:        -points.ReversePoints,
+			TransactionID: &transactionID,
+			SalesReturnID: &result.ID,
+			CreatedBy:     &req.CreatedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// ─── STEP 5: Batch insert items retur ───
+	query := `INSERT INTO sales_return_items
+		(return_id, transaction_detail_id, product_id, quantity, refund_amount, harga_beli, restock, tax_amount) VALUES `
+	values := make([]interface{}, 0, len(items)*8)
+	for i, item := range items {
+		if i > 0 {
+			query += ", "
+		}
+		query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			i*8+1, i*8+2, i*8+3, i*8+4, i*8+5, i*8+6, i*8+7, i*8+8)
+		values = append(values,
+			result.ID, item.TransactionDetailID, item.ProductID, item.Quantity,
+			item.RefundAmount, item.HargaBeli, item.Restock, item.TaxAmount,
+		)
+	}
+	_, err = tx.Exec(query, values...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan detail retur: %w", err)
+	}
+
+	// ─── STEP 5B: Ledger pergerakan stok & lapisan biaya (barang yang masuk stok lagi) ───
+	// Barang kembali dengan harga pokok saat terjual (snapshot transaction_details.harga_beli)
+	for i := range movements {
+		movements[i].ReferenceID = &result.ID
+	}
+	err = insertStockMovements(tx, movements)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range movements {
+		err = receiveCost(tx, r.costing, m, restockCosts[i])
+		if err != nil {
+			return nil, fmt.Errorf("gagal memperbarui harga pokok: %w", err)
+		}
+	}
+
+	// ─── STEP 6: Commit ───
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("gagal commit retur: %w", err)
+	}
+
+	log.Printf("↩️ Retur ID %d untuk transaksi ID %d: refund %.0f, %d item", result.ID, transactionID, totalRefund, len(items))
+
+	for i := range items {
+		items[i].ReturnID = result.ID
+	}
+	result.Items = items
+
+	return result, nil
+}
+
+// getReturnCreditRefund menghitung bagian refund retur yang dipotong dari kasbon
+// Return: customer ID pemilik kasbon, nominal potongan kasbon (0 jika transaksi bukan kasbon)
+func getReturnCreditRefund(q queryer, transactionID int, totalRefund float64) (int, float64, error) {
+	var customerID sql.NullInt64
+	var remaining float64
+	err := q.QueryRow(`
+		SELECT MAX(customer_id),
+			COALESCE(SUM(CASE WHEN type = 'charge' THEN amount ELSE 0 END), 0)
+			- COALESCE(SUM(CASE WHEN type = 'return' THEN amount ELSE 0 END), 0)
+		FROM customer_credit_ledger
+		WHERE transaction_id = $1 AND type IN ('charge', 'return')
+	`, transactionID).Scan(&customerID, &remaining)
+	if err != nil {
+		return 0, 0, fmt.Errorf("gagal mengambil kasbon transaksi: %w", err)
+	}
+	if !customerID.Valid || remaining <= 0 {
+		return 0, 0, nil
+	}
+
+	// Lock pelanggan agar saldo tidak berubah oleh cicilan bersamaan
+	var lockedID int
+	err = q.QueryRow("SELECT id FROM customers WHERE id = $1 FOR UPDATE", customerID.Int64).Scan(&lockedID)
+	if err != nil {
+		return 0, 0, err
+	}
+	balance, err := getCreditBalance(q, int(customerID.Int64))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	creditRefund := math.Min(totalRefund, math.Min(remaining, balance))
+	if creditRefund < 0 {
+		creditRefund = 0
+	}
+	return int(customerID.Int64), roundMoney(creditRefund), nil
+}
+
+// returnPoints adalah dampak retur ke poin loyalitas pelanggan
+type returnPoints struct {
+	CustomerID    int
+	RefundAmount  float64 // Bagian refund (rupiah) yang dikembalikan sebagai poin
+	RefundPoints  int     // Poin yang dikembalikan ke pelanggan
+	ReversePoints int     // Poin earn transaksi yang ditarik
+}
+
+// getReturnPoints menghitung poin yang dikembalikan & ditarik karena retur
+// - Refund non-kasbon dikembalikan sebagai poin maksimal sisa tender poin transaksi,
+// nilai per poin mengikuti saat checkout (pecahan poin dikembalikan tunai)
+// - Poin earn yang tersisa untuk transaksi = poin earn awal × (total - semua retur) / total
+func getReturnPoints(q queryer, transactionID int, totalAmount, totalRefund, refundable float64) (*returnPoints, error) {
+	tp, err := getTransactionPoints(q, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	result := &returnPoints{CustomerID: tp.CustomerID}
+	if tp.CustomerID == 0 {
+		return result, nil
+	}
+
+	if tp.Redeemed > 0 && tp.RedeemedPoints > 0 && refundable > 0 {
+		valuePerPoint := tp.TenderAmount / float64(tp.RedeemedPoints)
+		maxAmount := math.Min(refundable, float64(tp.Redeemed)*valuePerPoint)
+		result.RefundPoints = int(math.Floor(maxAmount/valuePerPoint + 1e-9))
+		result.RefundAmount = roundMoney(float64(result.RefundPoints) * valuePerPoint)
+	}
+
+	if tp.Earned > 0 && totalAmount > 0 {
+		var previousRefund float64
+		err = q.QueryRow(
+			"SELECT COALESCE(SUM(total_refund), 0) FROM sales_returns WHERE transaction_id = $1",
+			transactionID,
+		).Scan(&previousRefund)
+		if err != nil {
+			return nil, fmt.Errorf("gagal mengambil retur sebelumnya: %w", err)
+		}
+		remainingTotal := math.Max(totalAmount-previousRefund-totalRefund, 0)
+		keep := int(math.Floor(float64(tp.EarnedPoints)*remainingTotal/totalAmount + 1e-9))
+		if tp.Earned > keep {
+			result.ReversePoints = tp.Earned - keep
+		}
+	}
+	return result, nil
+}
+
+// GetByTransactionID retrieves all returns for a transaction with their items
+// Fungsi ini mengambil riwayat retur untuk 1 transaksi
+func (r *SalesReturnRepository) GetByTransactionID(transactionID int) ([]models.SalesReturn, error) {
+	rows, err := r.db.Query(`
+		SELECT sr.id, sr.transaction_id, sr.total_refund, COALESCE(sr.credit_refund, 0), COALESCE(sr.points_refund, 0), sr.reason, sr.created_by, u.username, sr.shift_id, sr.created_at
+		FROM sales_returns sr
+		LEFT JOIN users u ON sr.created_by = u.id
+		WHERE sr.transaction_id = $1
+		ORDER BY sr.created_at
+	`, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data retur: %w", err)
+	}
+	defer rows.Close()
+
+	var returns []models.SalesReturn
+	indexByID := make(map[int]int)
+	for rows.Next() {
+		var sr models.SalesReturn
+		var username sql.NullString
+		if err := rows.Scan(&sr.ID, &sr.TransactionID, &sr.TotalRefund, &sr.CreditRefund, &sr.PointsRefund, &sr.Reason, &sr.CreatedBy, &username, &sr.ShiftID, &sr.CreatedAt); err != nil {
+			return nil, fmt.Errorf("gagal membaca data retur: %w", err)
+		}
+		if username.Valid {
+			sr.Username = username.String
+		}
+		sr.Items = []models.SalesReturnItem{}
+		indexByID[sr.ID] = len(returns)
+		returns = append(returns, sr)
+	}
+
+	if returns == nil {
+		return []models.SalesReturn{}, nil
+	}
+
+	itemRows, err := r.db.Query(`
+		SELECT
+			sri.id, sri.return_id, sri.transaction_detail_id, sri.product_id,
+			COALESCE(p.nama, 'Produk Dihapus') as product_name,
+			sri.quantity, sri.refund_amount, COALESCE(sri.tax_amount, 0), sri.harga_beli, sri.restock, sri.created_at
+		FROM sales_return_items sri
+		JOIN sales_returns sr ON sri.return_id = sr.id
+		LEFT JOIN products p ON sri.product_id = p.id
+		WHERE sr.transaction_id = $1
+		ORDER BY sri.id
+	`, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil detail retur: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item models.SalesReturnItem
+		if err := itemRows.Scan(
+			&item.ID, &item.ReturnID, &item.TransactionDetailID, &item.ProductID,
+			&item.ProductName, &item.Quantity, &item.RefundAmount, &item.TaxAmount, &item.HargaBeli,
+			&item.Restock, &item.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("gagal membaca detail retur: %w", err)
+		}
+		if idx, ok := indexByID[item.ReturnID]; ok {
+			returns[idx].Items = append(returns[idx].Items, item)
+		}
+	}
+
+	return returns, nil
+}
@@ -0,0 +1,355 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"kasir-api/models"
+	"log"
+)
+
+// ShiftRepository handles database operations for cashier shifts
+type ShiftRepository struct {
+	db *sql.DB
+}
+
+// NewShiftRepository creates a new ShiftRepository
+func NewShiftRepository(db *sql.DB) *ShiftRepository {
+	return &ShiftRepository{db: db}
+}
+
+// queryer adalah interface yang dipenuhi *sql.DB dan *sql.Tx
+// agar query rekap bisa dijalankan di dalam maupun di luar database transaction
+type queryer interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+const shiftSelectColumns = `
+	s.id, s.user_id, u.username, s.opening_float, s.opened_at, s.closed_at,
+	s.expected_cash, s.counted_cash, s.cash_variance, s.denominations, s.closing_note
+`
+
+// Open membuka shift baru untuk user
+// Partial unique index (1 shift terbuka per user) menjamin tidak ada shift ganda walau request paralel
+func (r *ShiftRepository) Open(userID int, openingFloat float64) (*models.CashierShift, error) {
+	var shift models.CashierShift
+	err := r.db.QueryRow(`
+		INSERT INTO cashier_shifts (user_id, opening_float)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) WHERE closed_at IS NULL DO NOTHING
+		RETURNING id, user_id, opening_float, opened_at
+	`, userID, openingFloat).Scan(&shift.ID, &shift.UserID, &shift.OpeningFloat, &shift.OpenedAt)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrShiftAlreadyOpen
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuka shift: %w", err)
+	}
+
+	log.Printf("🟢 Shift ID %d dibuka oleh user ID %d (modal awal: %.0f)", shift.ID, userID, openingFloat)
+	return &shift, nil
+}
+
+// GetOpenByUser mengambil shift yang sedang terbuka milik user
+func (r *ShiftRepository) GetOpenByUser(userID int) (*models.CashierShift, error) {
+	row := r.db.QueryRow(`
+		SELECT `+shiftSelectColumns+`
+		FROM cashier_shifts s
+		LEFT JOIN users u ON s.user_id = u.id
+		WHERE s.user_id = $1 AND s.closed_at IS NULL
+	`, userID)
+
+	shift, err := scanShift(row)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNoOpenShift
+	}
+	return shift, err
+}
+
+// GetByID mengambil 1 shift
+func (r *ShiftRepository) GetByID(id int) (*models.CashierShift, error) {
+	row := r.db.QueryRow(`
+		SELECT `+shiftSelectColumns+`
+		FROM cashier_shifts s
+		LEFT JOIN users u ON s.user_id = u.id
+		WHERE s.id = $1
+	`, id)
+
+	shift, err := scanShift(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("shift dengan ID %d tidak ditemukan", id)
+	}
+	return shift, err
+}
+
+// GetAll mengambil riwayat shift (terbaru dulu), opsional filter user
+func (r *ShiftRepository) GetAll(userID *int) ([]models.CashierShift, error) {
+	query := `
+		SELECT ` + shiftSelectColumns + `
+		FROM cashier_shifts s
+		LEFT JOIN users u ON s.user_id = u.id
+	`
+	args := []interface{}{}
+	if userID != nil {
+		query += " WHERE s.user_id = $1 "
+		args = append(args, *userID)
+	}
+	query += " ORDER BY s.opened_at DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data shift: %w", err)
+	}
+	defer rows.Close()
+
+	shifts := []models.CashierShift{}
+	for rows.Next() {
+		shift, err := scanShift(rows)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, *shift)
+	}
+	return shifts, nil
+}
+
+// Close menutup shift user yang sedang terbuka
+// Shift di-lock (FOR UPDATE) → checkout yang sedang berjalan (FOR SHARE) selesai dulu,
+// sehingga expected cash mencakup semua transaksi shift ini.
+func (r *ShiftRepository) Close(userID int, countedCash float64, denominations []models.ShiftDenomination, note *string) (*models.ShiftReport, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// ─── STEP 1: Lock shift yang terbuka ───
+	var shiftID int
+	err = tx.QueryRow(
+		"SELECT id FROM cashier_shifts WHERE user_id = $1 AND closed_at IS NULL FOR UPDATE",
+		userID,
+	).Scan(&shiftID)
+	if err == sql.ErrNoRows {
+		err = models.ErrNoOpenShift
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil shift: %w", err)
+	}
+
+	// ─── STEP 2: Hitung expected cash ───
+	var report *models.ShiftReport
+	report, err = getShiftReport(tx, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	variance := countedCash - report.ExpectedCash
+
+	// ─── STEP 3: Simpan hasil tutup shift ───
+	var denominationsJSON interface{}
+	if len(denominations) > 0 {
+		var b []byte
+		b, err = json.Marshal(denominations)
+		if err != nil {
+			return nil, err
+		}
+		denominationsJSON = string(b)
+	}
+	_, err = tx.Exec(`
+		UPDATE cashier_shifts
+		SET closed_at = NOW(), expected_cash = $1, counted_cash = $2, cash_variance = $3,
+		    denominations = $4, closing_note = $5
+		WHERE id = $6
+	`, report.ExpectedCash, countedCash, variance, denominationsJSON, note, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menutup shift: %w", err)
+	}
+
+	// ─── STEP 4: Commit ───
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔴 Shift ID %d ditutup: expected %.0f, counted %.0f, selisih %.0f", shiftID, report.ExpectedCash, countedCash, variance)
+
+	return r.GetReport(shiftID)
+}
+
+// GetReport menghitung rekap shift (bisa untuk shift yang masih terbuka)
+func (r *ShiftRepository) GetReport(shiftID int) (*models.ShiftReport, error) {
+	return getShiftReport(r.db, shiftID)
+}
+
+// getShiftReport menghitung rekap 1 shift
+// Expected cash = modal awal + penjualan tunai - refund tunai + cicilan kasbon tunai + pay in - pay out - setor brankas.
+// Penjualan tunai = baris pembayaran cash (sudah net kembalian); transaksi lama tanpa
+// baris pembayaran dianggap cash sebesar total_amount. Penjualan tetap tercatat di shift asalnya
+// walaupun kemudian di-void; porsi tunai void dihitung sebagai refund di shift yang memproses void.
+func getShiftReport(q queryer, shiftID int) (*models.ShiftReport, error) {
+	row := q.QueryRow(`
+		SELECT `+shiftSelectColumns+`
+		FROM cashier_shifts s
+		LEFT JOIN users u ON s.user_id = u.id
+		WHERE s.id = $1
+	`, shiftID)
+	shift, err := scanShift(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("shift dengan ID %d tidak ditemukan", shiftID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ShiftReport{Shift: *shift}
+
+	// Total penjualan, jumlah transaksi & pembulatan tunai
+	err = q.QueryRow(`
+		SELECT COALESCE(SUM(total_amount), 0), COUNT(*), COALESCE(SUM(rounding_amount), 0)
+		FROM transactions
+		WHERE shift_id = $1
+	`, shiftID).Scan(&report.TotalSales, &report.TransactionCount, &report.TotalRounding)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung penjualan shift: %w", err)
+	}
+
+	// Rincian per metode pembayaran
+	rows, err := q.Query(`
+		SELECT method, COALESCE(SUM(amount), 0) as amount, COUNT(DISTINCT transaction_id) as transaction_count
+		FROM (
+			SELECT tp.method, tp.amount, t.id as transaction_id
+			FROM transaction_payments tp
+			JOIN transactions t ON tp.transaction_id = t.id
+			WHERE t.shift_id = $1
+
+			UNION ALL
+
+			SELECT 'cash' as method, t.total_amount as amount, t.id as transaction_id
+			FROM transactions t
+			WHERE t.shift_id = $1
+			  AND NOT EXISTS (SELECT 1 FROM transaction_payments tp WHERE tp.transaction_id = t.id)
+		) p
+		GROUP BY method
+		ORDER BY amount DESC
+	`, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung pembayaran shift: %w", err)
+	}
+	report.PaymentBreakdown = []models.PaymentMethodTotal{}
+	for rows.Next() {
+		var m models.PaymentMethodTotal
+		if err := rows.Scan(&m.Method, &m.Amount, &m.TransactionCount); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if m.Method == models.PaymentMethodCash {
+			report.CashSales = m.Amount
+		}
+		report.PaymentBreakdown = append(report.PaymentBreakdown, m)
+	}
+	rows.Close()
+
+	// Refund retur yang diproses di shift ini (tunai dari laci)
+	// Bagian refund yang memotong kasbon / dikembalikan sebagai poin tidak keluar dari laci
+	err = q.QueryRow(`
+		SELECT COALESCE(SUM(total_refund - COALESCE(credit_refund, 0) - COALESCE(points_refund, 0)), 0)
+		FROM sales_returns
+		WHERE shift_id = $1
+	`, shiftID).Scan(&report.CashRefunds)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung refund shift: %w", err)
+	}
+
+	// Porsi tunai transaksi yang di-void di shift ini (dikembalikan dari laci, termasuk di cash_refunds)
+	err = q.QueryRow(`
+		SELECT COALESCE(SUM(
+			CASE WHEN EXISTS (SELECT 1 FROM transaction_payments tp WHERE tp.transaction_id = t.id)
+				THEN (SELECT COALESCE(SUM(tp.amount), 0) FROM transaction_payments tp
+				      WHERE tp.transaction_id = t.id AND tp.method = 'cash')
+				ELSE t.total_amount
+			END), 0)
+		FROM transactions t
+		WHERE t.voided_shift_id = $1
+	`, shiftID).Scan(&report.VoidRefunds)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung void shift: %w", err)
+	}
+	report.CashRefunds += report.VoidRefunds
+
+	// Cicilan kasbon tunai yang diterima di shift ini
+	err = q.QueryRow(`
+		SELECT COALESCE(SUM(amount), 0)
+		FROM customer_credit_ledger
+		WHERE shift_id = $1 AND type = 'repayment' AND method = 'cash'
+	`, shiftID).Scan(&report.CashRepayments)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung cicilan kasbon shift: %w", err)
+	}
+
+	// Pergerakan kas laci (pay in / pay out / setor brankas)
+	report.CashMovements, err = getCashMovementsByShift(q, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range report.CashMovements {
+		switch m.Type {
+		case models.CashMovementPayIn:
+			report.PayIns += m.Amount
+		case models.CashMovementPayOut:
+			report.PayOuts += m.Amount
+		case models.CashMovementDrop:
+			report.Drops += m.Amount
+		}
+	}
+
+	report.ExpectedCash = shift.OpeningFloat + report.CashSales - report.CashRefunds + report.CashRepayments +
+		report.PayIns - report.PayOuts - report.Drops
+	report.CountedCash = shift.CountedCash
+	report.CashVariance = shift.CashVariance
+
+	return report, nil
+}
+
+func scanShift(row rowScanner) (*models.CashierShift, error) {
+	var s models.CashierShift
+	var username sql.NullString
+	var denominations []byte
+	err := row.Scan(
+		&s.ID, &s.UserID, &username, &s.OpeningFloat, &s.OpenedAt, &s.ClosedAt,
+		&s.ExpectedCash, &s.CountedCash, &s.CashVariance, &denominations, &s.ClosingNote,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if username.Valid {
+		s.Username = username.String
+	}
+	if len(denominations) > 0 {
+		if err := json.Unmarshal(denominations, &s.Denominations); err != nil {
+			return nil, fmt.Errorf("gagal membaca pecahan shift ID %d: %w", s.ID, err)
+		}
+	}
+	return &s, nil
+}
+
+// getOpenShiftID mengambil ID shift terbuka milik user (nil jika tidak ada)
+// FOR SHARE → shift tidak bisa ditutup sampai transaksi pemanggil commit
+func getOpenShiftID(tx *sql.Tx, userID int) (*int, error) {
+	var shiftID int
+	err := tx.QueryRow(
+		"SELECT id FROM cashier_shifts WHERE user_id = $1 AND closed_at IS NULL FOR SHARE",
+		userID,
+	).Scan(&shiftID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil shift kasir: %w", err)
+	}
+	return &shiftID, nil
+}
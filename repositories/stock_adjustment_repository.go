@@ -0,0 +1,340 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+	"sort"
+)
+
+// StockAdjustmentRepository handles database operations for manual stock adjustments
+type StockAdjustmentRepository struct {
+	db      *sql.DB
+	costing string // Metode harga pokok (average / fifo / last)
+}
+
+// NewStockAdjustmentRepository creates a new StockAdjustmentRepository
+func NewStockAdjustmentRepository(db *sql.DB, costing string) *StockAdjustmentRepository {
+	return &StockAdjustmentRepository{db: db, costing: costing}
+}
+
+// Create mencatat dokumen penyesuaian stok multi-baris
+// Nilai setiap baris = quantity × harga beli produk saat ini. Jika approvalLimit > 0 dan nilai
+// write-off melebihi limit, dokumen berstatus pending (stok belum berubah) sampai disetujui admin.
+// Selain itu stok langsung disesuaikan dalam database transaction yang sama.
+func (r *StockAdjustmentRepository) Create(req *models.StockAdjustmentRequest, userID int, approvalLimit float64) (*models.StockAdjustment, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var createdBy *int
+	if userID > 0 {
+		createdBy = &userID
+	}
+	adj := &models.StockAdjustment{
+		Status:    models.StockAdjustmentStatusApproved,
+		Notes:     req.Notes,
+		CreatedBy: createdBy,
+		Items:     []models.StockAdjustmentItem{},
+	}
+
+	// ─── STEP 1: Snapshot harga beli & hitung nilai per baris ───
+	for _, line := range req.Items {
+		item := models.StockAdjustmentItem{
+			ProductID: line.ProductID,
+			Reason:    line.Reason,
+			Quantity:  line.Quantity,
+			Change:    models.AdjustmentDirection(line.Reason) * line.Quantity,
+			Note:      line.Note,
+		}
+		err = tx.QueryRow("SELECT nama, COALESCE(harga_beli, 0) FROM products WHERE id = $1", line.ProductID).
+			Scan(&item.ProductName, &item.HargaBeli)
+		if err == sql.ErrNoRows {
+			err = fmt.Errorf("produk dengan ID %d tidak ditemukan", line.ProductID)
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+		item.Value = roundMoney(float64(item.Quantity) * item.HargaBeli)
+		if item.Change < 0 {
+			adj.WriteOffValue += item.Value
+		} else {
+			adj.FoundValue += item.Value
+		}
+		adj.Items = append(adj.Items, item)
+	}
+	adj.WriteOffValue = roundMoney(adj.WriteOffValue)
+	adj.FoundValue = roundMoney(adj.FoundValue)
+
+	// ─── STEP 2: Write-off besar menunggu persetujuan ───
+	if approvalLimit > 0 && adj.WriteOffValue > approvalLimit {
+		adj.Status = models.StockAdjustmentStatusPending
+	}
+
+	// ─── STEP 3: Simpan header & baris ───
+	err = tx.QueryRow(`
+		INSERT INTO stock_adjustments (status, notes, write_off_value, found_value, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		adj.Status, adj.Notes, adj.WriteOffValue, adj.FoundValue, adj.CreatedBy,
+	).Scan(&adj.ID, &adj.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan penyesuaian stok: %w", err)
+	}
+	for i := range adj.Items {
+		item := &adj.Items[i]
+		err = tx.QueryRow(`
+			INSERT INTO stock_adjustment_items (adjustment_id, product_id, reason, quantity, harga_beli, value, note)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			adj.ID, item.ProductID, item.Reason, item.Quantity, item.HargaBeli, item.Value, item.Note,
+		).Scan(&item.ID)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menyimpan baris penyesuaian stok: %w", err)
+		}
+	}
+
+	// ─── STEP 4: Sesuaikan stok (jika tidak perlu persetujuan) ───
+	if adj.Status == models.StockAdjustmentStatusApproved {
+		if err = applyStockAdjustment(tx, r.costing, adj, createdBy); err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("📦 Penyesuaian stok #%d (%s): write-off %.2f, ditemukan %.2f", adj.ID, adj.Status, adj.WriteOffValue, adj.FoundValue)
+	return adj, nil
+}
+
+// applyStockAdjustment mengubah stok sesuai setiap baris, mencatat stock_movements (type adjustment)
+// dan menandai dokumen approved. Produk diproses urut ID agar lock tidak deadlock.
+// Stok tidak boleh menjadi negatif. Write-off dinilai ulang dengan harga pokok unit yang keluar
+// (COSTING_METHOD), barang ditemukan masuk sebagai lapisan biaya dengan harga beli snapshot.
+func applyStockAdjustment(tx *sql.Tx, method string, adj *models.StockAdjustment, approvedBy *int) error {
+	order := make([]int, len(adj.Items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return adj.Items[order[a]].ProductID < adj.Items[order[b]].ProductID
+	})
+
+	movements := make([]models.StockMovement, 0, len(adj.Items))
+	for _, i := range order {
+		item := &adj.Items[i]
+		note := fmt.Sprintf("Penyesuaian stok #%d: %s", adj.ID, item.Reason)
+		m := models.StockMovement{
+			ProductID:   item.ProductID,
+			Type:        models.StockMovementAdjustment,
+			Quantity:    item.Change,
+			ReferenceID: &adj.ID,
+			Note:        &note,
+			CreatedBy:   approvedBy,
+		}
+		if err := moveStock(tx, &m); err != nil {
+			return err
+		}
+		if m.StockAfter < 0 {
+			return &models.InsufficientStockError{
+				ProductID:   item.ProductID,
+				ProductName: item.ProductName,
+				Available:   m.StockBefore,
+				Requested:   item.Quantity,
+			}
+		}
+		if item.Change < 0 {
+			unitCost, err := consumeCost(tx, method, item.ProductID, item.Quantity)
+			if err != nil {
+				return err
+			}
+			item.HargaBeli = unitCost
+			item.Value = roundMoney(float64(item.Quantity) * unitCost)
+		} else if err := receiveCost(tx, method, m, item.HargaBeli); err != nil {
+			return err
+		}
+		item.StockBefore = &m.StockBefore
+		item.StockAfter = &m.StockAfter
+		if _, err := tx.Exec("UPDATE stock_adjustment_items SET stock_before = $1, stock_after = $2, harga_beli = $3, value = $4 WHERE id = $5",
+			m.StockBefore, m.StockAfter, item.HargaBeli, item.Value, item.ID); err != nil {
+			return err
+		}
+		movements = append(movements, m)
+	}
+	if err := insertStockMovements(tx, movements); err != nil {
+		return err
+	}
+
+	adj.WriteOffValue, adj.FoundValue = 0, 0
+	for _, item := range adj.Items {
+		if item.Change < 0 {
+			adj.WriteOffValue += item.Value
+		} else {
+			adj.FoundValue += item.Value
+		}
+	}
+	adj.WriteOffValue = roundMoney(adj.WriteOffValue)
+	adj.FoundValue = roundMoney(adj.FoundValue)
+	adj.Status = models.StockAdjustmentStatusApproved
+	adj.ApprovedBy = approvedBy
+	return tx.QueryRow(`
+		UPDATE stock_adjustments SET status = 'approved', approved_by = $1, approved_at = NOW(),
+			write_off_value = $2, found_value = $3
+		WHERE id = $4 RETURNING approved_at`, approvedBy, adj.WriteOffValue, adj.FoundValue, adj.ID).Scan(&adj.ApprovedAt)
+}
+
+// Approve menyetujui dokumen pending dan menyesuaikan stok
+func (r *StockAdjustmentRepository) Approve(id int, adminID int) (*models.StockAdjustment, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	adj, err := getStockAdjustment(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if adj.Status != models.StockAdjustmentStatusPending {
+		err = models.ErrStockAdjustmentNotPending
+		return nil, err
+	}
+	if err = applyStockAdjustment(tx, r.costing, adj, &adminID); err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Penyesuaian stok #%d disetujui oleh user %d", id, adminID)
+	return adj, nil
+}
+
+// Reject menolak dokumen pending (stok tidak berubah)
+func (r *StockAdjustmentRepository) Reject(id int, adminID int, reason string) error {
+	result, err := r.db.Exec(`
+		UPDATE stock_adjustments SET status = 'rejected', rejected_by = $1, rejected_at = NOW(), reject_reason = $2
+		WHERE id = $3 AND status = 'pending'`, adminID, reason, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		if _, err := getStockAdjustment(r.db, id, false); err != nil {
+			return err
+		}
+		return models.ErrStockAdjustmentNotPending
+	}
+	return nil
+}
+
+const stockAdjustmentColumns = `
+	sa.id, sa.status, sa.notes, sa.write_off_value, sa.found_value, sa.created_by, COALESCE(u.username, ''),
+	sa.created_at, sa.approved_by, sa.approved_at, sa.rejected_by, sa.rejected_at, sa.reject_reason`
+
+func scanStockAdjustment(row rowScanner) (*models.StockAdjustment, error) {
+	var adj models.StockAdjustment
+	err := row.Scan(&adj.ID, &adj.Status, &adj.Notes, &adj.WriteOffValue, &adj.FoundValue, &adj.CreatedBy, &adj.Username,
+		&adj.CreatedAt, &adj.ApprovedBy, &adj.ApprovedAt, &adj.RejectedBy, &adj.RejectedAt, &adj.RejectReason)
+	if err != nil {
+		return nil, err
+	}
+	return &adj, nil
+}
+
+// getStockAdjustment mengambil dokumen beserta barisnya (forUpdate = lock header dalam tx)
+func getStockAdjustment(q queryer, id int, forUpdate bool) (*models.StockAdjustment, error) {
+	query := `SELECT ` + stockAdjustmentColumns + `
+		FROM stock_adjustments sa LEFT JOIN users u ON sa.created_by = u.id
+		WHERE sa.id = $1`
+	if forUpdate {
+		query += " FOR UPDATE OF sa"
+	}
+	adj, err := scanStockAdjustment(q.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("penyesuaian stok dengan ID %d tidak ditemukan", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(`
+		SELECT sai.id, sai.product_id, COALESCE(p.nama, ''), sai.reason, sai.quantity, sai.harga_beli, sai.value,
+			sai.note, sai.stock_before, sai.stock_after
+		FROM stock_adjustment_items sai
+		LEFT JOIN products p ON sai.product_id = p.id
+		WHERE sai.adjustment_id = $1
+		ORDER BY sai.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	adj.Items = []models.StockAdjustmentItem{}
+	for rows.Next() {
+		var item models.StockAdjustmentItem
+		if err := rows.Scan(&item.ID, &item.ProductID, &item.ProductName, &item.Reason, &item.Quantity, &item.HargaBeli, &item.Value,
+			&item.Note, &item.StockBefore, &item.StockAfter); err != nil {
+			return nil, err
+		}
+		item.Change = models.AdjustmentDirection(item.Reason) * item.Quantity
+		adj.Items = append(adj.Items, item)
+	}
+	return adj, rows.Err()
+}
+
+// GetByID returns an adjustment document with its lines
+func (r *StockAdjustmentRepository) GetByID(id int) (*models.StockAdjustment, error) {
+	return getStockAdjustment(r.db, id, false)
+}
+
+// GetAll returns adjustment documents (terbaru dulu) with pagination, optional filter status
+func (r *StockAdjustmentRepository) GetAll(status string, pagination *models.PaginationParams) ([]models.StockAdjustment, int, error) {
+	where := ""
+	args := []interface{}{}
+	if status != "" {
+		where = " WHERE sa.status = $1"
+		args = append(args, status)
+	}
+
+	var totalCount int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM stock_adjustments sa"+where, args...).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, pagination.Limit, pagination.GetOffset())
+	rows, err := r.db.Query(`SELECT `+stockAdjustmentColumns+`
+		FROM stock_adjustments sa LEFT JOIN users u ON sa.created_by = u.id`+where+
+		fmt.Sprintf(" ORDER BY sa.id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args)), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	adjustments := []models.StockAdjustment{}
+	for rows.Next() {
+		adj, err := scanStockAdjustment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		adjustments = append(adjustments, *adj)
+	}
+	return adjustments, totalCount, nil
+}
@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+)
+
+// StockMovementRepository handles database operations for the stock ledger
+type StockMovementRepository struct {
+	db *sql.DB
+}
+
+// NewStockMovementRepository creates a new StockMovementRepository
+func NewStockMovementRepository(db *sql.DB) *StockMovementRepository {
+	return &StockMovementRepository{db: db}
+}
+
+// moveStock mengubah stok 1 produk sebesar m.Quantity (+ masuk, - keluar) dan mengisi
+// m.StockBefore / m.StockAfter. Ledger-nya dicatat lewat insertStockMovements dalam tx yang sama
+// (setelah reference_id diketahui).
+func moveStock(tx *sql.Tx, m *models.StockMovement) error {
+	err := tx.QueryRow("UPDATE products SET stok = stok + $1 WHERE id = $2 RETURNING stok", m.Quantity, m.ProductID).Scan(&m.StockAfter)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("produk dengan ID %d tidak ditemukan", m.ProductID)
+	}
+	if err != nil {
+		return err
+	}
+	m.StockBefore = m.StockAfter - m.Quantity
+	return nil
+}
+
+// insertStockMovements mencatat pergerakan stok (batch, 1 query). Pergerakan dengan quantity 0 diskip.
+func insertStockMovements(tx *sql.Tx, movements []models.StockMovement) error {
+	query := `INSERT INTO stock_movements
+		(product_id, type, quantity, stock_before, stock_after, reference_id, note, created_by) VALUES `
+	args := make([]interface{}, 0, len(movements)*8)
+	for _, m := range movements {
+		if m.Quantity == 0 {
+			continue
+		}
+		if len(args) > 0 {
+			query += ", "
+		}
+		n := len(args)
+		query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8)
+		args = append(args, m.ProductID, m.Type, m.Quantity, m.StockBefore, m.StockAfter, m.ReferenceID, m.Note, m.CreatedBy)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("gagal mencatat pergerakan stok: %w", err)
+	}
+	return nil
+}
+
+// GetByProduct returns the stock history of a product (terbaru dulu) with pagination
+func (r *StockMovementRepository) GetByProduct(productID int, pagination *models.PaginationParams) ([]models.StockMovement, int, error) {
+	var totalCount int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM stock_movements WHERE product_id = $1", productID).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT sm.id, sm.product_id, COALESCE(p.nama, ''), sm.type, sm.quantity, sm.stock_before, sm.stock_after,
+			sm.reference_id, sm.note, sm.created_by, COALESCE(u.username, ''), sm.created_at
+		FROM stock_movements sm
+		LEFT JOIN products p ON sm.product_id = p.id
+		LEFT JOIN users u ON sm.created_by = u.id
+		WHERE sm.product_id = $1
+		ORDER BY sm.id DESC
+		LIMIT $2 OFFSET $3
+	`, productID, pagination.Limit, pagination.GetOffset())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	movements := []models.StockMovement{}
+	for rows.Next() {
+		var m models.StockMovement
+		if err := rows.Scan(&m.ID, &m.ProductID, &m.ProductName, &m.Type, &m.Quantity, &m.StockBefore, &m.StockAfter,
+			&m.ReferenceID, &m.Note, &m.CreatedBy, &m.Username, &m.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		movements = append(movements, m)
+	}
+	return movements, totalCount, nil
+}
+
+// Verify menghitung ulang stok setiap produk dari ledger dan membandingkan dengan products.stok
+// Konsisten jika SUM(quantity) = stok, stock_after terakhir = stok dan setiap stock_before
+// sama dengan stock_after pergerakan sebelumnya. all = false → hanya produk yang tidak konsisten.
+func (r *StockMovementRepository) Verify(all bool) (*models.StockLedgerReport, error) {
+	rows, err := r.db.Query(`
+		SELECT
+			p.id, p.nama, p.stok,
+			COALESCE(SUM(sm.quantity), 0) as ledger_stock,
+			COUNT(sm.id) as movements,
+			COUNT(*) FILTER (WHERE sm.id IS NOT NULL AND sm.stock_before <> COALESCE(sm.prev_after, 0)) as broken_chain,
+			(SELECT l.stock_after FROM stock_movements l WHERE l.product_id = p.id ORDER BY l.id DESC LIMIT 1) as last_stock_after
+		FROM products p
+		LEFT JOIN (
+			SELECT id, product_id, quantity, stock_before,
+				LAG(stock_after) OVER (PARTITION BY product_id ORDER BY id) as prev_after
+			FROM stock_movements
+		) sm ON sm.product_id = p.id
+		GROUP BY p.id, p.nama, p.stok
+		ORDER BY p.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &models.StockLedgerReport{Products: []models.StockLedgerCheck{}}
+	for rows.Next() {
+		var c models.StockLedgerCheck
+		if err := rows.Scan(&c.ProductID, &c.ProductName, &c.Stock, &c.LedgerStock, &c.Movements, &c.BrokenChain, &c.LastStockAfter); err != nil {
+			return nil, err
+		}
+		lastAfter := 0
+		if c.LastStockAfter != nil {
+			lastAfter = *c.LastStockAfter
+		}
+		c.Difference = c.Stock - c.LedgerStock
+		c.Consistent = c.Difference == 0 && lastAfter == c.Stock && c.BrokenChain == 0
+
+		report.CheckedProducts++
+		if !c.Consistent {
+			report.Mismatches++
+		}
+		if all || !c.Consistent {
+			report.Products = append(report.Products, c)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,547 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+	"math"
+)
+
+// StockTakeRepository handles database operations for stock opname sessions
+type StockTakeRepository struct {
+	db      *sql.DB
+	costing string // Metode harga pokok (average / fifo / last)
+}
+
+// NewStockTakeRepository creates a new StockTakeRepository
+func NewStockTakeRepository(db *sql.DB, costing string) *StockTakeRepository {
+	return &StockTakeRepository{db: db, costing: costing}
+}
+
+// stockTakeScope mengembalikan filter produk (alias p) sesuai cakupan sesi; argumen kategori = $argN
+func stockTakeScope(st *models.StockTake, argN int) (string, []interface{}) {
+	if st.Scope == models.StockTakeScopeCategory && st.CategoryID != nil {
+		return fmt.Sprintf("p.category_id = $%d", argN), []interface{}{*st.CategoryID}
+	}
+	return "TRUE", nil
+}
+
+// Create membuka sesi stock opname baru
+// Ditolak jika masih ada sesi terbuka yang cakupannya beririsan (seluruh toko / kategori yang sama)
+func (r *StockTakeRepository) Create(req *models.StockTakeRequest, userID int) (*models.StockTake, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Serialisasi pembukaan sesi agar 2 sesi beririsan tidak bisa dibuka bersamaan
+	if _, err = tx.Exec("LOCK TABLE stock_takes IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return nil, err
+	}
+	var overlap bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM stock_takes
+			WHERE status = 'open' AND ($1 = 'all' OR scope = 'all' OR category_id = $2)
+		)`, req.Scope, req.CategoryID).Scan(&overlap)
+	if err != nil {
+		return nil, err
+	}
+	if overlap {
+		err = models.ErrStockTakeOverlap
+		return nil, err
+	}
+
+	var createdBy *int
+	if userID > 0 {
+		createdBy = &userID
+	}
+	st := &models.StockTake{
+		Name:       req.Name,
+		Scope:      req.Scope,
+		CategoryID: req.CategoryID,
+		Status:     models.StockTakeStatusOpen,
+		Notes:      req.Notes,
+		CreatedBy:  createdBy,
+	}
+	err = tx.QueryRow(`
+		INSERT INTO stock_takes (name, scope, category_id, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		st.Name, st.Scope, st.CategoryID, st.Notes, st.CreatedBy,
+	).Scan(&st.ID, &st.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuka stock opname: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("📋 Stock opname #%d dibuka: %s (%s)", st.ID, st.Name, st.Scope)
+	return st, nil
+}
+
+const stockTakeColumns = `
+	st.id, st.name, st.scope, st.category_id, COALESCE(c.nama, ''), st.status, st.notes,
+	st.created_by, st.created_at, st.posted_by, st.posted_at, st.cancelled_at`
+
+func scanStockTake(row rowScanner) (*models.StockTake, error) {
+	var st models.StockTake
+	err := row.Scan(&st.ID, &st.Name, &st.Scope, &st.CategoryID, &st.CategoryName, &st.Status, &st.Notes,
+		&st.CreatedBy, &st.CreatedAt, &st.PostedBy, &st.PostedAt, &st.CancelledAt)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// GetAll returns stock opname sessions (terbaru dulu), optional filter status
+func (r *StockTakeRepository) GetAll(status string) ([]models.StockTake, error) {
+	query := `SELECT ` + stockTakeColumns + `
+		FROM stock_takes st LEFT JOIN categories c ON st.category_id = c.id`
+	var args []interface{}
+	if status != "" {
+		query += " WHERE st.status = $1"
+		args = append(args, status)
+	}
+	query += " ORDER BY st.id DESC"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.StockTake{}
+	for rows.Next() {
+		st, err := scanStockTake(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *st)
+	}
+	return sessions, nil
+}
+
+// getStockTakeHeader mengambil header sesi (forUpdate = lock baris dalam tx)
+func getStockTakeHeader(q queryer, id int, forUpdate bool) (*models.StockTake, error) {
+	query := `SELECT ` + stockTakeColumns + `
+		FROM stock_takes st LEFT JOIN categories c ON st.category_id = c.id
+		WHERE st.id = $1`
+	if forUpdate {
+		query += " FOR UPDATE OF st"
+	}
+	st, err := scanStockTake(q.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("stock opname dengan ID %d tidak ditemukan", id)
+	}
+	return st, err
+}
+
+// GetByID returns a session with counted items and variance summary
+// Sesi open: selisih dihitung terhadap snapshot stok saat produk pertama kali dihitung (produk yang belum
+// dihitung: products.stok saat ini; withUncounted = tampilkan juga produk tsb). Sesi posted: snapshot saat posting.
+func (r *StockTakeRepository) GetByID(id int, withUncounted bool) (*models.StockTake, error) {
+	st, err := getStockTakeHeader(r.db, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows *sql.Rows
+	if st.Status == models.StockTakeStatusOpen {
+		scope, scopeArgs := stockTakeScope(st, 2)
+		join := "JOIN"
+		where := ""
+		args := []interface{}{id}
+		if withUncounted {
+			join = "RIGHT JOIN"
+			where = " WHERE sti.product_id IS NOT NULL OR " + scope
+			args = append(args, scopeArgs...)
+		}
+		rows, err = r.db.Query(`
+			SELECT p.id, p.nama, p.barcode, sti.counted_qty, COALESCE(sti.system_stock, p.stok), COALESCE(p.harga_beli, 0)
+			FROM (SELECT * FROM stock_take_items WHERE stock_take_id = $1) sti
+			`+join+` products p ON sti.product_id = p.id`+where+`
+			ORDER BY p.nama`, args...)
+	} else {
+		rows, err = r.db.Query(`
+			SELECT sti.product_id, COALESCE(p.nama, ''), p.barcode, sti.counted_qty,
+				COALESCE(sti.system_stock, 0), COALESCE(sti.harga_beli, 0)
+			FROM stock_take_items sti
+			LEFT JOIN products p ON sti.product_id = p.id
+			WHERE sti.stock_take_id = $1
+			ORDER BY p.nama`, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summary := &models.StockTakeTotal{}
+	st.Items = []models.StockTakeItem{}
+	for rows.Next() {
+		var item models.StockTakeItem
+		if err := rows.Scan(&item.ProductID, &item.ProductName, &item.Barcode, &item.CountedQty,
+			&item.SystemStock, &item.HargaBeli); err != nil {
+			return nil, err
+		}
+		if item.CountedQty != nil {
+			item.Variance = *item.CountedQty - item.SystemStock
+			item.VarianceValue = roundMoney(float64(item.Variance) * item.HargaBeli)
+			addStockTakeVariance(summary, item)
+		}
+		st.Items = append(st.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Jumlah produk dalam cakupan: sesi open dari products saat ini, sesi posted dari snapshot
+	if st.Status == models.StockTakeStatusOpen {
+		scope, scopeArgs := stockTakeScope(st, 1)
+		if err := r.db.QueryRow("SELECT COUNT(*) FROM products p WHERE "+scope, scopeArgs...).Scan(&summary.ProductsInScope); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := r.db.QueryRow("SELECT COALESCE(products_in_scope, 0) FROM stock_takes WHERE id = $1", id).Scan(&summary.ProductsInScope); err != nil {
+			return nil, err
+		}
+	}
+	summary.UncountedProducts = max(summary.ProductsInScope-summary.CountedProducts, 0)
+	summary.ShrinkageValue = roundMoney(summary.ShrinkageValue)
+	summary.SurplusValue = roundMoney(summary.SurplusValue)
+	summary.NetVarianceValue = roundMoney(summary.SurplusValue - summary.ShrinkageValue)
+	st.Summary = summary
+	return st, nil
+}
+
+// addStockTakeVariance menambahkan selisih 1 produk ke ringkasan sesi
+func addStockTakeVariance(t *models.StockTakeTotal, item models.StockTakeItem) {
+	t.CountedProducts++
+	if item.Variance < 0 {
+		t.ShrinkageQty += -item.Variance
+		t.ShrinkageValue += math.Abs(item.VarianceValue)
+	} else if item.Variance > 0 {
+		t.SurplusQty += item.Variance
+		t.SurplusValue += item.VarianceValue
+	}
+}
+
+// RecordCounts mencatat 1 batch hasil hitung fisik (scan barcode) ke sesi yang masih open
+// Mode add: hitungan ditambahkan ke total produk (scan rak berikutnya); mode set: total diganti.
+// Stok sistem di-snapshot saat produk pertama kali dihitung, agar penjualan / pembelian di antara
+// hitung fisik dan posting tidak ikut terhitung sebagai selisih.
+// Setiap baris dicatat di stock_take_counts sebagai audit trail.
+func (r *StockTakeRepository) RecordCounts(id int, req *models.StockCountRequest, userID int) ([]models.StockTakeItem, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// ─── STEP 1: Lock sesi, pastikan masih open ───
+	st, err := getStockTakeHeader(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status != models.StockTakeStatusOpen {
+		err = models.ErrStockTakeNotOpen
+		return nil, err
+	}
+
+	var countedBy *int
+	if userID > 0 {
+		countedBy = &userID
+	}
+
+	// ─── STEP 2: Resolve produk & simpan hitungan per baris ───
+	updated := map[int]int{} // product_id → index di result
+	result := []models.StockTakeItem{}
+	for _, line := range req.Items {
+		var item models.StockTakeItem
+		var categoryID *int
+		if line.Barcode != "" {
+			err = tx.QueryRow("SELECT id, nama, barcode, category_id, stok FROM products WHERE barcode = $1", line.Barcode).
+				Scan(&item.ProductID, &item.ProductName, &item.Barcode, &categoryID, &item.SystemStock)
+			if err == sql.ErrNoRows {
+				err = fmt.Errorf("barcode %s tidak terdaftar", line.Barcode)
+				return nil, err
+			}
+		} else {
+			err = tx.QueryRow("SELECT id, nama, barcode, category_id, stok FROM products WHERE id = $1", *line.ProductID).
+				Scan(&item.ProductID, &item.ProductName, &item.Barcode, &categoryID, &item.SystemStock)
+			if err == sql.ErrNoRows {
+				err = fmt.Errorf("produk dengan ID %d tidak terdaftar", *line.ProductID)
+				return nil, err
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if st.Scope == models.StockTakeScopeCategory && (categoryID == nil || st.CategoryID == nil || *categoryID != *st.CategoryID) {
+			err = fmt.Errorf("%w: %s", models.ErrProductOutOfStockTake, item.ProductName)
+			return nil, err
+		}
+
+		// Produk yang sudah pernah dihitung: pakai hitungan & snapshot stok yang tersimpan
+		current := 0
+		var snapshot *int
+		err = tx.QueryRow("SELECT counted_qty, system_stock FROM stock_take_items WHERE stock_take_id = $1 AND product_id = $2 FOR UPDATE",
+			id, item.ProductID).Scan(&current, &snapshot)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		err = nil
+		if snapshot != nil {
+			item.SystemStock = *snapshot
+		}
+
+		counted := current + line.Quantity
+		if req.Mode == models.StockCountModeSet {
+			counted = line.Quantity
+		}
+		if counted < 0 {
+			err = fmt.Errorf("%w: hitungan %s menjadi %d", models.ErrInvalidCountQuantity, item.ProductName, counted)
+			return nil, err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO stock_take_items (stock_take_id, product_id, counted_qty, system_stock)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (stock_take_id, product_id)
+			DO UPDATE SET counted_qty = EXCLUDED.counted_qty,
+				system_stock = COALESCE(stock_take_items.system_stock, EXCLUDED.system_stock), updated_at = NOW()`,
+			id, item.ProductID, counted, item.SystemStock)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menyimpan hitungan: %w", err)
+		}
+
+		var barcode *string
+		if line.Barcode != "" {
+			barcode = &line.Barcode
+		}
+		_, err = tx.Exec(`
+			INSERT INTO stock_take_counts (stock_take_id, product_id, barcode, quantity, counted_qty, counted_by)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			id, item.ProductID, barcode, counted-current, counted, countedBy)
+		if err != nil {
+			return nil, fmt.Errorf("gagal mencatat log hitungan: %w", err)
+		}
+
+		item.CountedQty = &counted
+		item.Variance = counted - item.SystemStock
+		if idx, ok := updated[item.ProductID]; ok {
+			result[idx] = item
+		} else {
+			updated[item.ProductID] = len(result)
+			result = append(result, item)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("📋 Stock opname #%d: %d baris hitungan dicatat (%s)", id, len(req.Items), req.Mode)
+	return result, nil
+}
+
+// Post memposting sesi stock opname secara atomik:
+// selisih = hitungan - snapshot stok saat produk dihitung; hanya selisih itu yang diterapkan ke stok saat ini
+// (penjualan / pembelian setelah hitung fisik tetap utuh). Selisih & nilainya (harga beli) di-snapshot
+// ke stock_take_items, dan setiap perubahan dicatat di stock_movements (type stock_take).
+// uncountedAsZero = true → produk dalam cakupan yang belum dihitung dianggap stok 0 (terhadap stok saat ini).
+func (r *StockTakeRepository) Post(id int, req *models.StockTakePostRequest, userID int) (*models.StockTake, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// ─── STEP 1: Lock sesi, pastikan masih open ───
+	st, err := getStockTakeHeader(tx, id, true)
+	if err != nil {
+		return nil, err
+	}
+	if st.Status != models.StockTakeStatusOpen {
+		err = models.ErrStockTakeNotOpen
+		return nil, err
+	}
+
+	// ─── STEP 2: Ambil hitungan & snapshot stok saat dihitung ───
+	type stockCount struct {
+		counted  int
+		snapshot *int // NULL = hitungan sebelum snapshot ada → pakai stok saat posting
+	}
+	counts := map[int]stockCount{}
+	rows, err := tx.Query("SELECT product_id, counted_qty, system_stock FROM stock_take_items WHERE stock_take_id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var productID int
+		var c stockCount
+		if err = rows.Scan(&productID, &c.counted, &c.snapshot); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		counts[productID] = c
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 && !req.UncountedAsZero {
+		err = models.ErrStockTakeNothingCounted
+		return nil, err
+	}
+
+	// ─── STEP 3: Lock produk dalam cakupan + produk yang dihitung (urut id, hindari deadlock) ───
+	type lockedProduct struct {
+		id        int
+		stok      int
+		hargaBeli float64
+		inScope   bool
+	}
+	scope, scopeArgs := stockTakeScope(st, 2)
+	rows, err = tx.Query(`
+		SELECT p.id, p.stok, COALESCE(p.harga_beli, 0), COALESCE(`+scope+`, FALSE)
+		FROM products p
+		WHERE `+scope+` OR p.id IN (SELECT product_id FROM stock_take_items WHERE stock_take_id = $1)
+		ORDER BY p.id
+		FOR UPDATE`, append([]interface{}{id}, scopeArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	products := []lockedProduct{}
+	for rows.Next() {
+		var p lockedProduct
+		if err = rows.Scan(&p.id, &p.stok, &p.hargaBeli, &p.inScope); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// ─── STEP 4: Snapshot selisih & sesuaikan stok ───
+	var postedBy *int
+	if userID > 0 {
+		postedBy = &userID
+	}
+	note := fmt.Sprintf("Stock opname #%d: %s", id, st.Name)
+	movements := []models.StockMovement{}
+	productsInScope := 0
+	for _, p := range products {
+		if p.inScope {
+			productsInScope++
+		}
+		c, ok := counts[p.id]
+		if !ok && (!req.UncountedAsZero || !p.inScope) {
+			continue
+		}
+		systemStock := p.stok
+		if c.snapshot != nil {
+			systemStock = *c.snapshot
+		}
+		variance := c.counted - systemStock
+		// Stok berkurang lebih banyak dari hitungan fisik sejak dihitung → hitungan tidak konsisten
+		if p.stok+variance < 0 {
+			err = fmt.Errorf("%w: produk ID %d (hitungan %d, stok saat dihitung %d, stok sekarang %d)",
+				models.ErrStockTakeCountOutdated, p.id, c.counted, systemStock, p.stok)
+			return nil, err
+		}
+		unitCost := p.hargaBeli
+		if variance != 0 {
+			m := models.StockMovement{
+				ProductID:   p.id,
+				Type:        models.StockMovementStockTake,
+				Quantity:    variance,
+				ReferenceID: &id,
+				Note:        &note,
+				CreatedBy:   postedBy,
+			}
+			if err = moveStock(tx, &m); err != nil {
+				return nil, err
+			}
+			// Susut mengurangi lapisan biaya (nilai = harga pokok unit yang hilang),
+			// lebih dicatat sebagai lapisan baru dengan harga pokok saat ini
+			if variance < 0 {
+				unitCost, err = consumeCost(tx, r.costing, p.id, -variance)
+			} else {
+				err = receiveCost(tx, r.costing, m, p.hargaBeli)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("gagal memperbarui harga pokok: %w", err)
+			}
+			movements = append(movements, m)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO stock_take_items (stock_take_id, product_id, counted_qty, system_stock, variance, harga_beli, variance_value)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (stock_take_id, product_id)
+			DO UPDATE SET system_stock = EXCLUDED.system_stock, variance = EXCLUDED.variance,
+				harga_beli = EXCLUDED.harga_beli, variance_value = EXCLUDED.variance_value`,
+			id, p.id, c.counted, systemStock, variance, unitCost, roundMoney(float64(variance)*unitCost))
+		if err != nil {
+			return nil, fmt.Errorf("gagal menyimpan selisih stock opname: %w", err)
+		}
+	}
+	if err = insertStockMovements(tx, movements); err != nil {
+		return nil, err
+	}
+
+	// ─── STEP 5: Tandai sesi posted ───
+	_, err = tx.Exec(`
+		UPDATE stock_takes SET status = 'posted', posted_by = $1, posted_at = NOW(), products_in_scope = $2
+		WHERE id = $3`, postedBy, productsInScope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("📋 Stock opname #%d diposting: %d produk disesuaikan", id, len(movements))
+	return r.GetByID(id, false)
+}
+
+// Cancel membatalkan sesi yang masih open (hitungan tetap disimpan, stok tidak berubah)
+func (r *StockTakeRepository) Cancel(id int) error {
+	result, err := r.db.Exec(`
+		UPDATE stock_takes SET status = 'cancelled', cancelled_at = NOW()
+		WHERE id = $1 AND status = 'open'`, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		if _, err := getStockTakeHeader(r.db, id, false); err != nil {
+			return err
+		}
+		return models.ErrStockTakeNotOpen
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"math"
+)
+
+// taxRule adalah kelas pajak yang dipakai saat checkout
+type taxRule struct {
+	ID          int
+	Rate        float64
+	IsInclusive bool
+	IsExempt    bool
+}
+
+// loadTaxRules mengambil semua kelas pajak aktif + ID kelas default (nil jika tidak ada)
+// Tabel tax_classes kecil, jadi cukup diambil sekali per checkout
+func loadTaxRules(q queryer) (map[int]*taxRule, *int, error) {
+	rows, err := q.Query("SELECT id, rate, is_inclusive, is_exempt, is_default FROM tax_classes WHERE is_active = TRUE")
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal mengambil kelas pajak: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make(map[int]*taxRule)
+	var defaultID *int
+	for rows.Next() {
+		var t taxRule
+		var isDefault bool
+		if err := rows.Scan(&t.ID, &t.Rate, &t.IsInclusive, &t.IsExempt, &isDefault); err != nil {
+			return nil, nil, fmt.Errorf("gagal membaca kelas pajak: %w", err)
+		}
+		rules[t.ID] = &t
+		if isDefault {
+			id := t.ID
+			defaultID = &id
+		}
+	}
+	return rules, defaultID, nil
+}
+
+// resolveTaxRule memilih kelas pajak baris: produk → kategori → default
+// Kelas yang tidak aktif dianggap tidak ada
+func resolveTaxRule(rules map[int]*taxRule, productClassID, categoryClassID sql.NullInt64, defaultID *int) *taxRule {
+	if productClassID.Valid {
+		if t, ok := rules[int(productClassID.Int64)]; ok {
+			return t
+		}
+	}
+	if categoryClassID.Valid {
+		if t, ok := rules[int(categoryClassID.Int64)]; ok {
+			return t
+		}
+	}
+	if defaultID != nil {
+		return rules[*defaultID]
+	}
+	return nil
+}
+
+// lineTax adalah hasil perhitungan pajak & service charge 1 baris
+type lineTax struct {
+	TaxableAmount       float64 // DPP (termasuk service charge yang kena pajak)
+	TaxAmount           float64 // PPN barang + PPN service charge
+	TaxIncluded         float64 // Bagian PPN yang sudah termasuk di harga (inclusive)
+	ServiceChargeAmount float64
+}
+
+// Added mengembalikan nominal yang ditambahkan ke total belanja
+// (PPN exclusive + service charge + PPN service charge)
+func (l lineTax) Added() float64 {
+	return l.TaxAmount - l.TaxIncluded + l.ServiceChargeAmount
+}
+
+// calculateLineTax menghitung pajak 1 baris dari nilai jual bersih (setelah semua diskon)
+//   - Inclusive: PPN = bersih × rate / (100 + rate), DPP = bersih - PPN
+//   - Exclusive: DPP = bersih, PPN = DPP × rate / 100 (ditambahkan ke total)
+//   - Service charge dihitung dari DPP barang dan selalu ditambahkan ke total;
+//     jika taxable, service charge ikut kena PPN dengan tarif baris yang sama
+//   - Bebas pajak / tanpa kelas: PPN = 0
+func calculateLineTax(netAmount float64, rule *taxRule, serviceCharge models.ServiceChargeSettings) lineTax {
+	var result lineTax
+	rate := 0.0
+	if rule != nil && !rule.IsExempt {
+		rate = rule.Rate
+	}
+
+	dpp := netAmount
+	if rule != nil && rule.IsInclusive && rate > 0 {
+		result.TaxIncluded = roundMoney(netAmount * rate / (100 + rate))
+		dpp = netAmount - result.TaxIncluded
+		result.TaxAmount = result.TaxIncluded
+	} else {
+		result.TaxAmount = roundMoney(dpp * rate / 100)
+	}
+	result.TaxableAmount = dpp
+
+	if serviceCharge.Percent > 0 {
+		result.ServiceChargeAmount = roundMoney(dpp * serviceCharge.Percent / 100)
+		if serviceCharge.Taxable {
+			result.TaxableAmount += result.ServiceChargeAmount
+			result.TaxAmount += roundMoney(result.ServiceChargeAmount * rate / 100)
+		}
+	}
+	result.TaxableAmount = roundMoney(result.TaxableAmount)
+
+	return result
+}
+
+// roundMoney membulatkan nominal ke 2 desimal
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
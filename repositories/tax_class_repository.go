@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+)
+
+// TaxClassRepository handles database operations for tax classes
+type TaxClassRepository struct {
+	db *sql.DB
+}
+
+// NewTaxClassRepository creates a new TaxClassRepository
+func NewTaxClassRepository(db *sql.DB) *TaxClassRepository {
+	return &TaxClassRepository{db: db}
+}
+
+const taxClassSelectColumns = `id, name, rate, is_inclusive, is_exempt, is_default, is_active, created_at, updated_at`
+
+func scanTaxClass(row rowScanner) (*models.TaxClass, error) {
+	var t models.TaxClass
+	err := row.Scan(&t.ID, &t.Name, &t.Rate, &t.IsInclusive, &t.IsExempt, &t.IsDefault, &t.IsActive, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetAll returns all tax classes
+func (r *TaxClassRepository) GetAll() ([]models.TaxClass, error) {
+	rows, err := r.db.Query(`SELECT ` + taxClassSelectColumns + ` FROM tax_classes ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil kelas pajak: %w", err)
+	}
+	defer rows.Close()
+
+	classes := []models.TaxClass{}
+	for rows.Next() {
+		t, err := scanTaxClass(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca kelas pajak: %w", err)
+		}
+		classes = append(classes, *t)
+	}
+	return classes, nil
+}
+
+// GetByID returns a tax class by ID
+func (r *TaxClassRepository) GetByID(id int) (*models.TaxClass, error) {
+	t, err := scanTaxClass(r.db.QueryRow(`SELECT `+taxClassSelectColumns+` FROM tax_classes WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("kelas pajak dengan ID %d tidak ditemukan", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Create menambahkan kelas pajak baru
+// Jika is_default = true, kelas default sebelumnya otomatis dilepas (dalam 1 transaksi)
+func (r *TaxClassRepository) Create(t *models.TaxClass) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if t.IsDefault {
+		_, err = tx.Exec("UPDATE tax_classes SET is_default = FALSE, updated_at = NOW() WHERE is_default = TRUE")
+		if err != nil {
+			return fmt.Errorf("gagal melepas kelas pajak default: %w", err)
+		}
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO tax_classes (name, rate, is_inclusive, is_exempt, is_default, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, t.Name, t.Rate, t.IsInclusive, t.IsExempt, t.IsDefault, t.IsActive).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan kelas pajak: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🧾 Kelas pajak dibuat: ID=%d, %s (%.2f%%)", t.ID, t.Name, t.Rate)
+	return nil
+}
+
+// Update mengubah kelas pajak
+// Perubahan tarif hanya berlaku untuk transaksi baru (transaksi lama menyimpan snapshot)
+func (r *TaxClassRepository) Update(id int, t *models.TaxClass) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if t.IsDefault {
+		_, err = tx.Exec("UPDATE tax_classes SET is_default = FALSE, updated_at = NOW() WHERE is_default = TRUE AND id <> $1", id)
+		if err != nil {
+			return fmt.Errorf("gagal melepas kelas pajak default: %w", err)
+		}
+	}
+
+	err = tx.QueryRow(`
+		UPDATE tax_classes
+		SET name = $1, rate = $2, is_inclusive = $3, is_exempt = $4, is_default = $5, is_active = $6, updated_at = NOW()
+		WHERE id = $7
+		RETURNING id, created_at, updated_at
+	`, t.Name, t.Rate, t.IsInclusive, t.IsExempt, t.IsDefault, t.IsActive, id).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		err = fmt.Errorf("kelas pajak dengan ID %d tidak ditemukan", id)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("gagal mengupdate kelas pajak: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Delete menghapus kelas pajak
+// Produk & kategori yang memakai kelas ini otomatis kembali ke NULL (ON DELETE SET NULL)
+func (r *TaxClassRepository) Delete(id int) error {
+	result, err := r.db.Exec("DELETE FROM tax_classes WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus kelas pajak: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("kelas pajak dengan ID %d tidak ditemukan", id)
+	}
+	return nil
+}
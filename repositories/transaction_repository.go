@@ -10,12 +10,21 @@ import (
 
 // TransactionRepository handles database operations for transactions
 type TransactionRepository struct {
-	db *sql.DB
+	db            *sql.DB
+	serviceCharge models.ServiceChargeSettings
+	cashRounding  models.CashRoundingSettings
+	loyalty       models.LoyaltySettings
+	storeLoc      *time.Location
+	costing       string
 }
 
 // NewTransactionRepository creates a new TransactionRepository
-func NewTransactionRepository(db *sql.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
+// serviceCharge & cashRounding dipakai saat checkout (Percent / Unit = 0 → nonaktif)
+// loyalty dipakai untuk poin yang didapat & dipakai saat checkout (EarnAmount = 0 → tidak ada poin baru)
+// storeLoc = timezone toko untuk jadwal hari / jam diskon
+// costing = metode harga pokok barang terjual (average / fifo / last)
+func NewTransactionRepository(db *sql.DB, serviceCharge models.ServiceChargeSettings, cashRounding models.CashRoundingSettings, loyalty models.LoyaltySettings, storeLoc *time.Location, costing string) *TransactionRepository {
+	return &TransactionRepository{db: db, serviceCharge: serviceCharge, cashRounding: cashRounding, loyalty: loyalty, storeLoc: storeLoc, costing: costing}
 }
 
 // CreateTransaction creates a new transaction with details (OPTIMIZED - batch queries)
@@ -31,305 +40,382 @@ func (r *TransactionRepository) CreateTransaction(req *models.CheckoutRequest) (
 		}
 	}()
 
-	// ─── STEP 1: Batch fetch ALL products in 1 query (bukan per-item) ───
-	productIDPlaceholders := ""
-	productIDArgs := make([]interface{}, len(req.Items))
-	for i, item := range req.Items {
-		productIDArgs[i] = item.ProductID
-		if i > 0 {
-			productIDPlaceholders += ", "
+	// ─── STEP 1: Batch fetch + LOCK ALL products in 1 query (bukan per-item) ───
+	// Qty di-SUM per produk karena 1 produk bisa muncul di beberapa baris checkout
+	requestedQty := make(map[int]int)
+	productIDArgs := make([]interface{}, 0, len(req.Items))
+	for _, item := range req.Items {
+		if _, seen := requestedQty[item.ProductID]; !seen {
+			productIDArgs = append(productIDArgs, item.ProductID)
 		}
-		productIDPlaceholders += fmt.Sprintf("$%d", i+1)
+		requestedQty[item.ProductID] += item.Quantity
 	}
 
-	productRows, err := tx.Query(
-		fmt.Sprintf("SELECT id, harga, stok, category_id, harga_beli FROM products WHERE id IN (%s)", productIDPlaceholders),
-		productIDArgs...,
-	)
+	// FOR UPDATE → baris produk di-lock sampai commit/rollback, sehingga 2 kasir
+	// yang menjual stok terakhir bersamaan akan antri (bukan sama-sama lolos validasi).
+	productMap, err := loadPricingProducts(tx, productIDArgs, true)
 	if err != nil {
-		return nil, fmt.Errorf("gagal mengambil data produk: %w", err)
+		return nil, err
 	}
 
-	type productInfo struct {
-		Price      float64
-		Stok       int
-		CategoryID sql.NullInt64
-		HargaBeli  sql.NullFloat64
-	}
-	productMap := make(map[int]*productInfo)
-	for productRows.Next() {
-		var id int
-		var p productInfo
-		if scanErr := productRows.Scan(&id, &p.Price, &p.Stok, &p.CategoryID, &p.HargaBeli); scanErr != nil {
-			productRows.Close()
-			return nil, scanErr
-		}
-		productMap[id] = &p
+	// Stok yang sedang di-reservasi held cart lain tidak boleh dijual
+	// (reservasi milik cart yang sedang di-checkout tidak dihitung)
+	reserved, err := getReservedStock(tx, productIDArgs, req.HeldCartID)
+	if err != nil {
+		return nil, err
 	}
-	productRows.Close()
 
-	// Validasi
+	// Validasi (stok sudah di-lock, jadi hasil validasi ini tetap berlaku sampai commit)
 	for _, item := range req.Items {
 		p, exists := productMap[item.ProductID]
 		if !exists {
-			return nil, fmt.Errorf("produk dengan ID %d tidak ditemukan", item.ProductID)
+			err = fmt.Errorf("produk dengan ID %d tidak ditemukan", item.ProductID)
+			return nil, err
 		}
-		if p.Stok < item.Quantity {
-			return nil, fmt.Errorf("stok produk ID %d tidak mencukupi (sisa: %d, diminta: %d)", item.ProductID, p.Stok, item.Quantity)
+		available := p.Stok - reserved[item.ProductID]
+		if available < requestedQty[item.ProductID] {
+			err = &models.InsufficientStockError{
+				ProductID:   item.ProductID,
+				ProductName: p.Name,
+				Available:   available,
+				Requested:   requestedQty[item.ProductID],
+			}
+			return nil, err
 		}
 	}
 
-	// ─── STEP 2: Batch fetch ALL active item discounts in 1 query ───
-	discountRows, err := tx.Query(`
-		SELECT id, type, value, product_id, category_id FROM discounts 
-		WHERE is_active = TRUE AND NOW() BETWEEN start_date AND end_date
-		AND (product_id IS NOT NULL OR category_id IS NOT NULL)
-		ORDER BY value DESC
-	`)
+	// ─── STEP 2: Pricing engine (sama persis dengan POST /api/checkout/preview) ───
+	// Diskon produk/kategori, promosi, diskon global (DiscountID + min order) sesuai aturan stacking,
+	// pajak & service charge. Diskon dari frontend yang tidak sama dengan hasil engine ditolak (kecuali override).
+	pricing, err := priceCart(tx, req, productMap, r.serviceCharge, r.storeLoc)
 	if err != nil {
-		return nil, fmt.Errorf("gagal mengambil data diskon: %w", err)
-	}
-
-	type discInfo struct {
-		Type  string
-		Value float64
-	}
-	productDiscounts := make(map[int]*discInfo)
-	categoryDiscounts := make(map[int]*discInfo)
-	for discountRows.Next() {
-		var id int
-		var dType string
-		var dValue float64
-		var productID, categoryID sql.NullInt64
-		if scanErr := discountRows.Scan(&id, &dType, &dValue, &productID, &categoryID); scanErr != nil {
-			discountRows.Close()
-			return nil, scanErr
-		}
-		// Jika diskon punya KEDUA product_id DAN category_id → data tidak valid, skip
-		if productID.Valid && categoryID.Valid {
-			log.Printf("⚠️ Diskon ID %d punya product_id DAN category_id sekaligus — diskip (data tidak valid)", id)
-			continue
-		}
-		if productID.Valid {
-			pid := int(productID.Int64)
-			if _, exists := productDiscounts[pid]; !exists {
-				productDiscounts[pid] = &discInfo{Type: dType, Value: dValue}
-			}
-		} else if categoryID.Valid {
-			cid := int(categoryID.Int64)
-			if _, exists := categoryDiscounts[cid]; !exists {
-				categoryDiscounts[cid] = &discInfo{Type: dType, Value: dValue}
-			}
-		}
+		return nil, err
 	}
-	discountRows.Close()
-
-	// ─── STEP 3: Process items in-memory ───
-	// Prioritas: gunakan diskon dari frontend jika ada (discount_amount > 0),
-	// fallback ke diskon dari DB jika tidak ada diskon frontend.
-	var totalAmount float64
-	var totalDiscount float64
-	details := make([]models.TransactionDetail, 0, len(req.Items))
-
-	for _, item := range req.Items {
-		p := productMap[item.ProductID]
-
-		// Harga satuan SELALU dari database — jangan izinkan frontend override
-		// untuk mencegah harga naik/turun tanpa sepengetahuan admin
-		unitPrice := p.Price
-		if item.Price > 0 && item.Price != p.Price {
-			log.Printf("⚠️ Produk ID %d: frontend kirim harga %.0f, DB harga %.0f — gunakan harga DB",
-				item.ProductID, item.Price, p.Price)
-		}
-
-		var discountAmount float64
-		var discountType string
-		var discountValue float64
-
-		if item.DiscountAmount > 0 {
-			// Gunakan diskon yang sudah dihitung frontend
-			discountAmount = item.DiscountAmount
-			discountType = item.DiscountType
-			discountValue = item.DiscountValue
-		} else {
-			// Fallback: cari diskon dari DB (per produk / kategori)
-			var itemDiscountPerUnit float64
-			disc, found := productDiscounts[item.ProductID]
-			if !found && p.CategoryID.Valid {
-				disc, _ = categoryDiscounts[int(p.CategoryID.Int64)]
-			}
-			if disc != nil {
-				if disc.Type == "PERCENTAGE" {
-					itemDiscountPerUnit = unitPrice * (disc.Value / 100)
-					discountType = "percentage"
-				} else {
-					itemDiscountPerUnit = disc.Value
-					discountType = "fixed"
-				}
-				if itemDiscountPerUnit > unitPrice {
-					itemDiscountPerUnit = unitPrice
-				}
-				discountValue = disc.Value
-				discountAmount = itemDiscountPerUnit * float64(item.Quantity)
-			}
-		}
-
-		// Subtotal = (harga × qty) - total diskon item
-		subtotal := (unitPrice * float64(item.Quantity)) - discountAmount
-		if subtotal < 0 {
-			subtotal = 0
-		}
-		totalAmount += subtotal
-		totalDiscount += discountAmount
-
-		var hargaBeliSnapshot float64
-		if p.HargaBeli.Valid {
-			hargaBeliSnapshot = p.HargaBeli.Float64
-		} else {
-			hargaBeliSnapshot = unitPrice
-		}
-
-		details = append(details, models.TransactionDetail{
-			ProductID:      item.ProductID,
-			Quantity:       item.Quantity,
-			Price:          unitPrice,
-			Subtotal:       subtotal,
-			DiscountType:   discountType,
-			DiscountValue:  discountValue,
-			DiscountAmount: discountAmount,
-			HargaBeli:      hargaBeliSnapshot,
-		})
+	details := pricing.Details
+	finalTotal := pricing.Total
+	totalDiscount := pricing.TotalDiscount()
+	usedDiscountID := pricing.DiscountID
+	var usedVoucherID *int
+	if pricing.Voucher != nil {
+		usedVoucherID = &pricing.Voucher.ID
 	}
 
-	// ─── STEP 4: Batch UPDATE stock in 1 query ───
+	// ─── STEP 3: Batch UPDATE stock in 1 query ───
+	// Conditional decrement (stok >= qty) sebagai pengaman terakhir: jika ada baris
+	// yang tidak ter-update berarti stok berubah di luar lock → batalkan transaksi
 	stockQuery := "UPDATE products SET stok = CASE "
+	stockGuard := ""
 	stockIDs := ""
-	stockArgs := make([]interface{}, 0, len(req.Items)*2)
+	stockArgs := make([]interface{}, 0, len(requestedQty)*2)
 	argIdx := 1
-	for i, item := range req.Items {
+	for i, productID := range productIDArgs {
 		stockQuery += fmt.Sprintf("WHEN id = $%d THEN stok - $%d ", argIdx, argIdx+1)
-		stockArgs = append(stockArgs, item.ProductID, item.Quantity)
 		if i > 0 {
 			stockIDs += ", "
+			stockGuard += " OR "
 		}
+		stockGuard += fmt.Sprintf("(id = $%d AND stok >= $%d)", argIdx, argIdx+1)
 		stockIDs += fmt.Sprintf("$%d", argIdx)
+		stockArgs = append(stockArgs, productID, requestedQty[productID.(int)])
 		argIdx += 2
 	}
-	stockQuery += fmt.Sprintf("END WHERE id IN (%s)", stockIDs)
-	_, err = tx.Exec(stockQuery, stockArgs...)
+	stockQuery += fmt.Sprintf("END WHERE id IN (%s) AND (%s) RETURNING id, stok", stockIDs, stockGuard)
+	stockRows, err := tx.Query(stockQuery, stockArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("gagal update stok: %w", err)
 	}
+	// Stok sesudah per produk → ledger stock_movements (dicatat setelah ID transaksi diketahui)
+	var createdByRef *int
+	if req.CreatedBy > 0 {
+		createdByRef = &req.CreatedBy
+	}
+	movements := make([]models.StockMovement, 0, len(productIDArgs))
+	for stockRows.Next() {
+		m := models.StockMovement{Type: models.StockMovementSale, CreatedBy: createdByRef}
+		if err = stockRows.Scan(&m.ProductID, &m.StockAfter); err != nil {
+			stockRows.Close()
+			return nil, fmt.Errorf("gagal update stok: %w", err)
+		}
+		m.Quantity = -requestedQty[m.ProductID]
+		m.StockBefore = m.StockAfter - m.Quantity
+		movements = append(movements, m)
+	}
+	stockRows.Close()
+	if err = stockRows.Err(); err != nil {
+		return nil, fmt.Errorf("gagal update stok: %w", err)
+	}
+	if len(movements) != len(productIDArgs) {
+		err = fmt.Errorf("gagal update stok: %w", models.ErrInsufficientStock)
+		return nil, err
+	}
 
-	// ─── STEP 5: Process Global Discount (if any) ───
-	// Catatan alur diskon:
-	// - totalAmount   = jumlah subtotal per item (sudah dikurangi diskon per-item)
-	// - totalDiscount = akumulasi diskon per-item yang sudah dihitung di STEP 3
-	// - req.DiscountAmount (root) = total diskon keseluruhan dari frontend (INFORMASI SAJA,
-	//   sudah termasuk diskon per-item → JANGAN dikurangi lagi dari totalAmount)
-	// - globalDiscountAmount = diskon tambahan dari tabel discounts (via DiscountID)
-	var usedDiscountID *int
-	var globalDiscountAmount float64
-
-	if req.DiscountID != nil {
-		// Diskon global dari tabel discounts (berdasarkan ID) — ini diskon TAMBAHAN
-		var d models.Discount
-		var isValid bool
-		err = tx.QueryRow(`
-			SELECT id, type, value, min_order_amount, (NOW() BETWEEN start_date AND end_date) as is_valid 
-			FROM discounts WHERE id = $1 AND is_active = TRUE 
-			AND product_id IS NULL AND category_id IS NULL`, *req.DiscountID).Scan(
-			&d.ID, &d.Type, &d.Value, &d.MinOrderAmount, &isValid,
-		)
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("diskon global ID %d tidak valid atau (mungkin diskon produk/kategori)", *req.DiscountID)
+	// ─── STEP 3B: Harga pokok barang terjual (COSTING_METHOD) ───
+	// Lapisan biaya dikurangi; snapshot harga_beli per baris = harga pokok rata-rata unit yang keluar
+	unitCosts := make(map[int]float64, len(movements))
+	for _, m := range movements {
+		unitCosts[m.ProductID], err = consumeCost(tx, r.costing, m.ProductID, -m.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menghitung harga pokok: %w", err)
 		}
+	}
+	for i := range details {
+		details[i].HargaBeli = unitCosts[details[i].ProductID]
+	}
+
+	// ─── STEP 4: Hitung pembayaran (split tender) ───
+	// Kembalian hanya dihitung dari porsi cash; non-tunai harus pas.
+	// Porsi cash dibulatkan sesuai CASH_ROUNDING_UNIT, selisihnya disimpan di rounding_amount
+	payments, paymentAmount, changeAmount, roundingAmount, err := buildPayments(req, finalTotal, r.cashRounding)
+	if err != nil {
+		return nil, err
+	}
+
+	// ─── STEP 5: Insert transaction header ───
+	// discount_amount = total diskon hasil pricing engine (diskon item + diskon global), sama dengan
+	// yang dikembalikan di response. req.DiscountAmount dari frontend (override admin) tidak disimpan
+	// karena total_amount juga dihitung engine — keduanya harus berasal dari hasil yang sama.
+
+	// Hubungkan ke shift kasir yang sedang terbuka (NULL jika kasir belum buka shift)
+	shiftID, err := getOpenShiftID(tx, req.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pelanggan opsional, tapi jika diisi harus terdaftar & aktif
+	if req.CustomerID != nil {
+		err = checkCustomerActive(tx, *req.CustomerID)
 		if err != nil {
 			return nil, err
 		}
-		if !isValid {
-			return nil, fmt.Errorf("diskon global sudah kedaluwarsa")
-		}
-		if totalAmount < d.MinOrderAmount {
-			return nil, fmt.Errorf("min order %.0f tidak terpenuhi", d.MinOrderAmount)
-		}
-		if d.Type == "PERCENTAGE" {
-			globalDiscountAmount = totalAmount * (d.Value / 100)
-		} else {
-			globalDiscountAmount = d.Value
-		}
-		if globalDiscountAmount > totalAmount {
-			globalDiscountAmount = totalAmount
-		}
-		usedDiscountID = req.DiscountID
 	}
-	// req.DiscountAmount TIDAK dikurangi lagi — diskon per-item sudah masuk
-	// ke totalAmount via pengurangan subtotal di STEP 3.
 
-	// finalTotal = total setelah semua diskon
-	// Jika ada DiscountID (diskon global tambahan), kurangi dari totalAmount.
-	// Jika tidak, totalAmount sudah bersih.
-	finalTotal := totalAmount - globalDiscountAmount
-	if finalTotal < 0 {
-		finalTotal = 0
+	// Tender kasbon: wajib pelanggan & saldo kasbon setelah transaksi tidak boleh melebihi limit
+	creditAmount := sumPaymentMethod(payments, models.PaymentMethodCredit)
+	if creditAmount > 0 {
+		if req.CustomerID == nil {
+			err = models.ErrCreditRequiresCustomer
+			return nil, err
+		}
+		err = checkCreditLimit(tx, *req.CustomerID, creditAmount)
+		if err != nil {
+			return nil, err
+		}
 	}
-	totalDiscount += globalDiscountAmount
 
-	// change_amount = uang bayar - total yang harus dibayar (setelah SEMUA diskon)
-	paymentAmount := req.PaymentAmount
-	changeAmount := 0.0
-	if paymentAmount > 0 {
-		changeAmount = paymentAmount - finalTotal
-		if changeAmount < 0 {
-			changeAmount = 0
+	// Tender poin: wajib pelanggan, nominal harus kelipatan nilai 1 poin & saldo cukup.
+	// Baris pelanggan di-lock agar 2 transaksi tidak memakai poin yang sama bersamaan.
+	pointsAmount := sumPaymentMethod(payments, models.PaymentMethodPoints)
+	var pointsRedeemed int
+	if pointsAmount > 0 {
+		if req.CustomerID == nil {
+			err = models.ErrPointsRequireCustomer
+			return nil, err
+		}
+		var ok bool
+		pointsRedeemed, ok = r.loyalty.PointsForAmount(pointsAmount)
+		if !ok {
+			err = fmt.Errorf("%w (1 poin = Rp %.0f)", models.ErrInvalidPointsAmount, r.loyalty.PointValue)
+			return nil, err
+		}
+		_, err = tx.Exec("SELECT id FROM customers WHERE id = $1 FOR UPDATE", *req.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		var balance int
+		balance, err = expireLoyaltyPoints(tx, *req.CustomerID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if balance < pointsRedeemed {
+			err = fmt.Errorf("%w (saldo %d poin, dibutuhkan %d poin)", models.ErrInsufficientPoints, balance, pointsRedeemed)
+			return nil, err
 		}
 	}
 
-	// ─── STEP 6: Insert transaction header ───
-	// Gunakan req.DiscountAmount sebagai discount_amount yang disimpan ke DB
-	// (total diskon keseluruhan dari frontend, sudah include diskon per-item).
-	// Jika frontend tidak mengirim req.DiscountAmount, fallback ke totalDiscount internal.
-	savedDiscountAmount := totalDiscount
-	if req.DiscountAmount > 0 {
-		savedDiscountAmount = req.DiscountAmount
+	// Poin dihitung dari total akhir transaksi (hanya untuk transaksi dengan pelanggan)
+	var pointsEarned int
+	if req.CustomerID != nil {
+		pointsEarned = r.loyalty.EarnPoints(finalTotal)
 	}
 
 	var transactionID int
 	err = tx.QueryRow(
-		"INSERT INTO transactions (total_amount, discount_id, discount_amount, payment_amount, change_amount, created_by) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
-		finalTotal, usedDiscountID, savedDiscountAmount, paymentAmount, changeAmount, req.CreatedBy,
+		`INSERT INTO transactions (total_amount, discount_id, discount_amount, payment_amount, change_amount, created_by, shift_id,
+			tax_amount, tax_included_amount, service_charge_amount, rounding_amount, customer_id, voucher_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id`,
+		finalTotal, usedDiscountID, totalDiscount, paymentAmount, changeAmount, req.CreatedBy, shiftID,
+		pricing.TaxAmount, pricing.TaxIncludedAmount, pricing.ServiceChargeAmount, roundingAmount, req.CustomerID, usedVoucherID,
 	).Scan(&transactionID)
 	if err != nil {
 		return nil, err
 	}
 
-	// ─── STEP 7: Batch insert details (termasuk discount per item) ───
+	// ─── STEP 5B: Tandai approval token override sebagai terpakai (sekali pakai) ───
+	err = consumeOverrideTokens(tx, req, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ─── STEP 6: Batch insert details (termasuk discount & snapshot pajak per item) ───
 	if len(details) > 0 {
-		query := "INSERT INTO transaction_details (transaction_id, product_id, quantity, price, subtotal, harga_beli, discount_type, discount_value, discount_amount) VALUES "
-		values := make([]interface{}, 0, len(details)*9)
+		query := `INSERT INTO transaction_details (transaction_id, product_id, quantity, price, subtotal, harga_beli, discount_type, discount_value, discount_amount,
+			tax_class_id, tax_rate, tax_inclusive, taxable_amount, tax_amount, service_charge_amount,
+			original_price, override_approved_by, override_reason, promotion_id, promotion_type, promotion_amount,
+			order_discount_amount) VALUES `
+		values := make([]interface{}, 0, len(details)*22)
 		for i, detail := range details {
 			if i > 0 {
 				query += ", "
 			}
-			query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
-				i*9+1, i*9+2, i*9+3, i*9+4, i*9+5, i*9+6, i*9+7, i*9+8, i*9+9)
+			query += "("
+			for j := 1; j <= 22; j++ {
+				if j > 1 {
+					query += ", "
+				}
+				query += fmt.Sprintf("$%d", i*22+j)
+			}
+			query += ")"
 			// Simpan NULL jika discount_type kosong
 			var discType interface{}
 			if detail.DiscountType != "" {
 				discType = detail.DiscountType
 			}
+			var promoType interface{}
+			if detail.PromotionType != "" {
+				promoType = detail.PromotionType
+			}
 			values = append(values,
 				transactionID, detail.ProductID, detail.Quantity, detail.Price, detail.Subtotal,
 				detail.HargaBeli, discType, detail.DiscountValue, detail.DiscountAmount,
+				detail.TaxClassID, detail.TaxRate, detail.TaxInclusive, detail.TaxableAmount, detail.TaxAmount, detail.ServiceChargeAmount,
+				detail.OriginalPrice, detail.OverrideApprovedBy, detail.OverrideReason,
+				detail.PromotionID, promoType, detail.PromotionAmount,
+				detail.OrderDiscountAmount,
 			)
 		}
+		// ID detail (urutan sama dengan VALUES) dibutuhkan audit transaction_discounts
+		var rows *sql.Rows
+		rows, err = tx.Query(query+" RETURNING id", values...)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; rows.Next(); i++ {
+			if err = rows.Scan(&details[i].ID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	// ─── STEP 6B: Batch insert rincian pembayaran ───
+	if len(payments) > 0 {
+		query := "INSERT INTO transaction_payments (transaction_id, method, amount, tendered_amount, reference) VALUES "
+		values := make([]interface{}, 0, len(payments)*5)
+		for i, p := range payments {
+			if i > 0 {
+				query += ", "
+			}
+			query += fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", i*5+1, i*5+2, i*5+3, i*5+4, i*5+5)
+			values = append(values, transactionID, p.Method, p.Amount, p.TenderedAmount, p.Reference)
+		}
 		_, err = tx.Exec(query, values...)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menyimpan pembayaran: %w", err)
+		}
+		for i := range payments {
+			payments[i].TransactionID = transactionID
+		}
+	}
+
+	// ─── STEP 6C: Hapus held cart yang di-checkout (reservasi ikut terhapus) ───
+	// Row lock dari DELETE mencegah 1 cart di-checkout 2x bersamaan
+	if req.HeldCartID != nil {
+		var cartResult sql.Result
+		cartResult, err = tx.Exec("DELETE FROM held_carts WHERE id = $1", *req.HeldCartID)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menghapus keranjang: %w", err)
+		}
+		var cartRows int64
+		cartRows, err = cartResult.RowsAffected()
 		if err != nil {
 			return nil, err
 		}
+		if cartRows == 0 {
+			err = fmt.Errorf("keranjang dengan ID %d tidak ditemukan (mungkin sudah di-checkout)", *req.HeldCartID)
+			return nil, err
+		}
 	}
 
-	// ─── STEP 8: Commit ───
+	// ─── STEP 6D: Catat kasbon di buku besar piutang pelanggan ───
+	if creditAmount > 0 {
+		err = insertCreditEntry(tx, &models.CreditLedgerEntry{
+			CustomerID:    *req.CustomerID,
+			Type:          models.CreditEntryCharge,
+			Amount:        creditAmount,
+			TransactionID: &transactionID,
+			CreatedBy:     &req.CreatedBy,
+			ShiftID:       shiftID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// ─── STEP 6E: Catat poin loyalitas (dipakai & didapat) ───
+	if pointsRedeemed > 0 {
+		err = insertPointEntry(tx, &models.PointLedgerEntry{
+			CustomerID:    *req.CustomerID,
+			Type:          models.PointEntryRedeem,
+			Points:        -pointsRedeemed,
+			TransactionID: &transactionID,
+			CreatedBy:     &req.CreatedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if pointsEarned > 0 {
+		err = insertPointEntry(tx, &models.PointLedgerEntry{
+			CustomerID:    *req.CustomerID,
+			Type:          models.PointEntryEarn,
+			Points:        pointsEarned,
+			TransactionID: &transactionID,
+			ExpiresAt:     r.loyalty.ExpiresAt(time.Now()),
+			CreatedBy:     &req.CreatedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// ─── STEP 6F: Pakai kuota voucher (atomik, ditolak jika kuota habis saat ini) ───
+	if pricing.Voucher != nil {
+		err = redeemVoucher(tx, pricing.Voucher, req.CustomerID, transactionID, pricing.GlobalDiscount, req.CreatedBy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// ─── STEP 6G: Audit kombinasi diskon per baris ───
+	err = insertTransactionDiscounts(tx, transactionID, details, pricing.Applied)
+	if err != nil {
+		return nil, err
+	}
+
+	// ─── STEP 6H: Ledger pergerakan stok (penjualan) ───
+	for i := range movements {
+		movements[i].ReferenceID = &transactionID
+	}
+	err = insertStockMovements(tx, movements)
+	if err != nil {
+		return nil, err
+	}
+
+	// ─── STEP 7: Commit ───
 	err = tx.Commit()
 	if err != nil {
 		return nil, err
@@ -343,15 +429,198 @@ func (r *TransactionRepository) CreateTransaction(req *models.CheckoutRequest) (
 		PaymentAmount:  paymentAmount,
 		ChangeAmount:   changeAmount,
 		CreatedBy:      &req.CreatedBy,
+		Payments:       payments,
+		ShiftID:        shiftID,
+
+		TaxAmount:           pricing.TaxAmount,
+		TaxIncludedAmount:   pricing.TaxIncludedAmount,
+		ServiceChargeAmount: pricing.ServiceChargeAmount,
+		RoundingAmount:      roundingAmount,
+		CustomerID:          req.CustomerID,
+		PointsEarned:        pointsEarned,
+		PointsRedeemed:      pointsRedeemed,
+		VoucherID:           usedVoucherID,
 	}, nil
 }
 
+// consumeOverrideTokens mencatat jti setiap approval token yang dipakai checkout.
+// jti adalah PRIMARY KEY → token yang sudah pernah dipakai (atau dipakai checkout lain
+// secara bersamaan) ditolak dengan ErrApprovalTokenUsed dan seluruh transaksi di-rollback.
+func consumeOverrideTokens(tx *sql.Tx, req *models.CheckoutRequest, transactionID int) error {
+	for _, item := range req.Items {
+		if item.OverrideTokenID == "" {
+			continue
+		}
+		result, err := tx.Exec(`
+			INSERT INTO override_token_uses (jti, approved_by, cashier_id, product_id, transaction_id)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (jti) DO NOTHING
+		`, item.OverrideTokenID, item.OverrideApprovedBy, req.CreatedBy, item.ProductID, transactionID)
+		if err != nil {
+			return fmt.Errorf("gagal mencatat pemakaian approval token: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return fmt.Errorf("produk ID %d: %w", item.ProductID, models.ErrApprovalTokenUsed)
+		}
+	}
+	return nil
+}
+
+// PreviewCheckout menghitung harga keranjang tanpa menyimpan apapun
+// Memakai pricing engine yang sama dengan CreateTransaction (tanpa lock & tanpa cek stok),
+// sehingga frontend tidak perlu menghitung ulang diskon sendiri.
+func (r *TransactionRepository) PreviewCheckout(req *models.CheckoutRequest) (*models.CheckoutPreview, error) {
+	productIDArgs := make([]interface{}, 0, len(req.Items))
+	seen := make(map[int]bool)
+	for _, item := range req.Items {
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			productIDArgs = append(productIDArgs, item.ProductID)
+		}
+	}
+
+	products, err := loadPricingProducts(r.db, productIDArgs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	pricing, err := priceCart(r.db, req, products, r.serviceCharge, r.storeLoc)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.CheckoutPreview{
+		Items:               make([]models.PricingLine, 0, len(pricing.Details)),
+		AppliedDiscounts:    make([]models.TransactionDiscount, 0, len(pricing.Applied)),
+		GrossAmount:         roundMoney(pricing.GrossAmount),
+		ItemDiscount:        roundMoney(pricing.ItemDiscount),
+		PromotionDiscount:   pricing.PromotionDiscount,
+		Subtotal:            roundMoney(pricing.NetAmount),
+		DiscountID:          pricing.DiscountID,
+		GlobalDiscount:      roundMoney(pricing.GlobalDiscount),
+		TotalDiscount:       roundMoney(pricing.TotalDiscount()),
+		TaxAmount:           pricing.TaxAmount,
+		TaxIncludedAmount:   pricing.TaxIncludedAmount,
+		ServiceChargeAmount: pricing.ServiceChargeAmount,
+		TotalAmount:         pricing.Total,
+		CashTotal:           r.cashRounding.Round(pricing.Total),
+	}
+	if pricing.Voucher != nil {
+		preview.VoucherID = &pricing.Voucher.ID
+		preview.VoucherCode = pricing.Voucher.Code
+	}
+	for _, d := range pricing.Details {
+		preview.Items = append(preview.Items, models.PricingLine{
+			ProductID:           d.ProductID,
+			ProductName:         d.ProductName,
+			Quantity:            d.Quantity,
+			Price:               d.Price,
+			DiscountType:        d.DiscountType,
+			DiscountValue:       d.DiscountValue,
+			DiscountAmount:      d.DiscountAmount,
+			PromotionID:         d.PromotionID,
+			PromotionType:       d.PromotionType,
+			PromotionName:       d.PromotionName,
+			PromotionAmount:     d.PromotionAmount,
+			OrderDiscountAmount: d.OrderDiscountAmount,
+			Subtotal:            d.Subtotal,
+			TaxClassID:          d.TaxClassID,
+			TaxRate:             d.TaxRate,
+			TaxInclusive:        d.TaxInclusive,
+			TaxAmount:           d.TaxAmount,
+			ServiceChargeAmount: d.ServiceChargeAmount,
+		})
+	}
+	for _, a := range pricing.Applied {
+		preview.AppliedDiscounts = append(preview.AppliedDiscounts, a.TransactionDiscount)
+	}
+
+	return preview, nil
+}
+
+// buildPayments menyusun baris transaction_payments dari request checkout
+// - Jika payments kosong tapi payment_amount diisi → dianggap 1 pembayaran cash (kompatibel frontend lama)
+// - Non-tunai dipakai penuh dan tidak boleh melebihi total belanja (tidak ada kembalian non-tunai)
+// - Sisa tagihan cash dibulatkan sesuai setting (non-tunai tidak dibulatkan)
+// - Kembalian = cash diserahkan - sisa tagihan cash setelah pembulatan
+// Return: rincian pembayaran, total uang diserahkan, kembalian, selisih pembulatan
+func buildPayments(req *models.CheckoutRequest, finalTotal float64, cashRounding models.CashRoundingSettings) ([]models.TransactionPayment, float64, float64, float64, error) {
+	tenders := req.Payments
+	if len(tenders) == 0 {
+		if req.PaymentAmount <= 0 {
+			// Tidak ada info pembayaran sama sekali (perilaku lama)
+			return nil, 0, 0, 0, nil
+		}
+		tenders = []models.CheckoutPayment{{Method: models.PaymentMethodCash, Amount: req.PaymentAmount}}
+	}
+
+	var cashTendered, nonCashTotal float64
+	for _, p := range tenders {
+		if p.Method == models.PaymentMethodCash {
+			cashTendered += p.Amount
+		} else {
+			nonCashTotal += p.Amount
+		}
+	}
+
+	if nonCashTotal > finalTotal {
+		return nil, 0, 0, 0, fmt.Errorf("%w (non-tunai: %.0f, total: %.0f)", models.ErrNonCashOverpayment, nonCashTotal, finalTotal)
+	}
+	cashDue := finalTotal - nonCashTotal
+
+	// Pembulatan hanya untuk sisa tagihan yang dibayar tunai
+	var roundingAmount float64
+	if cashDue > 0 {
+		roundedCashDue := cashRounding.Round(cashDue)
+		roundingAmount = roundMoney(roundedCashDue - cashDue)
+		cashDue = roundedCashDue
+	}
+	if cashTendered < cashDue {
+		return nil, 0, 0, 0, fmt.Errorf("%w (dibayar: %.0f, total: %.0f)", models.ErrPaymentInsufficient, cashTendered+nonCashTotal, nonCashTotal+cashDue)
+	}
+	changeAmount := roundMoney(cashTendered - cashDue)
+
+	// Kembalian dikurangkan dari baris cash (urut sesuai request) sehingga
+	// amount = nominal yang benar-benar masuk laci
+	remainingChange := changeAmount
+	payments := make([]models.TransactionPayment, 0, len(tenders))
+	for _, p := range tenders {
+		applied := p.Amount
+		if p.Method == models.PaymentMethodCash && remainingChange > 0 {
+			deduct := remainingChange
+			if deduct > applied {
+				deduct = applied
+			}
+			applied -= deduct
+			remainingChange -= deduct
+		}
+		var reference *string
+		if p.Reference != "" {
+			ref := p.Reference
+			reference = &ref
+		}
+		payments = append(payments, models.TransactionPayment{
+			Method:         p.Method,
+			Amount:         applied,
+			TenderedAmount: p.Amount,
+			Reference:      reference,
+		})
+	}
+
+	return payments, cashTendered + nonCashTotal, changeAmount, roundingAmount, nil
+}
+
 // GetAll retrieves all transactions ordered by date descending
 // Fungsi ini mengambil semua data transaksi untuk history, termasuk profit per transaksi
 func (r *TransactionRepository) GetAll(userID *int) ([]models.Transaction, error) {
 	// Profit = (total_amount - discount_amount) - HPP
 	// total_amount = total setelah diskon item
 	// discount_amount = diskon transaksi (terpisah, perlu dikurangi)
+	// PPN (tax_amount) adalah titipan pajak, bukan keuntungan → dikurangkan
 	// Subquery HPP per transaksi untuk hindari duplikasi
 	query := `
 		SELECT 
@@ -363,9 +632,16 @@ func (r *TransactionRepository) GetAll(userID *int) ([]models.Transaction, error
 			COALESCE(t.change_amount, 0) as change_amount,
 			t.created_at,
 			COALESCE(hpp.total_qty, 0) as total_items,
-			t.total_amount - COALESCE(hpp.total_hpp, 0) as profit,
+			t.total_amount - COALESCE(t.tax_amount, 0) - COALESCE(hpp.total_hpp, 0) as profit,
 			t.created_by,
-			u.username
+			u.username,
+			t.voided_at,
+			t.voided_by,
+			t.void_reason,
+			COALESCE(t.tax_amount, 0) as tax_amount,
+			COALESCE(t.service_charge_amount, 0) as service_charge_amount,
+			COALESCE(t.rounding_amount, 0) as rounding_amount,
+			t.customer_id
 		FROM transactions t
 		LEFT JOIN (
 			SELECT 
@@ -400,7 +676,7 @@ func (r *TransactionRepository) GetAll(userID *int) ([]models.Transaction, error
 		var createdBy sql.NullInt64
 		var username sql.NullString
 
-		err := rows.Scan(&t.ID, &t.TotalAmount, &discountID, &t.DiscountAmount, &t.PaymentAmount, &t.ChangeAmount, &t.CreatedAt, &t.TotalItems, &t.Profit, &createdBy, &username)
+		err := rows.Scan(&t.ID, &t.TotalAmount, &discountID, &t.DiscountAmount, &t.PaymentAmount, &t.ChangeAmount, &t.CreatedAt, &t.TotalItems, &t.Profit, &createdBy, &username, &t.VoidedAt, &t.VoidedBy, &t.VoidReason, &t.TaxAmount, &t.ServiceChargeAmount, &t.RoundingAmount, &t.CustomerID)
 		if err != nil {
 			return nil, err
 		}
@@ -436,9 +712,16 @@ func (r *TransactionRepository) GetByDateRange(startDate, endDate time.Time, use
 			COALESCE(t.change_amount, 0) as change_amount,
 			t.created_at,
 			COALESCE(hpp.total_qty, 0) as total_items,
-			t.total_amount - COALESCE(hpp.total_hpp, 0) as profit,
+			t.total_amount - COALESCE(t.tax_amount, 0) - COALESCE(hpp.total_hpp, 0) as profit,
 			t.created_by,
-			u.username
+			u.username,
+			t.voided_at,
+			t.voided_by,
+			t.void_reason,
+			COALESCE(t.tax_amount, 0) as tax_amount,
+			COALESCE(t.service_charge_amount, 0) as service_charge_amount,
+			COALESCE(t.rounding_amount, 0) as rounding_amount,
+			t.customer_id
 		FROM transactions t
 		LEFT JOIN (
 			SELECT 
@@ -475,7 +758,7 @@ func (r *TransactionRepository) GetByDateRange(startDate, endDate time.Time, use
 		var createdBy sql.NullInt64
 		var username sql.NullString
 
-		err := rows.Scan(&t.ID, &t.TotalAmount, &discountID, &t.DiscountAmount, &t.PaymentAmount, &t.ChangeAmount, &t.CreatedAt, &t.TotalItems, &t.Profit, &createdBy, &username)
+		err := rows.Scan(&t.ID, &t.TotalAmount, &discountID, &t.DiscountAmount, &t.PaymentAmount, &t.ChangeAmount, &t.CreatedAt, &t.TotalItems, &t.Profit, &createdBy, &username, &t.VoidedAt, &t.VoidedBy, &t.VoidReason, &t.TaxAmount, &t.ServiceChargeAmount, &t.RoundingAmount, &t.CustomerID)
 		if err != nil {
 			return nil, err
 		}
@@ -509,9 +792,20 @@ func (r *TransactionRepository) GetByID(id int) (*models.TransactionWithItems, e
 			COALESCE(t.change_amount, 0) as change_amount,
 			t.created_at,
 			COALESCE(hpp.total_qty, 0) as total_items,
-			t.total_amount - COALESCE(hpp.total_hpp, 0) as profit,
+			t.total_amount - COALESCE(t.tax_amount, 0) - COALESCE(hpp.total_hpp, 0) as profit,
 			t.created_by,
-			u.username
+			u.username,
+			t.voided_at,
+			t.voided_by,
+			t.void_reason,
+			COALESCE(t.print_count, 0) as print_count,
+			COALESCE(t.tax_amount, 0) as tax_amount,
+			COALESCE(t.tax_included_amount, 0) as tax_included_amount,
+			COALESCE(t.service_charge_amount, 0) as service_charge_amount,
+			COALESCE(t.rounding_amount, 0) as rounding_amount,
+			t.customer_id,
+			COALESCE(c.name, '') as customer_name,
+			COALESCE(v.code, '') as voucher_code
 		FROM transactions t
 		LEFT JOIN (
 			SELECT 
@@ -522,6 +816,8 @@ func (r *TransactionRepository) GetByID(id int) (*models.TransactionWithItems, e
 			GROUP BY td.transaction_id
 		) hpp ON hpp.transaction_id = t.id
 		LEFT JOIN users u ON t.created_by = u.id
+		LEFT JOIN customers c ON t.customer_id = c.id
+		LEFT JOIN vouchers v ON t.voucher_id = v.id
 		WHERE t.id = $1
 	`
 
@@ -540,6 +836,17 @@ func (r *TransactionRepository) GetByID(id int) (*models.TransactionWithItems, e
 		&result.Profit,
 		&createdBy,
 		&username,
+		&result.VoidedAt,
+		&result.VoidedBy,
+		&result.VoidReason,
+		&result.PrintCount,
+		&result.TaxAmount,
+		&result.TaxIncludedAmount,
+		&result.ServiceChargeAmount,
+		&result.RoundingAmount,
+		&result.CustomerID,
+		&result.CustomerName,
+		&result.VoucherCode,
 	)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("transaksi dengan ID %d tidak ditemukan", id)
@@ -566,9 +873,24 @@ func (r *TransactionRepository) GetByID(id int) (*models.TransactionWithItems, e
 			td.subtotal,
 			COALESCE(td.discount_type, '') as discount_type,
 			COALESCE(td.discount_value, 0) as discount_value,
-			COALESCE(td.discount_amount, 0) as discount_amount
+			COALESCE(td.discount_amount, 0) as discount_amount,
+			td.tax_class_id,
+			COALESCE(td.tax_rate, 0) as tax_rate,
+			COALESCE(td.tax_inclusive, FALSE) as tax_inclusive,
+			COALESCE(td.taxable_amount, 0) as taxable_amount,
+			COALESCE(td.tax_amount, 0) as tax_amount,
+			COALESCE(td.service_charge_amount, 0) as service_charge_amount,
+			td.original_price,
+			td.override_approved_by,
+			td.override_reason,
+			td.promotion_id,
+			COALESCE(td.promotion_type, '') as promotion_type,
+			COALESCE(pr.name, '') as promotion_name,
+			COALESCE(td.promotion_amount, 0) as promotion_amount,
+			COALESCE(td.order_discount_amount, 0) as order_discount_amount
 		FROM transaction_details td
 		LEFT JOIN products p ON td.product_id = p.id
+		LEFT JOIN discounts pr ON td.promotion_id = pr.id
 		WHERE td.transaction_id = $1
 		ORDER BY td.id
 	`
@@ -586,6 +908,11 @@ func (r *TransactionRepository) GetByID(id int) (*models.TransactionWithItems, e
 			&item.ID, &item.ProductID, &item.ProductName,
 			&item.Quantity, &item.Price, &item.Subtotal,
 			&item.DiscountType, &item.DiscountValue, &item.DiscountAmount,
+			&item.TaxClassID, &item.TaxRate, &item.TaxInclusive,
+			&item.TaxableAmount, &item.TaxAmount, &item.ServiceChargeAmount,
+			&item.OriginalPrice, &item.OverrideApprovedBy, &item.OverrideReason,
+			&item.PromotionID, &item.PromotionType, &item.PromotionName, &item.PromotionAmount,
+			&item.OrderDiscountAmount,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("gagal membaca detail item: %w", err)
@@ -598,5 +925,293 @@ func (r *TransactionRepository) GetByID(id int) (*models.TransactionWithItems, e
 	}
 	result.Items = items
 
+	payments, err := r.getPayments(id)
+	if err != nil {
+		return nil, err
+	}
+	result.Payments = payments
+
+	discounts, err := r.getDiscounts(id)
+	if err != nil {
+		return nil, err
+	}
+	result.Discounts = discounts
+
 	return &result, nil
 }
+
+// IncrementPrintCount menambah counter cetak struk dan mengembalikan nilai terbarunya
+func (r *TransactionRepository) IncrementPrintCount(id int) (int, error) {
+	var printCount int
+	err := r.db.QueryRow(
+		"UPDATE transactions SET print_count = COALESCE(print_count, 0) + 1 WHERE id = $1 RETURNING print_count",
+		id,
+	).Scan(&printCount)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("transaksi dengan ID %d tidak ditemukan", id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("gagal mengupdate counter cetak struk: %w", err)
+	}
+	return printCount, nil
+}
+
+// getPayments mengambil rincian pembayaran 1 transaksi
+// Transaksi lama (sebelum split tender) tidak punya baris → list kosong
+func (r *TransactionRepository) getPayments(transactionID int) ([]models.TransactionPayment, error) {
+	rows, err := r.db.Query(`
+		SELECT id, transaction_id, method, amount, tendered_amount, reference, created_at
+		FROM transaction_payments
+		WHERE transaction_id = $1
+		ORDER BY id
+	`, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data pembayaran: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []models.TransactionPayment{}
+	for rows.Next() {
+		var p models.TransactionPayment
+		if err := rows.Scan(&p.ID, &p.TransactionID, &p.Method, &p.Amount, &p.TenderedAmount, &p.Reference, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("gagal membaca data pembayaran: %w", err)
+		}
+		payments = append(payments, p)
+	}
+
+	return payments, nil
+}
+
+// getDiscounts mengambil audit kombinasi diskon 1 transaksi (urut baris)
+// Transaksi lama (sebelum aturan stacking) tidak punya baris → list kosong
+func (r *TransactionRepository) getDiscounts(transactionID int) ([]models.TransactionDiscount, error) {
+	rows, err := r.db.Query(`
+		SELECT tdc.id, tdc.transaction_detail_id, COALESCE(td.product_id, 0), tdc.discount_id, tdc.voucher_id,
+			tdc.level, tdc.name, tdc.stacking_mode, tdc.priority, tdc.amount, tdc.capped
+		FROM transaction_discounts tdc
+		LEFT JOIN transaction_details td ON tdc.transaction_detail_id = td.id
+		WHERE tdc.transaction_id = $1
+		ORDER BY tdc.transaction_detail_id, tdc.id
+	`, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data diskon transaksi: %w", err)
+	}
+	defer rows.Close()
+
+	discounts := []models.TransactionDiscount{}
+	for rows.Next() {
+		var d models.TransactionDiscount
+		if err := rows.Scan(&d.ID, &d.TransactionDetailID, &d.ProductID, &d.DiscountID, &d.VoucherID,
+			&d.Level, &d.Name, &d.StackingMode, &d.Priority, &d.Amount, &d.Capped); err != nil {
+			return nil, fmt.Errorf("gagal membaca data diskon transaksi: %w", err)
+		}
+		discounts = append(discounts, d)
+	}
+	return discounts, nil
+}
+
+// VoidTransaction membatalkan transaksi dan mengembalikan stok semua item
+// Semua dalam 1 database transaction (atomic):
+// - Lock header transaksi (FOR UPDATE) agar tidak bisa di-void 2x bersamaan
+// - Kembalikan quantity transaction_details ke products.stok (dicatat di stock_movements)
+// - Hapus kasbon transaksi dari piutang pelanggan (baris void di buku besar)
+// - Tarik poin yang didapat & kembalikan poin yang dipakai (buku besar poin)
+// - Kembalikan kuota voucher yang dipakai
+// - Tandai transaksi dengan voided_at, voided_by, dan void_reason
+func (r *TransactionRepository) VoidTransaction(id int, reason string, voidedBy int) (*models.Transaction, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// ─── STEP 1: Lock header transaksi ───
+	var voidedAt sql.NullTime
+	err = tx.QueryRow("SELECT voided_at FROM transactions WHERE id = $1 FOR UPDATE", id).Scan(&voidedAt)
+	if err == sql.ErrNoRows {
+		err = fmt.Errorf("transaksi dengan ID %d tidak ditemukan", id)
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil data transaksi: %w", err)
+	}
+	if voidedAt.Valid {
+		err = models.ErrTransactionVoided
+		return nil, err
+	}
+
+	// Transaksi yang sudah diretur sebagian tidak boleh di-void
+	// (stok & refund retur sudah tercatat, void penuh akan double-count)
+	var hasReturns bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM sales_returns WHERE transaction_id = $1)", id).Scan(&hasReturns)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengecek retur transaksi: %w", err)
+	}
+	if hasReturns {
+		err = models.ErrTransactionHasReturns
+		return nil, err
+	}
+
+	// ─── STEP 2: Kembalikan stok (batch, 1 query) + ledger pergerakan stok ───
+	// Quantity di-SUM per produk karena 1 produk bisa muncul di beberapa baris detail.
+	// Barang kembali sebagai lapisan biaya dengan harga pokok saat terjual.
+	stockRows, err := tx.Query(`
+		UPDATE products p
+		SET stok = p.stok + d.qty
+		FROM (
+			SELECT product_id, SUM(quantity) AS qty, SUM(COALESCE(harga_beli, 0) * quantity) AS cost
+			FROM transaction_details
+			WHERE transaction_id = $1
+			GROUP BY product_id
+		) d
+		WHERE p.id = d.product_id
+		RETURNING p.id, p.stok, d.qty, d.cost
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengembalikan stok: %w", err)
+	}
+	var movements []models.StockMovement
+	var costs []float64
+	for stockRows.Next() {
+		m := models.StockMovement{Type: models.StockMovementVoid, ReferenceID: &id, CreatedBy: &voidedBy, Note: &reason}
+		var cost float64
+		if err = stockRows.Scan(&m.ProductID, &m.StockAfter, &m.Quantity, &cost); err != nil {
+			stockRows.Close()
+			return nil, fmt.Errorf("gagal mengembalikan stok: %w", err)
+		}
+		m.StockBefore = m.StockAfter - m.Quantity
+		movements = append(movements, m)
+		costs = append(costs, cost)
+	}
+	stockRows.Close()
+	if err = stockRows.Err(); err != nil {
+		return nil, fmt.Errorf("gagal mengembalikan stok: %w", err)
+	}
+	err = insertStockMovements(tx, movements)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range movements {
+		if m.Quantity <= 0 {
+			continue
+		}
+		err = receiveCost(tx, r.costing, m, roundMoney(costs[i]/float64(m.Quantity)))
+		if err != nil {
+			return nil, fmt.Errorf("gagal memperbarui harga pokok: %w", err)
+		}
+	}
+
+	// ─── STEP 2B: Batalkan kasbon transaksi ini (jika dibayar dengan tender credit) ───
+	// Transaksi yang sudah diretur tidak bisa di-void, jadi kasbonnya masih utuh
+	var creditCustomerID sql.NullInt64
+	var creditAmount float64
+	err = tx.QueryRow(`
+		SELECT MAX(customer_id), COALESCE(SUM(amount), 0)
+		FROM customer_credit_ledger
+		WHERE transaction_id = $1 AND type = 'charge'
+	`, id).Scan(&creditCustomerID, &creditAmount)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil kasbon transaksi: %w", err)
+	}
+	if creditCustomerID.Valid && creditAmount > 0 {
+		err = insertCreditEntry(tx, &models.CreditLedgerEntry{
+			CustomerID:    int(creditCustomerID.Int64),
+			Type:          models.CreditEntryVoid,
+			Amount:        creditAmount,
+			TransactionID: &id,
+			Note:          &reason,
+			CreatedBy:     &voidedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// ─── STEP 2C: Tarik poin yang didapat & kembalikan poin yang dipakai ───
+	// Poin yang sudah terpakai untuk transaksi lain tetap ditarik (saldo poin bisa minus)
+	points, err := getTransactionPoints(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if points.Earned > 0 {
+		err = insertPointEntry(tx, &models.PointLedgerEntry{
+			CustomerID:    points.CustomerID,
+			Type:          models.PointEntryReverse,
+			Points:        -points.Earned,
+			TransactionID: &id,
+			Note:          &reason,
+			CreatedBy:     &voidedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if points.Redeemed > 0 {
+		err = insertPointEntry(tx, &models.PointLedgerEntry{
+			CustomerID:    points.CustomerID,
+			Type:          models.PointEntryRefund,
+			Points:        points.Redeemed,
+			TransactionID: &id,
+			Note:          &reason,
+			CreatedBy:     &voidedBy,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// ─── STEP 2D: Kembalikan kuota voucher ───
+	err = releaseVoucherRedemption(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// ─── STEP 3: Tandai transaksi sebagai void ───
+	// Uang tunai void dikembalikan dari laci shift yang sedang terbuka milik user yang mem-void
+	// (seperti retur); penjualan di shift asal tidak berubah
+	voidShiftID, err := getOpenShiftID(tx, voidedBy)
+	if err != nil {
+		return nil, err
+	}
+	var t models.Transaction
+	var discountID sql.NullInt64
+	var createdBy sql.NullInt64
+	err = tx.QueryRow(`
+		UPDATE transactions
+		SET voided_at = NOW(), voided_by = $1, void_reason = $2, voided_shift_id = $3
+		WHERE id = $4
+		RETURNING id, total_amount, discount_id, discount_amount,
+			COALESCE(payment_amount, 0), COALESCE(change_amount, 0),
+			created_at, created_by, voided_at, voided_by, void_reason
+	`, voidedBy, reason, voidShiftID, id).Scan(
+		&t.ID, &t.TotalAmount, &discountID, &t.DiscountAmount,
+		&t.PaymentAmount, &t.ChangeAmount,
+		&t.CreatedAt, &createdBy, &t.VoidedAt, &t.VoidedBy, &t.VoidReason,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membatalkan transaksi: %w", err)
+	}
+
+	if discountID.Valid {
+		did := int(discountID.Int64)
+		t.DiscountID = &did
+	}
+	if createdBy.Valid {
+		uid := int(createdBy.Int64)
+		t.CreatedBy = &uid
+	}
+
+	// ─── STEP 4: Commit ───
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🚫 Transaksi ID %d di-void oleh user %d: %s", id, voidedBy, reason)
+
+	return &t, nil
+}
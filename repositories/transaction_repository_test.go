@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"kasir-api/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCreateTransactionConcurrentLastUnit memastikan tidak ada overselling:
+// N checkout paralel untuk 1 unit stok terakhir → tepat 1 sukses, sisanya ErrInsufficientStock, stok akhir 0.
+func TestCreateTransactionConcurrentLastUnit(t *testing.T) {
+	db := openTestDB(t)
+	repo := newTestTransactionRepository(db)
+
+	const (
+		price   = 10000
+		buyers  = 10
+		initial = 1
+	)
+	productID := createTestProduct(t, db, price, initial)
+	cashierID := createTestUser(t, db, "kasir")
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	errs := make([]error, buyers)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = repo.CreateTransaction(&models.CheckoutRequest{
+				Items:         []models.CheckoutItem{{ProductID: productID, Quantity: 1}},
+				PaymentAmount: price,
+				CreatedBy:     cashierID,
+			})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for i, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		if !errors.Is(err, models.ErrInsufficientStock) {
+			t.Errorf("checkout #%d: error = %v, want ErrInsufficientStock", i+1, err)
+		}
+	}
+	if succeeded != initial {
+		t.Errorf("checkout sukses = %d, want %d", succeeded, initial)
+	}
+	if stok := getTestStock(t, db, productID); stok != 0 {
+		t.Errorf("stok akhir = %d, want 0", stok)
+	}
+}
+
+// TestCreateTransactionOverrideTokenSingleUse memastikan approval token (jti) hanya bisa dipakai 1 checkout;
+// checkout kedua ditolak ErrApprovalTokenUsed dan di-rollback (stok tidak berkurang lagi).
+func TestCreateTransactionOverrideTokenSingleUse(t *testing.T) {
+	db := openTestDB(t)
+	repo := newTestTransactionRepository(db)
+
+	const price = 10000
+	productID := createTestProduct(t, db, price, 5)
+	cashierID := createTestUser(t, db, "kasir")
+	approverID := createTestUser(t, db, "admin")
+	jti := fmt.Sprintf("test-jti-%d", time.Now().UnixNano())
+
+	checkout := func() error {
+		overridePrice := float64(8000)
+		_, err := repo.CreateTransaction(&models.CheckoutRequest{
+			Items: []models.CheckoutItem{{
+				ProductID:          productID,
+				Quantity:           1,
+				OverridePrice:      &overridePrice,
+				OverrideApprovedBy: &approverID,
+				OverrideReason:     "harga grosir",
+				OverrideTokenID:    jti,
+			}},
+			PaymentAmount: price,
+			CreatedBy:     cashierID,
+		})
+		return err
+	}
+
+	if err := checkout(); err != nil {
+		t.Fatalf("checkout pertama: error = %v, want nil", err)
+	}
+	if err := checkout(); !errors.Is(err, models.ErrApprovalTokenUsed) {
+		t.Fatalf("checkout kedua: error = %v, want ErrApprovalTokenUsed", err)
+	}
+	if stok := getTestStock(t, db, productID); stok != 4 {
+		t.Errorf("stok akhir = %d, want 4 (checkout kedua harus di-rollback)", stok)
+	}
+}
@@ -0,0 +1,300 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"log"
+	"strings"
+	"time"
+)
+
+// VoucherRepository handles database operations for vouchers
+type VoucherRepository struct {
+	db *sql.DB
+}
+
+// NewVoucherRepository creates a new VoucherRepository
+func NewVoucherRepository(db *sql.DB) *VoucherRepository {
+	return &VoucherRepository{db: db}
+}
+
+const voucherSelectQuery = `
+	SELECT id, code, name, type, value, min_order_amount, max_redemptions, per_customer_limit,
+		redemption_count, start_date, end_date, is_active, created_by, created_at, updated_at
+	FROM vouchers
+`
+
+func scanVoucher(row rowScanner) (*models.Voucher, error) {
+	var v models.Voucher
+	err := row.Scan(&v.ID, &v.Code, &v.Name, &v.Type, &v.Value, &v.MinOrderAmount, &v.MaxRedemptions, &v.PerCustomerLimit,
+		&v.RedemptionCount, &v.StartDate, &v.EndDate, &v.IsActive, &v.CreatedBy, &v.CreatedAt, &v.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	v.SetRemaining()
+	return &v, nil
+}
+
+// GetAll mengambil voucher dengan pencarian kode / nama dan pagination (terbaru dulu)
+// Return: vouchers, total count, error
+func (r *VoucherRepository) GetAll(search string, pagination *models.PaginationParams) ([]models.Voucher, int, error) {
+	where := ""
+	var args []interface{}
+	if search != "" {
+		where = " WHERE code ILIKE $1 OR name ILIKE $1"
+		args = append(args, "%"+search+"%")
+	}
+
+	var totalItems int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM vouchers"+where, args...).Scan(&totalItems)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal menghitung voucher: %w", err)
+	}
+
+	query := voucherSelectQuery + where + " ORDER BY id DESC"
+	if pagination != nil {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+		args = append(args, pagination.Limit, pagination.GetOffset())
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gagal mengambil voucher: %w", err)
+	}
+	defer rows.Close()
+
+	vouchers := []models.Voucher{}
+	for rows.Next() {
+		v, err := scanVoucher(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("gagal membaca voucher: %w", err)
+		}
+		vouchers = append(vouchers, *v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("gagal membaca voucher: %w", err)
+	}
+	return vouchers, totalItems, nil
+}
+
+// GetByID mengambil voucher by ID
+func (r *VoucherRepository) GetByID(id int) (*models.Voucher, error) {
+	v, err := scanVoucher(r.db.QueryRow(voucherSelectQuery+" WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("voucher dengan ID %d tidak ditemukan", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Create menyimpan 1 atau beberapa voucher dalam 1 database transaction
+// Kode yang sudah dipakai → models.ErrVoucherCodeExists (semua voucher batal dibuat)
+func (r *VoucherRepository) Create(vouchers []*models.Voucher) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, v := range vouchers {
+		err = tx.QueryRow(`
+			INSERT INTO vouchers (code, name, type, value, min_order_amount, max_redemptions, per_customer_limit,
+				start_date, end_date, is_active, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING id, created_at, updated_at
+		`, v.Code, v.Name, v.Type, v.Value, v.MinOrderAmount, v.MaxRedemptions, v.PerCustomerLimit,
+			v.StartDate, v.EndDate, v.IsActive, v.CreatedBy).Scan(&v.ID, &v.CreatedAt, &v.UpdatedAt)
+		if err != nil {
+			if strings.Contains(err.Error(), "duplicate key") {
+				err = fmt.Errorf("%w (%s)", models.ErrVoucherCodeExists, v.Code)
+				return err
+			}
+			err = fmt.Errorf("gagal menyimpan voucher: %w", err)
+			return err
+		}
+		v.SetRemaining()
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🎟️ %d voucher dibuat (%s)", len(vouchers), vouchers[0].Name)
+	return nil
+}
+
+// Update mengubah voucher (redemption_count tidak berubah)
+// Kuota baru tidak boleh lebih kecil dari pemakaian yang sudah terjadi (CHECK chk_vouchers_quota)
+func (r *VoucherRepository) Update(v *models.Voucher) error {
+	err := r.db.QueryRow(`
+		UPDATE vouchers
+		SET code = $1, name = $2, type = $3, value = $4, min_order_amount = $5, max_redemptions = $6,
+			per_customer_limit = $7, start_date = $8, end_date = $9, is_active = $10, updated_at = NOW()
+		WHERE id = $11
+		RETURNING redemption_count, created_by, created_at, updated_at
+	`, v.Code, v.Name, v.Type, v.Value, v.MinOrderAmount, v.MaxRedemptions, v.PerCustomerLimit,
+		v.StartDate, v.EndDate, v.IsActive, v.ID).Scan(&v.RedemptionCount, &v.CreatedBy, &v.CreatedAt, &v.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("voucher dengan ID %d tidak ditemukan", v.ID)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return models.ErrVoucherCodeExists
+		}
+		if strings.Contains(err.Error(), "chk_vouchers_quota") {
+			return fmt.Errorf("%w (sudah dipakai %d kali)", models.ErrInvalidVoucherLimit, v.RedemptionCount)
+		}
+		return fmt.Errorf("gagal mengupdate voucher: %w", err)
+	}
+	v.SetRemaining()
+	return nil
+}
+
+// Deactivate menonaktifkan voucher
+// Voucher tidak dihapus agar riwayat pemakaian tetap utuh
+func (r *VoucherRepository) Deactivate(id int) error {
+	result, err := r.db.Exec("UPDATE vouchers SET is_active = FALSE, updated_at = NOW() WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("voucher dengan ID %d tidak ditemukan", id)
+	}
+	return nil
+}
+
+// loadVoucherByCode mengambil voucher by kode (sudah dinormalisasi) untuk pricing engine
+func loadVoucherByCode(q queryer, code string) (*models.Voucher, error) {
+	v, err := scanVoucher(q.QueryRow(voucherSelectQuery+" WHERE code = $1", code))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("voucher %s tidak ditemukan", code)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil voucher: %w", err)
+	}
+	return v, nil
+}
+
+// checkVoucherUsable memvalidasi voucher sebelum dipakai (masa berlaku, kuota & batas per pelanggan)
+// Dipakai preview & checkout; di checkout kuota dicek ulang secara atomik oleh redeemVoucher
+func checkVoucherUsable(q queryer, v *models.Voucher, customerID *int, now time.Time) error {
+	if !v.IsValidAt(now) {
+		return models.ErrVoucherInactive
+	}
+	if v.MaxRedemptions != nil && v.RedemptionCount >= *v.MaxRedemptions {
+		return models.ErrVoucherExhausted
+	}
+	if v.PerCustomerLimit == nil {
+		return nil
+	}
+	if customerID == nil {
+		return models.ErrVoucherRequiresCustomer
+	}
+	used, err := countCustomerRedemptions(q, v.ID, *customerID)
+	if err != nil {
+		return err
+	}
+	if used >= *v.PerCustomerLimit {
+		return fmt.Errorf("%w (batas %d kali)", models.ErrVoucherCustomerLimit, *v.PerCustomerLimit)
+	}
+	return nil
+}
+
+func countCustomerRedemptions(q queryer, voucherID, customerID int) (int, error) {
+	var used int
+	err := q.QueryRow(`
+		SELECT COUNT(*) FROM voucher_redemptions
+		WHERE voucher_id = $1 AND customer_id = $2 AND voided_at IS NULL
+	`, voucherID, customerID).Scan(&used)
+	if err != nil {
+		return 0, fmt.Errorf("gagal menghitung pemakaian voucher: %w", err)
+	}
+	return used, nil
+}
+
+// redeemVoucher mencatat pemakaian voucher di dalam transaksi checkout
+// Increment redemption_count bersyarat (kuota belum habis) dalam 1 UPDATE → atomik:
+// 2 kasir yang memakai kode sekali pakai bersamaan, hanya 1 yang berhasil.
+// Row voucher tetap ter-lock sampai commit, sehingga cek batas per pelanggan juga aman.
+func redeemVoucher(tx *sql.Tx, v *models.Voucher, customerID *int, transactionID int, discountAmount float64, createdBy int) error {
+	var count int
+	err := tx.QueryRow(`
+		UPDATE vouchers SET redemption_count = redemption_count + 1
+		WHERE id = $1 AND (max_redemptions IS NULL OR redemption_count < max_redemptions)
+		RETURNING redemption_count
+	`, v.ID).Scan(&count)
+	if err == sql.ErrNoRows {
+		return models.ErrVoucherExhausted
+	}
+	if err != nil {
+		return fmt.Errorf("gagal memakai voucher: %w", err)
+	}
+
+	if v.PerCustomerLimit != nil {
+		if customerID == nil {
+			return models.ErrVoucherRequiresCustomer
+		}
+		used, err := countCustomerRedemptions(tx, v.ID, *customerID)
+		if err != nil {
+			return err
+		}
+		if used >= *v.PerCustomerLimit {
+			return fmt.Errorf("%w (batas %d kali)", models.ErrVoucherCustomerLimit, *v.PerCustomerLimit)
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO voucher_redemptions (voucher_id, transaction_id, customer_id, discount_amount, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+	`, v.ID, transactionID, customerID, discountAmount, createdBy)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat pemakaian voucher: %w", err)
+	}
+	return nil
+}
+
+// releaseVoucherRedemption mengembalikan kuota voucher saat transaksi di-void
+func releaseVoucherRedemption(tx *sql.Tx, transactionID int) error {
+	rows, err := tx.Query(`
+		UPDATE voucher_redemptions SET voided_at = NOW()
+		WHERE transaction_id = $1 AND voided_at IS NULL
+		RETURNING voucher_id
+	`, transactionID)
+	if err != nil {
+		return fmt.Errorf("gagal membatalkan pemakaian voucher: %w", err)
+	}
+	var voucherIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		voucherIDs = append(voucherIDs, id)
+	}
+	rows.Close()
+	// Iterasi terputus → jangan kembalikan kuota sebagian (tx di-rollback oleh caller)
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("gagal membatalkan pemakaian voucher: %w", err)
+	}
+
+	for _, id := range voucherIDs {
+		_, err = tx.Exec("UPDATE vouchers SET redemption_count = GREATEST(redemption_count - 1, 0) WHERE id = $1", id)
+		if err != nil {
+			return fmt.Errorf("gagal mengembalikan kuota voucher: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// CashMovementService handles business logic for drawer cash movements
+type CashMovementService struct {
+	repo *repositories.CashMovementRepository
+}
+
+// NewCashMovementService creates a new CashMovementService
+func NewCashMovementService(repo *repositories.CashMovementRepository) *CashMovementService {
+	return &CashMovementService{repo: repo}
+}
+
+// Create mencatat pay in / pay out / drop pada shift user yang sedang terbuka
+func (s *CashMovementService) Create(userID int, req *models.CashMovementRequest) (*models.CashMovement, error) {
+	if !models.IsValidCashMovementType(req.Type) {
+		return nil, models.ErrInvalidCashMovement
+	}
+	if req.Amount <= 0 {
+		return nil, models.ErrInvalidCashAmount
+	}
+	return s.repo.Create(userID, req)
+}
+
+// GetAll returns cash movement history (Admin: semua / filter, Kasir: milik sendiri)
+func (s *CashMovementService) GetAll(user *models.User, shiftID *int) ([]models.CashMovement, error) {
+	if !user.IsAdmin() {
+		return s.repo.GetAll(shiftID, &user.ID)
+	}
+	return s.repo.GetAll(shiftID, nil)
+}
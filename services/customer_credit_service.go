@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"math"
+	"strings"
+	"time"
+)
+
+// CustomerCreditService handles business logic for customer credit (kasbon)
+type CustomerCreditService struct {
+	repo *repositories.CustomerCreditRepository
+}
+
+// NewCustomerCreditService creates a new CustomerCreditService
+func NewCustomerCreditService(repo *repositories.CustomerCreditRepository) *CustomerCreditService {
+	return &CustomerCreditService{repo: repo}
+}
+
+// GetSummary mengambil saldo kasbon, aging & riwayat 1 pelanggan
+func (s *CustomerCreditService) GetSummary(customerID int) (*models.CustomerCreditSummary, error) {
+	return s.repo.GetSummary(customerID)
+}
+
+// Repay mencatat pembayaran / cicilan kasbon (boleh sebagian)
+// Metode default cash; cicilan tidak bisa dibayar dengan kasbon lagi atau dengan poin
+func (s *CustomerCreditService) Repay(customerID int, req *models.CreditRepaymentRequest) (*models.CreditLedgerEntry, error) {
+	req.Amount = math.Round(req.Amount*100) / 100
+	if req.Amount <= 0 {
+		return nil, models.ErrInvalidRepaymentAmount
+	}
+
+	req.Method = strings.ToLower(strings.TrimSpace(req.Method))
+	if req.Method == "" {
+		req.Method = models.PaymentMethodCash
+	}
+	if req.Method == models.PaymentMethodCredit || req.Method == models.PaymentMethodPoints || !models.IsValidPaymentMethod(req.Method) {
+		return nil, fmt.Errorf("%w (%s)", models.ErrInvalidPaymentMethod, req.Method)
+	}
+	req.Reference = strings.TrimSpace(req.Reference)
+	req.Note = strings.TrimSpace(req.Note)
+
+	return s.repo.Repay(customerID, req)
+}
+
+// GetReceivables merangkum piutang semua pelanggan per saat ini
+func (s *CustomerCreditService) GetReceivables() (*models.ReceivablesReport, error) {
+	return s.repo.GetReceivables(time.Now())
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+// CustomerService handles business logic for customers
+type CustomerService struct {
+	repo *repositories.CustomerRepository
+}
+
+// NewCustomerService creates a new CustomerService
+func NewCustomerService(repo *repositories.CustomerRepository) *CustomerService {
+	return &CustomerService{repo: repo}
+}
+
+// GetAll mengambil pelanggan dengan pencarian (nama / no. HP), urutan & pagination
+func (s *CustomerService) GetAll(search, sort string, pagination *models.PaginationParams) ([]models.Customer, int, error) {
+	return s.repo.GetAll(strings.TrimSpace(search), sort, pagination)
+}
+
+// GetByID mengambil pelanggan by ID
+func (s *CustomerService) GetByID(id int) (*models.Customer, error) {
+	return s.repo.GetByID(id)
+}
+
+// GetByPhone mengambil pelanggan by no. HP (format bebas, dinormalisasi dulu)
+func (s *CustomerService) GetByPhone(phone string) (*models.Customer, error) {
+	return s.repo.GetByPhone(models.NormalizePhone(phone))
+}
+
+// Create menambahkan pelanggan baru (no. HP dinormalisasi sebagai natural key)
+func (s *CustomerService) Create(req models.CustomerRequest) (*models.Customer, error) {
+	customer, err := s.buildCustomer(req, true, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(customer); err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+// Update mengubah data pelanggan
+// is_active tidak dikirim → status aktif tidak berubah
+func (s *CustomerService) Update(id int, req models.CustomerRequest) (*models.Customer, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := s.buildCustomer(req, existing.IsActive, existing.CreditLimit)
+	if err != nil {
+		return nil, err
+	}
+	customer.ID = id
+	if err := s.repo.Update(customer); err != nil {
+		return nil, err
+	}
+
+	// Total belanja tidak berubah oleh update data pelanggan
+	customer.LifetimeSpend = existing.LifetimeSpend
+	customer.VisitCount = existing.VisitCount
+	customer.LastVisit = existing.LastVisit
+	customer.CreditBalance = existing.CreditBalance
+	return customer, nil
+}
+
+// SoftDelete menonaktifkan pelanggan (riwayat transaksi tetap tersimpan)
+func (s *CustomerService) SoftDelete(id int) error {
+	return s.repo.SoftDelete(id)
+}
+
+// GetTransactions mengambil riwayat belanja pelanggan
+func (s *CustomerService) GetTransactions(customerID int, pagination *models.PaginationParams) ([]models.Transaction, int, error) {
+	return s.repo.GetTransactions(customerID, pagination)
+}
+
+func (s *CustomerService) buildCustomer(req models.CustomerRequest, defaultActive bool, defaultCreditLimit float64) (*models.Customer, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Phone = models.NormalizePhone(req.Phone)
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	isActive := defaultActive
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	creditLimit := defaultCreditLimit
+	if req.CreditLimit != nil {
+		creditLimit = *req.CreditLimit
+	}
+
+	return &models.Customer{
+		Name:        req.Name,
+		Phone:       req.Phone,
+		Email:       req.Email,
+		Address:     req.Address,
+		Notes:       req.Notes,
+		IsActive:    isActive,
+		CreditLimit: creditLimit,
+	}, nil
+}
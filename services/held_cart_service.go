@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// HeldCartService handles business logic for held (parked) carts
+type HeldCartService struct {
+	repo               *repositories.HeldCartRepository
+	transactionService *TransactionService
+	reservationMinutes int
+}
+
+// NewHeldCartService creates a new HeldCartService
+// reservationMinutes = lama reservasi stok untuk cart dengan reserve_stock = true
+func NewHeldCartService(repo *repositories.HeldCartRepository, transactionService *TransactionService, reservationMinutes int) *HeldCartService {
+	if reservationMinutes <= 0 {
+		reservationMinutes = 30
+	}
+	return &HeldCartService{
+		repo:               repo,
+		transactionService: transactionService,
+		reservationMinutes: reservationMinutes,
+	}
+}
+
+// Create menyimpan keranjang milik user
+func (s *HeldCartService) Create(req *models.HeldCartRequest, userID int) (*models.HeldCart, error) {
+	if err := validateCheckoutItems(req.Items); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Create(userID, req.Label, &req.CheckoutRequest, req.ReserveStock, s.reservationMinutes)
+}
+
+// GetAll returns held carts (Admin: semua, Kasir: milik sendiri)
+func (s *HeldCartService) GetAll(user *models.User) ([]models.HeldCart, error) {
+	if user.IsAdmin() {
+		return s.repo.GetAll(nil)
+	}
+	return s.repo.GetAll(&user.ID)
+}
+
+// GetByID returns a held cart, hanya pemilik atau Admin yang boleh akses
+func (s *HeldCartService) GetByID(id int, user *models.User) (*models.HeldCart, error) {
+	cart, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if cart.UserID != user.ID && !user.IsAdmin() {
+		return nil, fmt.Errorf("%w: keranjang milik kasir lain", models.ErrForbidden)
+	}
+	return cart, nil
+}
+
+// Delete menghapus keranjang (melepas reservasi stok)
+func (s *HeldCartService) Delete(id int, user *models.User) error {
+	if _, err := s.GetByID(id, user); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Checkout melanjutkan keranjang ke checkout normal (TransactionService.Checkout)
+// Item & diskon dari cart, pembayaran dari request. Cart terhapus dalam transaksi yang sama.
+func (s *HeldCartService) Checkout(id int, req *models.HeldCartCheckoutRequest, user *models.User) (*models.Transaction, error) {
+	cart, err := s.GetByID(id, user)
+	if err != nil {
+		return nil, err
+	}
+
+	checkoutReq := cart.Cart
+	checkoutReq.PaymentAmount = req.PaymentAmount
+	checkoutReq.Payments = req.Payments
+	checkoutReq.CreatedBy = user.ID
+	checkoutReq.HeldCartID = &cart.ID
+	checkoutReq.DiscountOverride = user.IsAdmin()
+
+	return s.transactionService.Checkout(&checkoutReq)
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// LoyaltyService handles business logic for customer loyalty points
+type LoyaltyService struct {
+	repo *repositories.LoyaltyRepository
+}
+
+// NewLoyaltyService creates a new LoyaltyService
+func NewLoyaltyService(repo *repositories.LoyaltyRepository) *LoyaltyService {
+	return &LoyaltyService{repo: repo}
+}
+
+// GetSummary mengambil saldo poin, poin yang akan kedaluwarsa & riwayat 1 pelanggan
+func (s *LoyaltyService) GetSummary(customerID int) (*models.LoyaltySummary, error) {
+	return s.repo.GetSummary(customerID)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"kasir-api/utils"
+	"strings"
+	"time"
+)
+
+// OverrideService handles supervisor approval for price / discount overrides
+type OverrideService struct {
+	userRepo *repositories.UserRepository
+	tokenTTL time.Duration
+}
+
+// NewOverrideService creates a new OverrideService
+// tokenMinutes = masa berlaku token persetujuan
+func NewOverrideService(userRepo *repositories.UserRepository, tokenMinutes int) *OverrideService {
+	return &OverrideService{
+		userRepo: userRepo,
+		tokenTTL: time.Duration(tokenMinutes) * time.Minute,
+	}
+}
+
+// Approve memverifikasi kredensial admin dan menerbitkan token persetujuan
+// Token hanya berlaku untuk kasir yang memintanya (cashier), 1 produk, harga / batas diskon
+// yang disetujui, sekali pakai dan berumur pendek.
+func (s *OverrideService) Approve(cashier *models.User, req *models.OverrideApprovalRequest) (*models.OverrideApproval, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, models.ErrOverrideReasonEmpty
+	}
+	if err := validateOverrideApprovalRequest(req); err != nil {
+		return nil, err
+	}
+
+	approver, err := s.userRepo.GetByUsername(req.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if !approver.IsActive || !utils.CheckPasswordHash(req.Password, approver.Password) {
+		return nil, models.ErrInvalidCredentials
+	}
+	if !approver.IsAdmin() {
+		return nil, models.ErrApproverNotAdmin
+	}
+
+	token, expiresAt, err := utils.GenerateOverrideJWT(utils.OverrideClaims{
+		ApproverID:        approver.ID,
+		ApproverName:      approver.Username,
+		CashierID:         cashier.ID,
+		Reason:            reason,
+		ProductID:         req.ProductID,
+		OverridePrice:     req.OverridePrice,
+		MaxDiscountAmount: req.MaxDiscountAmount,
+	}, s.tokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat token persetujuan: %w", err)
+	}
+
+	return &models.OverrideApproval{
+		Token:             token,
+		ExpiresAt:         expiresAt,
+		ApprovedBy:        approver.ID,
+		ApproverName:      approver.Username,
+		Reason:            reason,
+		ProductID:         req.ProductID,
+		OverridePrice:     req.OverridePrice,
+		MaxDiscountAmount: req.MaxDiscountAmount,
+	}, nil
+}
+
+// validateOverrideApprovalRequest memastikan persetujuan menyebut produk dan apa yang disetujui
+func validateOverrideApprovalRequest(req *models.OverrideApprovalRequest) error {
+	if req.ProductID <= 0 {
+		return models.ErrOverrideProductEmpty
+	}
+	if req.OverridePrice == nil && req.MaxDiscountAmount == nil {
+		return models.ErrOverrideNothingApproved
+	}
+	if req.OverridePrice != nil && *req.OverridePrice < 0 {
+		return models.ErrInvalidOverridePrice
+	}
+	if req.MaxDiscountAmount != nil && *req.MaxDiscountAmount <= 0 {
+		return models.ErrInvalidMaxDiscount
+	}
+	return nil
+}
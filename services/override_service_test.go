@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"kasir-api/models"
+	"testing"
+)
+
+func TestOverrideApproveValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     models.OverrideApprovalRequest
+		wantErr error
+	}{
+		{
+			name:    "tanpa alasan",
+			req:     models.OverrideApprovalRequest{ProductID: testProductID, OverridePrice: price(8000)},
+			wantErr: models.ErrOverrideReasonEmpty,
+		},
+		{
+			name:    "tanpa produk",
+			req:     models.OverrideApprovalRequest{Reason: "grosir", OverridePrice: price(8000)},
+			wantErr: models.ErrOverrideProductEmpty,
+		},
+		{
+			name:    "tanpa harga maupun batas diskon",
+			req:     models.OverrideApprovalRequest{Reason: "grosir", ProductID: testProductID},
+			wantErr: models.ErrOverrideNothingApproved,
+		},
+		{
+			name:    "harga negatif",
+			req:     models.OverrideApprovalRequest{Reason: "grosir", ProductID: testProductID, OverridePrice: price(-1)},
+			wantErr: models.ErrInvalidOverridePrice,
+		},
+		{
+			name:    "batas diskon nol",
+			req:     models.OverrideApprovalRequest{Reason: "grosir", ProductID: testProductID, MaxDiscountAmount: price(0)},
+			wantErr: models.ErrInvalidMaxDiscount,
+		},
+	}
+
+	// Validasi berjalan sebelum cek kredensial, jadi userRepo tidak dibutuhkan
+	svc := NewOverrideService(nil, 5)
+	cashier := &models.User{ID: testCashierID}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			if _, err := svc.Approve(cashier, &req); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"math"
+	"strings"
+)
+
+// ReceiptService renders transaction receipts (ESC/POS, plain text, HTML)
+// Layout struk dibuat sekali di sini agar semua frontend tidak perlu menyusun ulang
+type ReceiptService struct {
+	repo     *repositories.TransactionRepository
+	settings models.ReceiptSettings
+}
+
+// NewReceiptService creates a new ReceiptService
+func NewReceiptService(repo *repositories.TransactionRepository, settings models.ReceiptSettings) *ReceiptService {
+	return &ReceiptService{repo: repo, settings: settings}
+}
+
+// Render membuat struk 1 transaksi dalam format yang diminta
+// print = false → preview (tanpa efek samping, print_count tidak berubah);
+// print = true → cetak: print_count bertambah, cetakan ke-2 dst ditandai CETAK ULANG.
+// Mengembalikan isi struk dan Content-Type yang sesuai.
+func (s *ReceiptService) Render(id int, format string, print bool) ([]byte, string, error) {
+	if !models.IsValidReceiptFormat(format) {
+		return nil, "", models.ErrInvalidReceiptFormat
+	}
+
+	trx, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if print {
+		trx.PrintCount, err = s.repo.IncrementPrintCount(id)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	lines := s.buildLines(trx)
+
+	switch format {
+	case models.ReceiptFormatESCPOS:
+		return renderESCPOS(lines), "application/octet-stream", nil
+	case models.ReceiptFormatHTML:
+		content, err := s.renderHTML(trx.ID, lines)
+		if err != nil {
+			return nil, "", err
+		}
+		return content, "text/html; charset=utf-8", nil
+	default:
+		return renderText(lines), "text/plain; charset=utf-8", nil
+	}
+}
+
+// receiptLine adalah 1 baris struk yang sudah di-pad sesuai lebar kertas
+type receiptLine struct {
+	Text   string
+	Center bool
+	Bold   bool
+	Large  bool // Huruf besar (nama toko)
+}
+
+// buildLines menyusun layout struk dalam bentuk baris-baris teks
+// Dipakai bersama oleh semua format agar isi struk selalu sama
+func (s *ReceiptService) buildLines(trx *models.TransactionWithItems) []receiptLine {
+	width := s.settings.Columns()
+	separator := receiptLine{Text: strings.Repeat("-", width)}
+
+	var lines []receiptLine
+	center := func(text string, bold bool) {
+		for _, l := range wrapText(text, width) {
+			lines = append(lines, receiptLine{Text: l, Center: true, Bold: bold})
+		}
+	}
+	row := func(label, value string, bold bool) {
+		lines = append(lines, receiptLine{Text: padBetween(label, value, width), Bold: bold})
+	}
+
+	// ─── Header toko ───
+	if s.settings.StoreName != "" {
+		for _, l := range wrapText(s.settings.StoreName, width) {
+			lines = append(lines, receiptLine{Text: l, Center: true, Bold: true, Large: true})
+		}
+	}
+	if s.settings.StoreAddress != "" {
+		center(s.settings.StoreAddress, false)
+	}
+	lines = append(lines, separator)
+
+	// ─── Info transaksi ───
+	row("No", fmt.Sprintf("#%d", trx.ID), false)
+	row("Tanggal", trx.CreatedAt.Format("02/01/2006 15:04"), false)
+	if trx.Username != "" {
+		row("Kasir", trx.Username, false)
+	}
+	if trx.CustomerName != "" {
+		row("Pelanggan", trx.CustomerName, false)
+	}
+	if trx.PrintCount > 1 {
+		center(fmt.Sprintf("** CETAK ULANG ke-%d **", trx.PrintCount-1), true)
+	}
+	if trx.VoidedAt != nil {
+		center("*** TRANSAKSI DIBATALKAN ***", true)
+	}
+	lines = append(lines, separator)
+
+	// ─── Items ───
+	var subtotal float64
+	for _, item := range trx.Items {
+		for _, l := range wrapText(item.ProductName, width) {
+			lines = append(lines, receiptLine{Text: l})
+		}
+		gross := item.Price * float64(item.Quantity)
+		row(fmt.Sprintf("  %d x %s", item.Quantity, formatRupiah(item.Price)), formatRupiah(gross), false)
+		// Diskon produk/kategori dan promosi ditampilkan terpisah
+		if itemDiscount := item.DiscountAmount - item.PromotionAmount; itemDiscount > 0.005 {
+			label := "  Diskon"
+			if item.DiscountType == "percentage" {
+				label = fmt.Sprintf("  Diskon %s%%", formatNumber(item.DiscountValue))
+			}
+			row(label, "-"+formatRupiah(itemDiscount), false)
+		}
+		if item.PromotionAmount > 0 {
+			label := "  Promo"
+			if item.PromotionName != "" {
+				label = "  Promo " + item.PromotionName
+			}
+			row(label, "-"+formatRupiah(item.PromotionAmount), false)
+		}
+		subtotal += item.Subtotal
+	}
+	lines = append(lines, separator)
+
+	// ─── Total ───
+	// discount_amount di header = diskon item + diskon global,
+	// sehingga diskon global dihitung dari selisih subtotal dan total sebelum pajak.
+	// PPN exclusive & service charge ditambahkan setelah diskon.
+	addedTax := trx.TaxAmount - trx.TaxIncludedAmount
+	totalBeforeTax := trx.TotalAmount - trx.ServiceChargeAmount - addedTax
+	row("Subtotal", formatRupiah(subtotal), false)
+	if globalDiscount := math.Round((subtotal-totalBeforeTax)*100) / 100; globalDiscount > 0 {
+		label := "Diskon"
+		if trx.VoucherCode != "" {
+			label = "Voucher " + trx.VoucherCode
+		}
+		row(label, "-"+formatRupiah(globalDiscount), false)
+	}
+	if trx.ServiceChargeAmount > 0 {
+		row("Service charge", formatRupiah(trx.ServiceChargeAmount), false)
+	}
+	if addedTax > 0 {
+		row("PPN", formatRupiah(addedTax), false)
+	}
+	row("TOTAL", formatRupiah(trx.TotalAmount), true)
+	if trx.TaxIncludedAmount > 0 {
+		row("  Termasuk PPN", formatRupiah(trx.TaxIncludedAmount), false)
+	}
+	// Pembulatan porsi tunai (bisa negatif jika dibulatkan ke bawah)
+	if trx.RoundingAmount != 0 {
+		row("Pembulatan", formatRupiah(trx.RoundingAmount), false)
+	}
+
+	// ─── Pembayaran ───
+	// Transaksi lama (sebelum split tender) tidak punya rincian → tampilkan payment_amount sebagai tunai
+	if len(trx.Payments) == 0 {
+		row(paymentLabel(models.PaymentMethodCash), formatRupiah(trx.PaymentAmount), false)
+	}
+	for _, p := range trx.Payments {
+		row(paymentLabel(p.Method), formatRupiah(p.TenderedAmount), false)
+		if p.Reference != nil && *p.Reference != "" {
+			row("  Ref", *p.Reference, false)
+		}
+	}
+	row("Kembali", formatRupiah(trx.ChangeAmount), false)
+	lines = append(lines, separator)
+
+	// ─── Footer ───
+	if s.settings.Footer != "" {
+		center(s.settings.Footer, false)
+	}
+
+	// Pad semua baris rata tengah ke lebar kertas (untuk text & html)
+	for i := range lines {
+		if lines[i].Center {
+			lines[i].Text = centerText(lines[i].Text, width)
+		}
+	}
+
+	return lines
+}
+
+// renderText menggabungkan baris menjadi plain text
+func renderText(lines []receiptLine) []byte {
+	var buf bytes.Buffer
+	for _, l := range lines {
+		buf.WriteString(strings.TrimRight(l.Text, " "))
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// Command ESC/POS yang dipakai
+var (
+	escposInit        = []byte{0x1B, 0x40}                               // ESC @ : reset printer
+	escposAlignLeft   = []byte{0x1B, 0x61, 0x00}                         // ESC a 0
+	escposAlignCenter = []byte{0x1B, 0x61, 0x01}                         // ESC a 1
+	escposBoldOn      = []byte{0x1B, 0x45, 0x01}                         // ESC E 1
+	escposBoldOff     = []byte{0x1B, 0x45, 0x00}                         // ESC E 0
+	escposDoubleOn    = []byte{0x1D, 0x21, 0x01}                         // GS ! : tinggi 2x (lebar tetap agar tidak melebihi kolom)
+	escposDoubleOff   = []byte{0x1D, 0x21, 0x00}                         // GS ! : ukuran normal
+	escposFeedCut     = []byte{0x1B, 0x64, 0x04, 0x1D, 0x56, 0x42, 0x00} // ESC d 4 + GS V B 0 : feed lalu potong
+)
+
+// renderESCPOS membuat byte command untuk printer thermal
+// Baris rata tengah dikirim dengan ESC a 1 (tanpa padding) agar printer yang mengatur posisi
+func renderESCPOS(lines []receiptLine) []byte {
+	var buf bytes.Buffer
+	buf.Write(escposInit)
+	for _, l := range lines {
+		if l.Center {
+			buf.Write(escposAlignCenter)
+		}
+		if l.Bold {
+			buf.Write(escposBoldOn)
+		}
+		if l.Large {
+			buf.Write(escposDoubleOn)
+		}
+
+		text := l.Text
+		if l.Center {
+			text = strings.TrimSpace(text)
+		}
+		buf.WriteString(text)
+		buf.WriteString("\n")
+
+		if l.Large {
+			buf.Write(escposDoubleOff)
+		}
+		if l.Bold {
+			buf.Write(escposBoldOff)
+		}
+		if l.Center {
+			buf.Write(escposAlignLeft)
+		}
+	}
+	buf.Write(escposFeedCut)
+	return buf.Bytes()
+}
+
+var receiptHTMLTemplate = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Struk #{{.ID}}</title>
+<style>
+  body { margin: 0; }
+  .receipt { width: {{.PaperWidth}}mm; padding: 2mm; font-family: "Courier New", monospace; font-size: 12px; }
+  .receipt pre { margin: 0; white-space: pre; }
+  .bold { font-weight: bold; }
+  .large { font-size: 16px; }
+  @media print { @page { size: {{.PaperWidth}}mm auto; margin: 0; } }
+</style>
+</head>
+<body>
+<div class="receipt">
+{{range .Lines}}<pre{{if .Bold}} class="bold{{if .Large}} large{{end}}"{{end}}>{{.Text}}</pre>
+{{end}}</div>
+</body>
+</html>
+`))
+
+// renderHTML membuat struk HTML monospace dengan lebar sesuai kertas
+func (s *ReceiptService) renderHTML(id int, lines []receiptLine) ([]byte, error) {
+	var buf bytes.Buffer
+	err := receiptHTMLTemplate.Execute(&buf, map[string]interface{}{
+		"ID":         id,
+		"PaperWidth": s.settings.PaperWidth,
+		"Lines":      lines,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat struk HTML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// paymentLabel mengubah kode metode pembayaran menjadi label di struk
+func paymentLabel(method string) string {
+	switch method {
+	case models.PaymentMethodCash:
+		return "Tunai"
+	case models.PaymentMethodQRIS:
+		return "QRIS"
+	case models.PaymentMethodDebit:
+		return "Debit"
+	case models.PaymentMethodTransfer:
+		return "Transfer"
+	case models.PaymentMethodCredit:
+		return "Kasbon"
+	case models.PaymentMethodPoints:
+		return "Poin"
+	}
+	return method
+}
+
+// formatRupiah memformat nominal dengan pemisah ribuan titik, misal 15000 -> "15.000"
+func formatRupiah(amount float64) string {
+	return formatNumber(math.Round(amount))
+}
+
+// formatNumber memformat angka dengan pemisah ribuan titik dan desimal koma (jika ada)
+func formatNumber(value float64) string {
+	negative := value < 0
+	value = math.Abs(value)
+
+	whole := int64(value)
+	frac := math.Round((value - float64(whole)) * 100)
+	if frac >= 100 {
+		whole++
+		frac = 0
+	}
+
+	digits := fmt.Sprintf("%d", whole)
+	var grouped strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(d)
+	}
+
+	result := grouped.String()
+	if frac > 0 {
+		result += strings.TrimRight(fmt.Sprintf(",%02d", int(frac)), "0")
+	}
+	if negative {
+		result = "-" + result
+	}
+	return result
+}
+
+// padBetween menaruh label di kiri dan value di kanan dalam 1 baris selebar width
+func padBetween(label, value string, width int) string {
+	gap := width - len([]rune(label)) - len([]rune(value))
+	if gap < 1 {
+		gap = 1
+	}
+	return label + strings.Repeat(" ", gap) + value
+}
+
+// centerText menaruh text di tengah baris selebar width
+func centerText(text string, width int) string {
+	pad := (width - len([]rune(text))) / 2
+	if pad <= 0 {
+		return text
+	}
+	return strings.Repeat(" ", pad) + text
+}
+
+// wrapText memecah text per kata agar tidak melebihi width karakter
+func wrapText(text string, width int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var result []string
+	current := ""
+	for _, word := range words {
+		// Kata yang lebih panjang dari 1 baris dipotong paksa
+		for len([]rune(word)) > width {
+			if current != "" {
+				result = append(result, current)
+				current = ""
+			}
+			runes := []rune(word)
+			result = append(result, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		switch {
+		case current == "":
+			current = word
+		case len([]rune(current))+1+len([]rune(word)) <= width:
+			current += " " + word
+		default:
+			result = append(result, current)
+			current = word
+		}
+	}
+	if current != "" {
+		result = append(result, current)
+	}
+	return result
+}
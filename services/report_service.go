@@ -32,6 +32,62 @@ func (s *ReportService) GetSalesReportByDateRange(startDate, endDate time.Time,
 	return s.repo.GetSalesReportByDateRange(startDate, endDate, userID)
 }
 
+// GetOverrideReport retrieves approved price / discount overrides per cashier for a date range
+func (s *ReportService) GetOverrideReport(startDate, endDate time.Time, userID *int) (*models.OverrideReport, error) {
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start_date harus sebelum atau sama dengan end_date")
+	}
+
+	return s.repo.GetOverrideReport(startDate, endDate, userID)
+}
+
+// GetVoucherReport retrieves voucher redemptions per voucher for a date range
+func (s *ReportService) GetVoucherReport(startDate, endDate time.Time) (*models.VoucherReport, error) {
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start_date harus sebelum atau sama dengan end_date")
+	}
+
+	return s.repo.GetVoucherReport(startDate, endDate)
+}
+
+// GetDiscountReport retrieves discount & promotion usage per discount for a date range
+// beserta perbandingan penjualan sebelum promo dimulai
+func (s *ReportService) GetDiscountReport(startDate, endDate time.Time) (*models.DiscountReport, error) {
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start_date harus sebelum atau sama dengan end_date")
+	}
+
+	return s.repo.GetDiscountReport(startDate, endDate)
+}
+
+// GetShrinkageReport retrieves stock opname variances (susut / lebih) posted in a date range
+func (s *ReportService) GetShrinkageReport(startDate, endDate time.Time) (*models.ShrinkageReport, error) {
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start_date harus sebelum atau sama dengan end_date")
+	}
+
+	return s.repo.GetShrinkageReport(startDate, endDate)
+}
+
+// GetWriteOffReport retrieves approved stock adjustments (write-off & found) valued at harga_beli for a date range
+func (s *ReportService) GetWriteOffReport(startDate, endDate time.Time) (*models.WriteOffReport, error) {
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start_date harus sebelum atau sama dengan end_date")
+	}
+
+	return s.repo.GetWriteOffReport(startDate, endDate)
+}
+
+// GetTaxReport retrieves tax (PPN) summary per tax class for a date range
+// Dipakai untuk pelaporan pajak bulanan
+func (s *ReportService) GetTaxReport(startDate, endDate time.Time) (*models.TaxReport, error) {
+	if startDate.After(endDate) {
+		return nil, fmt.Errorf("start_date harus sebelum atau sama dengan end_date")
+	}
+
+	return s.repo.GetTaxReport(startDate, endDate)
+}
+
 // GetSalesTrend retrieves sales trend data based on period type
 // Jika startDate & endDate diisi, akan digunakan langsung (custom range).
 // Jika kosong (zero value), akan fallback ke preset berdasarkan periodType.
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// SalesReturnService handles business logic for sales returns
+type SalesReturnService struct {
+	repo *repositories.SalesReturnRepository
+}
+
+// NewSalesReturnService creates a new SalesReturnService
+func NewSalesReturnService(repo *repositories.SalesReturnRepository) *SalesReturnService {
+	return &SalesReturnService{repo: repo}
+}
+
+// Create memproses retur sebagian item dari transaksi
+func (s *SalesReturnService) Create(transactionID int, req *models.SalesReturnRequest) (*models.SalesReturn, error) {
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("items retur tidak boleh kosong")
+	}
+
+	for i, item := range req.Items {
+		if item.TransactionDetailID <= 0 {
+			return nil, fmt.Errorf("item #%d: transaction_detail_id tidak valid", i+1)
+		}
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("item #%d: quantity harus lebih dari 0", i+1)
+		}
+	}
+
+	return s.repo.Create(transactionID, req)
+}
+
+// GetByTransactionID returns all returns for a transaction
+func (s *SalesReturnService) GetByTransactionID(transactionID int) ([]models.SalesReturn, error) {
+	return s.repo.GetByTransactionID(transactionID)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// ShiftService handles business logic for cashier shifts
+type ShiftService struct {
+	repo *repositories.ShiftRepository
+}
+
+// NewShiftService creates a new ShiftService
+func NewShiftService(repo *repositories.ShiftRepository) *ShiftService {
+	return &ShiftService{repo: repo}
+}
+
+// Open membuka shift baru dengan modal awal
+func (s *ShiftService) Open(userID int, req *models.OpenShiftRequest) (*models.CashierShift, error) {
+	if req.OpeningFloat < 0 {
+		return nil, models.ErrInvalidOpeningFloat
+	}
+	return s.repo.Open(userID, req.OpeningFloat)
+}
+
+// Close menutup shift user yang sedang terbuka
+// Uang fisik dihitung dari denominations (jika diisi), selain itu dari counted_cash
+func (s *ShiftService) Close(userID int, req *models.CloseShiftRequest) (*models.ShiftReport, error) {
+	var countedCash float64
+	if len(req.Denominations) > 0 {
+		for i, d := range req.Denominations {
+			if d.Value <= 0 || d.Count < 0 {
+				return nil, fmt.Errorf("pecahan #%d: %w", i+1, models.ErrInvalidCountedCash)
+			}
+			countedCash += d.Value * float64(d.Count)
+		}
+	} else if req.CountedCash != nil {
+		if *req.CountedCash < 0 {
+			return nil, models.ErrInvalidCountedCash
+		}
+		countedCash = *req.CountedCash
+	} else {
+		return nil, fmt.Errorf("%w: isi denominations atau counted_cash", models.ErrInvalidCountedCash)
+	}
+
+	return s.repo.Close(userID, countedCash, req.Denominations, req.Note)
+}
+
+// Current mengambil rekap berjalan shift user yang sedang terbuka
+func (s *ShiftService) Current(userID int) (*models.ShiftReport, error) {
+	shift, err := s.repo.GetOpenByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetReport(shift.ID)
+}
+
+// GetAll returns shift history (Admin: semua / filter user_id, Kasir: milik sendiri)
+func (s *ShiftService) GetAll(user *models.User, filterUserID *int) ([]models.CashierShift, error) {
+	if !user.IsAdmin() {
+		return s.repo.GetAll(&user.ID)
+	}
+	return s.repo.GetAll(filterUserID)
+}
+
+// GetReport returns reconciliation report of a shift (pemilik shift atau Admin)
+func (s *ShiftService) GetReport(id int, user *models.User) (*models.ShiftReport, error) {
+	report, err := s.repo.GetReport(id)
+	if err != nil {
+		return nil, err
+	}
+	if report.Shift.UserID != user.ID && !user.IsAdmin() {
+		return nil, fmt.Errorf("%w: shift milik kasir lain", models.ErrForbidden)
+	}
+	return report, nil
+}
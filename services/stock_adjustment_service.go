@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+// StockAdjustmentService handles business logic for manual stock adjustments
+type StockAdjustmentService struct {
+	repo          *repositories.StockAdjustmentRepository
+	approvalLimit float64 // Write-off non-admin di atas nilai ini menunggu persetujuan (0 = nonaktif)
+}
+
+// NewStockAdjustmentService creates a new StockAdjustmentService
+func NewStockAdjustmentService(repo *repositories.StockAdjustmentRepository, approvalLimit float64) *StockAdjustmentService {
+	return &StockAdjustmentService{repo: repo, approvalLimit: approvalLimit}
+}
+
+// Create memvalidasi dan mencatat dokumen penyesuaian stok
+// Dokumen dari admin langsung diproses; dari kasir menunggu persetujuan jika write-off melebihi limit
+func (s *StockAdjustmentService) Create(req *models.StockAdjustmentRequest, user *models.User) (*models.StockAdjustment, error) {
+	if len(req.Items) == 0 {
+		return nil, models.ErrStockAdjustmentEmpty
+	}
+	for i := range req.Items {
+		line := &req.Items[i]
+		line.Reason = strings.ToLower(strings.TrimSpace(line.Reason))
+		if line.ProductID <= 0 {
+			return nil, fmt.Errorf("item #%d: product_id tidak valid", i+1)
+		}
+		if models.AdjustmentDirection(line.Reason) == 0 {
+			return nil, fmt.Errorf("item #%d: %w", i+1, models.ErrInvalidAdjustmentReason)
+		}
+		if line.Quantity <= 0 {
+			return nil, fmt.Errorf("item #%d: %w", i+1, models.ErrInvalidAdjustmentQuantity)
+		}
+	}
+
+	approvalLimit := s.approvalLimit
+	if user.IsAdmin() {
+		approvalLimit = 0
+	}
+	return s.repo.Create(req, user.ID, approvalLimit)
+}
+
+// GetAll returns adjustment documents, optional filter status
+func (s *StockAdjustmentService) GetAll(status string, pagination *models.PaginationParams) ([]models.StockAdjustment, int, error) {
+	switch status {
+	case "", models.StockAdjustmentStatusPending, models.StockAdjustmentStatusApproved, models.StockAdjustmentStatusRejected:
+		return s.repo.GetAll(status, pagination)
+	}
+	return nil, 0, fmt.Errorf("status harus pending, approved atau rejected")
+}
+
+// GetByID returns an adjustment document (kasir hanya dokumen miliknya sendiri)
+func (s *StockAdjustmentService) GetByID(id int, user *models.User) (*models.StockAdjustment, error) {
+	adj, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsAdmin() && (adj.CreatedBy == nil || *adj.CreatedBy != user.ID) {
+		return nil, models.ErrForbidden
+	}
+	return adj, nil
+}
+
+// Approve menyetujui dokumen pending (stok disesuaikan)
+func (s *StockAdjustmentService) Approve(id int, adminID int) (*models.StockAdjustment, error) {
+	return s.repo.Approve(id, adminID)
+}
+
+// Reject menolak dokumen pending
+func (s *StockAdjustmentService) Reject(id int, adminID int, req *models.StockAdjustmentRejectRequest) error {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return models.ErrStockAdjustmentRejectReason
+	}
+	return s.repo.Reject(id, adminID, req.Reason)
+}
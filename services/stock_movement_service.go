@@ -0,0 +1,26 @@
+package services
+
+import (
+	"kasir-api/models"
+	"kasir-api/repositories"
+)
+
+// StockMovementService handles business logic for the stock ledger
+type StockMovementService struct {
+	repo *repositories.StockMovementRepository
+}
+
+// NewStockMovementService creates a new StockMovementService
+func NewStockMovementService(repo *repositories.StockMovementRepository) *StockMovementService {
+	return &StockMovementService{repo: repo}
+}
+
+// GetProductHistory returns stock movements of a product (terbaru dulu)
+func (s *StockMovementService) GetProductHistory(productID int, pagination *models.PaginationParams) ([]models.StockMovement, int, error) {
+	return s.repo.GetByProduct(productID, pagination)
+}
+
+// Verify menghitung ulang stok dari ledger dan melaporkan produk yang tidak konsisten
+func (s *StockMovementService) Verify(all bool) (*models.StockLedgerReport, error) {
+	return s.repo.Verify(all)
+}
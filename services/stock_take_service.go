@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+// StockTakeService handles business logic for stock opname
+type StockTakeService struct {
+	repo *repositories.StockTakeRepository
+}
+
+// NewStockTakeService creates a new StockTakeService
+func NewStockTakeService(repo *repositories.StockTakeRepository) *StockTakeService {
+	return &StockTakeService{repo: repo}
+}
+
+// Create membuka sesi stock opname (seluruh toko / 1 kategori)
+func (s *StockTakeService) Create(req *models.StockTakeRequest, userID int) (*models.StockTake, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, models.ErrStockTakeNameEmpty
+	}
+	if req.Scope == "" {
+		req.Scope = models.StockTakeScopeAll
+	}
+	switch req.Scope {
+	case models.StockTakeScopeAll:
+		req.CategoryID = nil
+	case models.StockTakeScopeCategory:
+		if req.CategoryID == nil || *req.CategoryID <= 0 {
+			return nil, models.ErrInvalidStockTakeScope
+		}
+	default:
+		return nil, models.ErrInvalidStockTakeScope
+	}
+
+	return s.repo.Create(req, userID)
+}
+
+// GetAll returns stock opname sessions, optional filter status
+func (s *StockTakeService) GetAll(status string) ([]models.StockTake, error) {
+	switch status {
+	case "", models.StockTakeStatusOpen, models.StockTakeStatusPosted, models.StockTakeStatusCancelled:
+		return s.repo.GetAll(status)
+	}
+	return nil, fmt.Errorf("status harus open, posted atau cancelled")
+}
+
+// GetByID returns a session with its counts and variances
+func (s *StockTakeService) GetByID(id int, withUncounted bool) (*models.StockTake, error) {
+	return s.repo.GetByID(id, withUncounted)
+}
+
+// RecordCounts mencatat 1 batch hasil scan ke sesi
+func (s *StockTakeService) RecordCounts(id int, req *models.StockCountRequest, userID int) ([]models.StockTakeItem, error) {
+	if len(req.Items) == 0 {
+		return nil, models.ErrStockTakeEmptyCount
+	}
+	if req.Mode == "" {
+		req.Mode = models.StockCountModeAdd
+	}
+	if req.Mode != models.StockCountModeAdd && req.Mode != models.StockCountModeSet {
+		return nil, fmt.Errorf("mode hitungan harus add atau set")
+	}
+
+	for i := range req.Items {
+		line := &req.Items[i]
+		line.Barcode = strings.TrimSpace(line.Barcode)
+		if line.Barcode == "" && (line.ProductID == nil || *line.ProductID <= 0) {
+			return nil, fmt.Errorf("item #%d: barcode atau product_id harus diisi", i+1)
+		}
+		if (req.Mode == models.StockCountModeAdd && line.Quantity == 0) ||
+			(req.Mode == models.StockCountModeSet && line.Quantity < 0) {
+			return nil, fmt.Errorf("item #%d: %w", i+1, models.ErrInvalidCountQuantity)
+		}
+	}
+
+	return s.repo.RecordCounts(id, req, userID)
+}
+
+// Post memposting sesi: stok disesuaikan dengan hasil hitungan
+func (s *StockTakeService) Post(id int, req *models.StockTakePostRequest, userID int) (*models.StockTake, error) {
+	return s.repo.Post(id, req, userID)
+}
+
+// Cancel membatalkan sesi yang masih open
+func (s *StockTakeService) Cancel(id int) error {
+	return s.repo.Cancel(id)
+}
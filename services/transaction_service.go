@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"kasir-api/models"
 	"kasir-api/repositories"
+	"kasir-api/utils"
+	"math"
+	"strings"
 	"time"
 )
 
@@ -19,22 +22,123 @@ func NewTransactionService(repo *repositories.TransactionRepository) *Transactio
 
 // Checkout processes a checkout request
 func (s *TransactionService) Checkout(req *models.CheckoutRequest) (*models.Transaction, error) {
-	if len(req.Items) == 0 {
-		return nil, fmt.Errorf("items tidak boleh kosong")
+	if err := validateCheckoutItems(req.Items); err != nil {
+		return nil, err
 	}
 
-	for _, item := range req.Items {
-		if item.ProductID <= 0 {
-			return nil, fmt.Errorf("product_id tidak valid")
+	for i, p := range req.Payments {
+		if !models.IsValidPaymentMethod(p.Method) {
+			return nil, fmt.Errorf("pembayaran #%d: %w (%s)", i+1, models.ErrInvalidPaymentMethod, p.Method)
 		}
-		if item.Quantity <= 0 {
-			return nil, fmt.Errorf("quantity harus lebih dari 0")
+		if p.Amount <= 0 {
+			return nil, fmt.Errorf("pembayaran #%d: amount harus lebih dari 0", i+1)
+		}
+		if p.Method == models.PaymentMethodCredit && req.CustomerID == nil {
+			return nil, models.ErrCreditRequiresCustomer
 		}
+		if p.Method == models.PaymentMethodPoints && req.CustomerID == nil {
+			return nil, models.ErrPointsRequireCustomer
+		}
+	}
+
+	if err := applyOverrideApprovals(req); err != nil {
+		return nil, err
 	}
 
 	return s.repo.CreateTransaction(req)
 }
 
+// Preview menghitung harga keranjang dengan pricing engine checkout tanpa menyimpan transaksi
+func (s *TransactionService) Preview(req *models.CheckoutRequest) (*models.CheckoutPreview, error) {
+	if err := validateCheckoutItems(req.Items); err != nil {
+		return nil, err
+	}
+	if err := applyOverrideApprovals(req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.PreviewCheckout(req)
+}
+
+// applyOverrideApprovals memvalidasi approval_token per baris dan mengisi data persetujuannya
+// - override_price wajib disertai approval_token
+// - token harus valid, belum kedaluwarsa, dan diterbitkan untuk kasir yang checkout
+// - token hanya berlaku untuk produk, harga override & batas diskon manual yang disetujui
+// - 1 token hanya untuk 1 baris; pemakaian ulang antar checkout ditolak saat checkout (jti dicatat di DB)
+func applyOverrideApprovals(req *models.CheckoutRequest) error {
+	usedTokens := make(map[string]bool)
+	for i := range req.Items {
+		item := &req.Items[i]
+		item.OverrideApprovedBy = nil
+		item.OverrideReason = ""
+		item.OverrideTokenID = ""
+
+		if item.OverridePrice != nil && *item.OverridePrice < 0 {
+			return fmt.Errorf("produk ID %d: %w", item.ProductID, models.ErrInvalidOverridePrice)
+		}
+		if item.ApprovalToken == "" {
+			if item.OverridePrice != nil {
+				return fmt.Errorf("produk ID %d: %w", item.ProductID, models.ErrApprovalTokenRequired)
+			}
+			continue
+		}
+
+		claims, err := utils.ValidateOverrideJWT(item.ApprovalToken)
+		if err != nil || claims.CashierID != req.CreatedBy {
+			return fmt.Errorf("produk ID %d: %w", item.ProductID, models.ErrInvalidApprovalToken)
+		}
+		if !overrideClaimsCoverItem(claims, item) {
+			return fmt.Errorf("produk ID %d: %w", item.ProductID, models.ErrApprovalTokenMismatch)
+		}
+		if usedTokens[claims.ID] {
+			return fmt.Errorf("produk ID %d: %w", item.ProductID, models.ErrApprovalTokenUsed)
+		}
+		usedTokens[claims.ID] = true
+
+		approverID := claims.ApproverID
+		item.OverrideApprovedBy = &approverID
+		item.OverrideReason = claims.Reason
+		item.OverrideTokenID = claims.ID
+	}
+	return nil
+}
+
+// overrideClaimsCoverItem mengecek baris checkout sesuai dengan yang disetujui supervisor:
+// produk sama, override_price sama persis (jika ada), diskon manual tidak melebihi batas
+func overrideClaimsCoverItem(claims *utils.OverrideClaims, item *models.CheckoutItem) bool {
+	if claims.ProductID != item.ProductID {
+		return false
+	}
+	if item.OverridePrice != nil {
+		if claims.OverridePrice == nil || math.Abs(*claims.OverridePrice-*item.OverridePrice) > 0.005 {
+			return false
+		}
+	}
+	if item.DiscountAmount > 0 {
+		if claims.MaxDiscountAmount == nil || item.DiscountAmount > *claims.MaxDiscountAmount+0.005 {
+			return false
+		}
+	}
+	return true
+}
+
+// validateCheckoutItems memvalidasi item keranjang (dipakai checkout & held cart)
+func validateCheckoutItems(items []models.CheckoutItem) error {
+	if len(items) == 0 {
+		return fmt.Errorf("items tidak boleh kosong")
+	}
+
+	for _, item := range items {
+		if item.ProductID <= 0 {
+			return fmt.Errorf("product_id tidak valid")
+		}
+		if item.Quantity <= 0 {
+			return fmt.Errorf("quantity harus lebih dari 0")
+		}
+	}
+	return nil
+}
+
 // GetAll returns all transactions
 func (s *TransactionService) GetAll(userID *int) ([]models.Transaction, error) {
 	return s.repo.GetAll(userID)
@@ -49,3 +153,14 @@ func (s *TransactionService) GetByDateRange(startDate, endDate time.Time, userID
 func (s *TransactionService) GetByID(id int) (*models.TransactionWithItems, error) {
 	return s.repo.GetByID(id)
 }
+
+// Void membatalkan transaksi dan mengembalikan stok
+// reason wajib diisi untuk keperluan audit
+func (s *TransactionService) Void(id int, reason string, voidedBy int) (*models.Transaction, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, models.ErrVoidReasonEmpty
+	}
+
+	return s.repo.VoidTransaction(id, reason, voidedBy)
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"errors"
+	"kasir-api/models"
+	"kasir-api/utils"
+	"testing"
+	"time"
+)
+
+// Test approval token override: token hanya berlaku untuk kasir, produk, harga & batas diskon
+// yang disetujui supervisor, dan hanya untuk 1 baris.
+
+const (
+	testCashierID  = 7
+	testApproverID = 1
+	testProductID  = 42
+)
+
+func price(v float64) *float64 { return &v }
+
+// signOverride menerbitkan approval token atas nama testApproverID
+func signOverride(t *testing.T, cashierID, productID int, overridePrice, maxDiscount *float64, ttl time.Duration) string {
+	t.Helper()
+	token, _, err := utils.GenerateOverrideJWT(utils.OverrideClaims{
+		ApproverID:        testApproverID,
+		ApproverName:      "supervisor",
+		CashierID:         cashierID,
+		Reason:            "harga grosir",
+		ProductID:         productID,
+		OverridePrice:     overridePrice,
+		MaxDiscountAmount: maxDiscount,
+	}, ttl)
+	if err != nil {
+		t.Fatalf("gagal membuat approval token: %v", err)
+	}
+	return token
+}
+
+func TestApplyOverrideApprovals(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	priceToken := signOverride(t, testCashierID, testProductID, price(8000), nil, time.Minute)
+	discountToken := signOverride(t, testCashierID, testProductID, nil, price(1500), time.Minute)
+
+	tests := []struct {
+		name    string
+		items   []models.CheckoutItem
+		wantErr error
+	}{
+		{
+			name:  "harga override sesuai token",
+			items: []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, OverridePrice: price(8000), ApprovalToken: priceToken}},
+		},
+		{
+			name:  "diskon manual di bawah batas",
+			items: []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, DiscountAmount: 1000, ApprovalToken: discountToken}},
+		},
+		{
+			name:  "tanpa override tidak butuh token",
+			items: []models.CheckoutItem{{ProductID: testProductID, Quantity: 1}},
+		},
+		{
+			name:    "override_price tanpa token",
+			items:   []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, OverridePrice: price(8000)}},
+			wantErr: models.ErrApprovalTokenRequired,
+		},
+		{
+			name:    "override_price negatif",
+			items:   []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, OverridePrice: price(-1), ApprovalToken: priceToken}},
+			wantErr: models.ErrInvalidOverridePrice,
+		},
+		{
+			name:    "token rusak",
+			items:   []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, OverridePrice: price(8000), ApprovalToken: "bukan-token"}},
+			wantErr: models.ErrInvalidApprovalToken,
+		},
+		{
+			name: "token untuk kasir lain",
+			items: []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, OverridePrice: price(8000),
+				ApprovalToken: signOverride(t, testCashierID+1, testProductID, price(8000), nil, time.Minute)}},
+			wantErr: models.ErrInvalidApprovalToken,
+		},
+		{
+			name: "token kedaluwarsa",
+			items: []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, OverridePrice: price(8000),
+				ApprovalToken: signOverride(t, testCashierID, testProductID, price(8000), nil, -time.Minute)}},
+			wantErr: models.ErrInvalidApprovalToken,
+		},
+		{
+			name:    "token untuk produk lain",
+			items:   []models.CheckoutItem{{ProductID: testProductID + 1, Quantity: 1, OverridePrice: price(8000), ApprovalToken: priceToken}},
+			wantErr: models.ErrApprovalTokenMismatch,
+		},
+		{
+			name:    "harga berbeda dari yang disetujui",
+			items:   []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, OverridePrice: price(5000), ApprovalToken: priceToken}},
+			wantErr: models.ErrApprovalTokenMismatch,
+		},
+		{
+			name:    "harga override dengan token batas diskon",
+			items:   []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, OverridePrice: price(8000), ApprovalToken: discountToken}},
+			wantErr: models.ErrApprovalTokenMismatch,
+		},
+		{
+			name:    "diskon melebihi batas",
+			items:   []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, DiscountAmount: 2000, ApprovalToken: discountToken}},
+			wantErr: models.ErrApprovalTokenMismatch,
+		},
+		{
+			name:    "diskon manual dengan token harga saja",
+			items:   []models.CheckoutItem{{ProductID: testProductID, Quantity: 1, DiscountAmount: 500, ApprovalToken: priceToken}},
+			wantErr: models.ErrApprovalTokenMismatch,
+		},
+		{
+			name: "1 token dipakai di 2 baris",
+			items: []models.CheckoutItem{
+				{ProductID: testProductID, Quantity: 1, OverridePrice: price(8000), ApprovalToken: priceToken},
+				{ProductID: testProductID, Quantity: 2, OverridePrice: price(8000), ApprovalToken: priceToken},
+			},
+			wantErr: models.ErrApprovalTokenUsed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &models.CheckoutRequest{CreatedBy: testCashierID, Items: tt.items}
+			err := applyOverrideApprovals(req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v, want nil", err)
+			}
+			for _, item := range req.Items {
+				if item.ApprovalToken == "" {
+					continue
+				}
+				if item.OverrideApprovedBy == nil || *item.OverrideApprovedBy != testApproverID {
+					t.Errorf("override_approved_by = %v, want %d", item.OverrideApprovedBy, testApproverID)
+				}
+				if item.OverrideTokenID == "" {
+					t.Error("jti token tidak diisi, token tidak bisa ditandai terpakai")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"crypto/rand"
+	"fmt"
+	"kasir-api/models"
+	"kasir-api/repositories"
+	"strings"
+)
+
+// voucherCodeAlphabet = karakter kode otomatis (tanpa 0/O dan 1/I agar tidak tertukar saat dicetak)
+const voucherCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// voucherCodeLength = panjang bagian acak kode otomatis
+const voucherCodeLength = 8
+
+// maxVoucherBatch = jumlah maksimal kode per POST /api/vouchers/generate
+const maxVoucherBatch = 1000
+
+// VoucherService handles business logic for vouchers
+type VoucherService struct {
+	repo *repositories.VoucherRepository
+}
+
+// NewVoucherService creates a new VoucherService
+func NewVoucherService(repo *repositories.VoucherRepository) *VoucherService {
+	return &VoucherService{repo: repo}
+}
+
+// GetAll mengambil voucher dengan pencarian kode / nama dan pagination
+func (s *VoucherService) GetAll(search string, pagination *models.PaginationParams) ([]models.Voucher, int, error) {
+	return s.repo.GetAll(strings.TrimSpace(search), pagination)
+}
+
+// GetByID mengambil voucher by ID
+func (s *VoucherService) GetByID(id int) (*models.Voucher, error) {
+	return s.repo.GetByID(id)
+}
+
+// Create membuat 1 voucher (kode kosong = dibuat otomatis)
+func (s *VoucherService) Create(req models.VoucherRequest, createdBy int) (*models.Voucher, error) {
+	voucher, err := buildVoucher(req, true)
+	if err != nil {
+		return nil, err
+	}
+	if voucher.Code == "" {
+		if voucher.Code, err = generateVoucherCode(""); err != nil {
+			return nil, err
+		}
+	}
+	if createdBy > 0 {
+		voucher.CreatedBy = &createdBy
+	}
+
+	if err := s.repo.Create([]*models.Voucher{voucher}); err != nil {
+		return nil, err
+	}
+	return voucher, nil
+}
+
+// Generate membuat banyak voucher sekaligus dengan aturan yang sama (kode acak + prefix)
+func (s *VoucherService) Generate(req models.VoucherGenerateRequest, createdBy int) ([]*models.Voucher, error) {
+	if req.Count <= 0 || req.Count > maxVoucherBatch {
+		return nil, models.ErrInvalidVoucherCount
+	}
+	prefix := models.NormalizeVoucherCode(req.Prefix)
+	if prefix != "" && !models.IsValidVoucherCode(prefix+"-"+strings.Repeat("A", voucherCodeLength)) {
+		return nil, models.ErrInvalidVoucherCode
+	}
+	req.Code = ""
+
+	vouchers := make([]*models.Voucher, 0, req.Count)
+	seen := make(map[string]bool, req.Count)
+	for len(vouchers) < req.Count {
+		voucher, err := buildVoucher(req.VoucherRequest, true)
+		if err != nil {
+			return nil, err
+		}
+		if voucher.Code, err = generateVoucherCode(prefix); err != nil {
+			return nil, err
+		}
+		if seen[voucher.Code] {
+			continue
+		}
+		seen[voucher.Code] = true
+		if createdBy > 0 {
+			voucher.CreatedBy = &createdBy
+		}
+		vouchers = append(vouchers, voucher)
+	}
+
+	if err := s.repo.Create(vouchers); err != nil {
+		return nil, err
+	}
+	return vouchers, nil
+}
+
+// Update mengubah voucher
+// is_active tidak dikirim → status aktif tidak berubah
+func (s *VoucherService) Update(id int, req models.VoucherRequest) (*models.Voucher, error) {
+	existing, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Code) == "" {
+		req.Code = existing.Code
+	}
+
+	voucher, err := buildVoucher(req, existing.IsActive)
+	if err != nil {
+		return nil, err
+	}
+	voucher.ID = id
+	voucher.RedemptionCount = existing.RedemptionCount
+	if err := s.repo.Update(voucher); err != nil {
+		return nil, err
+	}
+	return voucher, nil
+}
+
+// Deactivate menonaktifkan voucher (riwayat pemakaian tetap disimpan)
+func (s *VoucherService) Deactivate(id int) error {
+	return s.repo.Deactivate(id)
+}
+
+// buildVoucher memvalidasi request & mengubahnya menjadi models.Voucher
+func buildVoucher(req models.VoucherRequest, defaultActive bool) (*models.Voucher, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	code := models.NormalizeVoucherCode(req.Code)
+	if code != "" && !models.IsValidVoucherCode(code) {
+		return nil, models.ErrInvalidVoucherCode
+	}
+
+	isActive := defaultActive
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	return &models.Voucher{
+		Code:             code,
+		Name:             strings.TrimSpace(req.Name),
+		Type:             req.Type,
+		Value:            req.Value,
+		MinOrderAmount:   req.MinOrderAmount,
+		MaxRedemptions:   req.MaxRedemptions,
+		PerCustomerLimit: req.PerCustomerLimit,
+		StartDate:        req.StartDate,
+		EndDate:          req.EndDate,
+		IsActive:         isActive,
+	}, nil
+}
+
+// generateVoucherCode membuat kode acak, misal "LEBARAN-7KQ2M9XA" (prefix kosong → "7KQ2M9XA")
+func generateVoucherCode(prefix string) (string, error) {
+	buf := make([]byte, voucherCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("gagal membuat kode voucher: %w", err)
+	}
+	code := make([]byte, voucherCodeLength)
+	for i, b := range buf {
+		code[i] = voucherCodeAlphabet[int(b)%len(voucherCodeAlphabet)]
+	}
+	if prefix == "" {
+		return string(code), nil
+	}
+	return prefix + "-" + string(code), nil
+}
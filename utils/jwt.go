@@ -1,6 +1,8 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"os"
 	"time"
@@ -76,7 +78,81 @@ func ValidateJWT(tokenString string) (*Claims, error) {
 	}
 
 	// Ambil claims
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	// Token persetujuan override tidak boleh dipakai sebagai token login
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.Subject != overrideTokenSubject {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid token")
+}
+
+// overrideTokenSubject menandai JWT sebagai token persetujuan override (bukan token login)
+const overrideTokenSubject = "override"
+
+// OverrideClaims adalah claims token persetujuan override harga / diskon
+// Token terikat ke kasir yang meminta, 1 produk, dan harga / batas diskon yang disetujui.
+// ID (jti) unik per token; checkout mencatatnya agar token hanya bisa dipakai 1 kali.
+type OverrideClaims struct {
+	ApproverID        int      `json:"approver_id"`
+	ApproverName      string   `json:"approver_name"`
+	CashierID         int      `json:"cashier_id"`
+	Reason            string   `json:"reason"`
+	ProductID         int      `json:"product_id"`
+	OverridePrice     *float64 `json:"override_price,omitempty"`      // Harga satuan yang disetujui (nil = tidak boleh ganti harga)
+	MaxDiscountAmount *float64 `json:"max_discount_amount,omitempty"` // Batas diskon manual baris (nil = tidak boleh diskon manual)
+	jwt.RegisteredClaims
+}
+
+// GenerateOverrideJWT membuat token persetujuan override yang berlaku selama ttl
+// claims diisi caller (approver, kasir, produk, harga / batas diskon); jti, subject & masa berlaku diisi di sini.
+func GenerateOverrideJWT(claims OverrideClaims, ttl time.Duration) (string, time.Time, error) {
+	secretKey := os.Getenv("JWT_SECRET")
+	if secretKey == "" {
+		return "", time.Time{}, errors.New("JWT_SECRET tidak ditemukan di environment variable")
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        hex.EncodeToString(jti),
+		Subject:   overrideTokenSubject,
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(secretKey))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// ValidateOverrideJWT memvalidasi token persetujuan override dan mengembalikan claims
+func ValidateOverrideJWT(tokenString string) (*OverrideClaims, error) {
+	secretKey := os.Getenv("JWT_SECRET")
+	if secretKey == "" {
+		return nil, errors.New("JWT_SECRET tidak ditemukan di environment variable")
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &OverrideClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return []byte(secretKey), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*OverrideClaims); ok && token.Valid && claims.Subject == overrideTokenSubject && claims.ID != "" {
 		return claims, nil
 	}
 